@@ -0,0 +1,63 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/handler"
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/pkg/errors"
+)
+
+func readCredentials(path string) (map[string]entity.Credential, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]entity.Credential{}
+	return out, errors.Wrapf(json.Unmarshal(data, &out), "parsing %s", path)
+}
+
+// getAuthHandler creates the handler for the configured methods of authentication, or returns
+// nil if none are configured
+func getAuthHandler(conf config.Config) (handler.AuthHandler, error) {
+	if !conf.Auth.Enabled() {
+		return nil, nil
+	}
+	auths := []service.Authenticator{}
+	if conf.Auth.TokensFile != "" {
+		tokens, err := readCredentials(conf.Auth.TokensFile)
+		if err != nil {
+			return nil, err
+		}
+		auths = append(auths, service.NewBearerAuthenticator(tokens))
+	}
+	if conf.Auth.HMACKeysFile != "" {
+		keys, err := readCredentials(conf.Auth.HMACKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		auths = append(auths, service.NewHMACAuthenticator(keys, conf.Auth.HMACMaxSkew))
+	}
+	if conf.Auth.JWKSFile != "" {
+		jwks, err := ioutil.ReadFile(conf.Auth.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		auth, err := service.NewJWTAuthenticator(jwks, conf.Auth)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", conf.Auth.JWKSFile)
+		}
+		auths = append(auths, auth)
+	}
+	return handler.NewAuthHandler(auths, conf.GetLogger()), nil
+}
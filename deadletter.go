@@ -0,0 +1,73 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/config"
+)
+
+const deadLetterUsage = `usage: genesis deadletter <command>
+
+commands:
+	list           list all of the dead-lettered instructions
+	show <id>      show the full details of a dead letter
+	replay <id>    place the original instructions back onto the command queue`
+
+func printJSON(i interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(i)
+}
+
+func deadLetterCLI(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(deadLetterUsage)
+	}
+	conf, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
+	serv, err := getDeadLetterService(conf)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		letters, err := serv.List()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTEST\tORG\tATTEMPTS\tTIMESTAMP")
+		for _, letter := range letters {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", letter.ID, letter.TestID, letter.OrgID,
+				len(letter.Attempts), letter.Timestamp.Format(time.RFC3339))
+		}
+		return w.Flush()
+	case args[0] == "show" && len(args) == 2:
+		letter, err := serv.Get(args[1])
+		if err != nil {
+			return err
+		}
+		return printJSON(letter)
+	case args[0] == "replay" && len(args) == 2:
+		letter, err := serv.Replay(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("replayed %s\n", letter.ID)
+		return nil
+	}
+	return fmt.Errorf(deadLetterUsage)
+}
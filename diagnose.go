@@ -0,0 +1,57 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/service"
+)
+
+const diagnoseUsage = `usage: genesis diagnose [--json] <host>
+
+diagnoses the connection to the docker daemon of host, exiting with an error if its api
+does not respond`
+
+func diagnoseCLI(args []string) error {
+	asJSON := len(args) > 0 && args[0] == "--json"
+	if asJSON {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return fmt.Errorf(diagnoseUsage)
+	}
+
+	conf, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
+	diag := service.NewHostDiagnoser(getDockerService(conf), conf.Docker.DiagnosisTimeout,
+		conf.GetLogger()).Diagnose(context.Background(), args[0])
+
+	if asJSON {
+		err = printJSON(diag)
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "STEP\tOK\tLATENCY\tERROR")
+		for _, step := range diag.Steps {
+			fmt.Fprintf(w, "%s\t%t\t%.1fms\t%s\n", step.Name, step.OK, step.LatencyMillis, step.Error)
+		}
+		err = w.Flush()
+	}
+	if err != nil {
+		return err
+	}
+	if !diag.Healthy {
+		return fmt.Errorf("the docker daemon of %s is unhealthy", diag.Host)
+	}
+	return nil
+}
@@ -7,8 +7,11 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 
+	"github.com/whiteblock/genesis/pkg/bus"
 	"github.com/whiteblock/genesis/pkg/config"
 	"github.com/whiteblock/genesis/pkg/controller"
 	"github.com/whiteblock/genesis/pkg/file"
@@ -16,123 +19,206 @@ import (
 	handAux "github.com/whiteblock/genesis/pkg/handler/auxillary"
 	"github.com/whiteblock/genesis/pkg/repository"
 	"github.com/whiteblock/genesis/pkg/service"
+	"github.com/whiteblock/genesis/pkg/tracing"
 	"github.com/whiteblock/genesis/pkg/usecase"
 
 	"github.com/gorilla/mux"
 	queue "github.com/whiteblock/amqp"
 )
 
-func getRestServer() (controller.RestController, error) {
-	conf, err := config.NewConfig()
+func getDeadLetterService(conf config.Config) (service.DeadLetterService, error) {
+	if conf.Bus.Backend != config.AMQPBus {
+		return nil, fmt.Errorf("browsing dead letters requires the %s message bus", config.AMQPBus)
+	}
+	cmdConf, err := conf.CommandAMQP()
 	if err != nil {
 		return nil, err
 	}
-	config.SanityCheck(conf)
 
-	return controller.NewRestController(
-		conf.GetRestConfig(),
-		handler.NewRestHandler(
-			handAux.NewExecutor(
-				conf.Execution,
-				usecase.NewDockerUseCase(
-					service.NewDockerService(
-						repository.NewDockerRepository(conf.GetLogger()),
-						conf.Docker,
-						file.NewRemoteSources(
-							conf,
-							conf.GetLogger()),
-						conf.GetLogger()),
-					conf.GetLogger()),
-				conf.GetLogger()),
+	deadLetterConf, err := conf.DeadLetterAMQP()
+	if err != nil {
+		return nil, err
+	}
+
+	cmdConn, err := queue.OpenAMQPConnection(cmdConf.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetterConn, err := queue.OpenAMQPConnection(deadLetterConf.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewDeadLetterService(
+		repository.NewDeadLetterRepository(
+			deadLetterConf.QueueName,
+			queue.NewAMQPRepository(deadLetterConn),
 			conf.GetLogger()),
-		mux.NewRouter(),
+		queue.NewAMQPService(cmdConf, queue.NewAMQPRepository(cmdConn), conf.GetLogger()),
 		conf.GetLogger()), nil
 }
 
-func assertUniqueQueues(conf config.Config, confs ...queue.AMQPConfig) {
-	queues := map[string]bool{}
-	for i := range confs {
-		queues[confs[i].QueueName] = false
-		if len(queues)-1 != i {
-			for j := range confs {
-				conf.GetLogger().Errorf("%d = %s", j, confs[j].QueueName)
+func getReadinessService(
+	conf config.Config,
+	dockerService service.DockerService) (service.ReadinessService, error) {
+
+	checks := []service.ReadinessCheck{}
+	if !conf.LocalMode {
+		if conf.Bus.Backend == config.AMQPBus {
+			queues := []func() (queue.AMQPConfig, error){
+				conf.CommandAMQP, conf.ErrorsAMQP, conf.CompletionAMQP, conf.StatusAMQP}
+			for _, getConf := range queues {
+				queueConf, err := getConf()
+				if err != nil {
+					return nil, err
+				}
+				checks = append(checks, service.NewAMQPCheck(queueConf))
 			}
-			panic("queue names are not unique")
 		}
+		checks = append(checks, service.NewHTTPCheck("fileAPI", conf.FileHandler.APIEndpoint, false))
 	}
+	for _, host := range conf.Readiness.DockerHosts {
+		checks = append(checks, service.NewDockerCheck(host, dockerService))
+	}
+	return service.NewReadinessService(checks, conf.Readiness.Timeout,
+		conf.Readiness.CacheTTL, conf.GetLogger()), nil
 }
 
-func getCommandController() (controller.CommandController, error) {
+func getRestServer(
+	sched handAux.Scheduler,
+	ledger service.LedgerService,
+	jobs service.JobService,
+	dockerService service.DockerService,
+	breaker service.HostBreaker,
+	broker bus.MemoryBroker) (controller.RestController, error) {
 	conf, err := config.NewConfig()
 	if err != nil {
 		return nil, err
 	}
 	config.SanityCheck(conf)
-	if conf.Execution.DebugMode {
-		conf.GetLogger().Warn("Debug mode is enabled!")
-	}
 
-	complConf, err := conf.CompletionAMQP()
-	if err != nil {
-		return nil, err
+	var deadLetters handler.DeadLetterHandler
+	if !conf.LocalMode && conf.Bus.Backend == config.AMQPBus {
+		serv, err := getDeadLetterService(conf)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = handler.NewDeadLetterHandler(serv, conf.GetLogger())
 	}
 
-	cmdConf, err := conf.CommandAMQP()
-	if err != nil {
-		return nil, err
+	var schedHandler handler.SchedulerHandler
+	if sched != nil {
+		schedHandler = handler.NewSchedulerHandler(sched, conf.GetLogger())
 	}
 
-	errConf, err := conf.ErrorsAMQP()
+	docker := usecase.NewDockerUseCase(dockerService, conf.GetLogger())
+	diagnoser := service.NewHostDiagnoser(dockerService, conf.Docker.DiagnosisTimeout,
+		conf.GetLogger())
+
+	restHandler := handler.NewRestHandler(
+		handAux.NewExecutor(
+			conf.Execution,
+			docker,
+			ledger,
+			breaker,
+			diagnoser,
+			conf.GetLogger()),
+		ledger,
+		jobs,
+		conf.GetLogger())
+
+	err = restHandler.Recover(conf.State.ResumeJobs)
 	if err != nil {
 		return nil, err
 	}
 
-	statusConf, err := conf.StatusAMQP()
+	auth, err := getAuthHandler(conf)
 	if err != nil {
 		return nil, err
 	}
 
-	assertUniqueQueues(conf, complConf, cmdConf, errConf, statusConf)
-
-	cmdConn, err := queue.OpenAMQPConnection(cmdConf.Endpoint)
+	readiness, err := getReadinessService(conf, dockerService)
 	if err != nil {
 		return nil, err
 	}
 
-	complConn, err := queue.OpenAMQPConnection(complConf.Endpoint)
-	if err != nil {
-		return nil, err
+	var busHandler handler.BusHandler
+	if broker != nil {
+		busHandler = handler.NewBusHandler(broker, conf.CommandQueueName,
+			[]string{conf.ErrorQueueName, conf.CompletionQueueName, conf.StatusQueueName,
+				conf.DeadLetterQueueName},
+			conf.GetLogger())
 	}
 
-	errConn, err := queue.OpenAMQPConnection(errConf.Endpoint)
+	return controller.NewRestController(
+		conf.GetRestConfig(),
+		restHandler,
+		handler.NewValidationHandler(
+			usecase.NewValidationUseCase(docker, conf.GetLogger()),
+			conf.GetLogger()),
+		handler.NewPlanHandler(
+			usecase.NewPlanUseCase(conf.Docker, conf.GetLogger()),
+			conf.GetLogger()),
+		deadLetters,
+		schedHandler,
+		handler.NewHostHandler(breaker, conf.GetLogger()),
+		handler.NewReadinessHandler(readiness, conf.GetLogger()),
+		handler.NewDiagnosticsHandler(diagnoser, conf.GetLogger()),
+		busHandler,
+		auth,
+		mux.NewRouter(),
+		conf.GetLogger()), nil
+}
+
+func getDockerService(conf config.Config) service.DockerService {
+	return service.NewTracedDockerService(service.NewDockerService(
+		repository.NewDockerRepository(conf.GetLogger()),
+		conf.Docker,
+		file.NewRemoteSources(
+			conf,
+			conf.GetLogger()),
+		conf.GetLogger()))
+}
+
+func getCommandController(
+	sched handAux.Scheduler,
+	ledger service.LedgerService,
+	dockerService service.DockerService,
+	breaker service.HostBreaker,
+	broker bus.MemoryBroker) (controller.CommandController, error) {
+	conf, err := config.NewConfig()
 	if err != nil {
 		return nil, err
 	}
+	config.SanityCheck(conf)
+	if conf.Execution.DebugMode {
+		conf.GetLogger().Warn("Debug mode is enabled!")
+	}
 
-	statusConn, err := queue.OpenAMQPConnection(statusConf.Endpoint)
+	queues, err := getMessageQueues(conf, broker)
 	if err != nil {
 		return nil, err
 	}
 
 	return controller.NewCommandController(
-		conf.QueueMaxConcurrency,
-		queue.NewAMQPService(cmdConf, queue.NewAMQPRepository(cmdConn), conf.GetLogger()),
-		queue.NewAMQPService(errConf, queue.NewAMQPRepository(errConn), conf.GetLogger()),
-		queue.NewAMQPService(complConf, queue.NewAMQPRepository(complConn), conf.GetLogger()),
-		queue.NewAMQPService(statusConf, queue.NewAMQPRepository(statusConn), conf.GetLogger()),
+		sched,
+		queues.cmds,
+		queues.errors,
+		queues.completion,
+		queues.status,
+		queues.deadLetters,
+		queues.retry,
 		handler.NewDeliveryHandler(
 			handAux.NewExecutor(
 				conf.Execution,
-				usecase.NewDockerUseCase(
-					service.NewDockerService(
-						repository.NewDockerRepository(conf.GetLogger()),
-						conf.Docker,
-						file.NewRemoteSources(
-							conf,
-							conf.GetLogger()),
-						conf.GetLogger()),
+				usecase.NewDockerUseCase(dockerService, conf.GetLogger()),
+				ledger,
+				breaker,
+				service.NewHostDiagnoser(dockerService, conf.Docker.DiagnosisTimeout,
 					conf.GetLogger()),
 				conf.GetLogger()),
+			ledger,
 			conf,
 			conf.MaxMessageRetries,
 			conf.GetLogger()),
@@ -151,18 +237,84 @@ func main() {
 		os.Exit(0)
 	}
 
-	restServer, err := getRestServer()
+	if len(os.Args) > 1 && os.Args[1] == "deadletter" { //Inspect and replay dead-lettered instructions
+		err := deadLetterCLI(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "plan" { //Preview the docker calls of some instructions
+		err := planCLI(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "diagnose" { //Diagnose the docker daemon of a host
+		err := diagnoseCLI(os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	conf, err := config.NewConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	shutdownTracing, err := tracing.Setup(conf.Tracing)
 	if err != nil {
 		panic(err)
 	}
+	defer shutdownTracing(context.Background())
 
-	conf, err := config.NewConfig()
+	var sched handAux.Scheduler
+	if !conf.LocalMode {
+		sched, err = handAux.NewScheduler(conf.Scheduler, conf.GetLogger())
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	db, err := openStateDB(conf.State)
+	if err != nil {
+		panic(err)
+	}
+
+	ledger, err := getLedgerService(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	jobs, err := getJobService(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	//the docker service is shared, so that the rest api and the queues share its pool of clients
+	//along with the health of each host
+	dockerService := getDockerService(conf)
+	breaker := service.NewHostBreaker(dockerService, conf.Execution, conf.GetLogger())
+
+	var broker bus.MemoryBroker
+	if !conf.LocalMode {
+		broker = getMemoryBroker(conf)
+	}
+
+	restServer, err := getRestServer(sched, ledger, jobs, dockerService, breaker, broker)
 	if err != nil {
 		panic(err)
 	}
 
 	if !conf.LocalMode {
-		cmdCntl, err := getCommandController()
+		cmdCntl, err := getCommandController(sched, ledger, dockerService, breaker, broker)
 		if err != nil {
 			panic(err)
 		}
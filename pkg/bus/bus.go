@@ -0,0 +1,51 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package bus
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// Queue is a single named queue on the message bus. The AMQP message types are used as
+// the common message format, so queue.AMQPService from github.com/whiteblock/amqp
+// implements this interface as is, and the other backends translate to and from them.
+type Queue interface {
+	// Consume immediately starts delivering queued messages
+	Consume() (<-chan amqp.Delivery, error)
+	// Send places a message into the queue
+	Send(pub amqp.Publishing) error
+	// Requeue rejects the oldMsg and queues the newMsg
+	Requeue(oldMsg amqp.Delivery, newMsg amqp.Publishing) error
+	// CreateQueue attempts to create the queue, if the backend requires it
+	CreateQueue() error
+}
+
+// TryCreateQueues attempts to create the given queues, logging any failures
+func TryCreateQueues(log logrus.Ext1FieldLogger, queues ...Queue) {
+	errChan := make(chan error)
+	for i := range queues {
+		go func(i int) {
+			errChan <- queues[i].CreateQueue()
+		}(i)
+	}
+
+	for range queues {
+		err := <-errChan
+		if err != nil {
+			log.WithFields(logrus.Fields{"err": err}).Debug("failed to create a queue")
+		}
+	}
+}
+
+func copyHeaders(headers amqp.Table) amqp.Table {
+	out := amqp.Table{}
+	for key, val := range headers {
+		out[key] = val
+	}
+	return out
+}
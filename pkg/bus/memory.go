@@ -0,0 +1,206 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package bus
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// ErrQueueFull is returned when sending to an in memory queue which already holds its limit
+var ErrQueueFull = errors.New("the queue is full")
+
+// MemoryBroker holds a set of in process queues, for tests and single binary deployments.
+// Nothing is persisted, so any queued messages are lost when the process exits.
+type MemoryBroker interface {
+	// Queue gets the queue with the given name, creating it if it does not yet exist
+	Queue(name string) Queue
+	// Get removes the next message from the queue with the given name, acknowledging it.
+	// It returns false if the queue is empty.
+	Get(name string) (amqp.Delivery, bool)
+}
+
+type memoryBroker struct {
+	queues map[string]*memoryQueue
+	limit  int
+	log    logrus.Ext1FieldLogger
+	mux    sync.Mutex
+}
+
+// NewMemoryBroker creates a new MemoryBroker. Each of its queues holds at most limit
+// messages, after which sending to it fails with ErrQueueFull. A limit of zero means unlimited.
+func NewMemoryBroker(limit int, log logrus.Ext1FieldLogger) MemoryBroker {
+	return &memoryBroker{queues: map[string]*memoryQueue{}, limit: limit, log: log}
+}
+
+// Queue gets the queue with the given name, creating it if it does not yet exist
+func (mb *memoryBroker) Queue(name string) Queue {
+	mb.mux.Lock()
+	defer mb.mux.Unlock()
+	return mb.queue(name)
+}
+
+// Get removes the next message from the queue with the given name, acknowledging it.
+// It returns false if the queue is empty.
+func (mb *memoryBroker) Get(name string) (amqp.Delivery, bool) {
+	mb.mux.Lock()
+	q := mb.queue(name)
+	mb.mux.Unlock()
+	return q.get()
+}
+
+func (mb *memoryBroker) queue(name string) *memoryQueue {
+	if q, ok := mb.queues[name]; ok {
+		return q
+	}
+	q := &memoryQueue{
+		name:    name,
+		limit:   mb.limit,
+		unacked: map[uint64]amqp.Delivery{},
+		log:     mb.log,
+	}
+	q.cond = sync.NewCond(&q.mux)
+	mb.queues[name] = q
+	return q
+}
+
+type memoryQueue struct {
+	name    string
+	limit   int
+	pending []amqp.Delivery
+	unacked map[uint64]amqp.Delivery
+	tag     uint64
+	log     logrus.Ext1FieldLogger
+	mux     sync.Mutex
+	cond    *sync.Cond
+}
+
+// Consume immediately starts delivering queued messages
+func (mq *memoryQueue) Consume() (<-chan amqp.Delivery, error) {
+	out := make(chan amqp.Delivery)
+	go func() {
+		for {
+			out <- mq.next()
+		}
+	}()
+	return out, nil
+}
+
+func (mq *memoryQueue) next() amqp.Delivery {
+	mq.mux.Lock()
+	defer mq.mux.Unlock()
+	for len(mq.pending) == 0 {
+		mq.cond.Wait()
+	}
+	msg := mq.pending[0]
+	mq.pending = mq.pending[1:]
+	mq.unacked[msg.DeliveryTag] = msg
+	return msg
+}
+
+func (mq *memoryQueue) get() (amqp.Delivery, bool) {
+	mq.mux.Lock()
+	defer mq.mux.Unlock()
+	if len(mq.pending) == 0 {
+		return amqp.Delivery{}, false
+	}
+	msg := mq.pending[0]
+	mq.pending = mq.pending[1:]
+	return msg, true
+}
+
+// Send places a message into the queue, failing with ErrQueueFull if it is already at its limit
+func (mq *memoryQueue) Send(pub amqp.Publishing) error {
+	mq.mux.Lock()
+	defer mq.mux.Unlock()
+	if mq.limit > 0 && len(mq.pending) >= mq.limit {
+		mq.log.WithField("queue", mq.name).Warn("refusing to send to a full queue")
+		return ErrQueueFull
+	}
+	mq.tag++
+	timestamp := pub.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	mq.pending = append(mq.pending, amqp.Delivery{
+		Acknowledger:  mq,
+		Headers:       copyHeaders(pub.Headers),
+		ContentType:   pub.ContentType,
+		DeliveryMode:  pub.DeliveryMode,
+		Priority:      pub.Priority,
+		CorrelationId: pub.CorrelationId,
+		Expiration:    pub.Expiration,
+		MessageId:     pub.MessageId,
+		Timestamp:     timestamp,
+		Type:          pub.Type,
+		DeliveryTag:   mq.tag,
+		RoutingKey:    mq.name,
+		Body:          pub.Body,
+	})
+	mq.cond.Signal()
+	return nil
+}
+
+// Requeue rejects the oldMsg and queues the newMsg
+func (mq *memoryQueue) Requeue(oldMsg amqp.Delivery, newMsg amqp.Publishing) error {
+	err := mq.Send(newMsg)
+	if err != nil {
+		return err
+	}
+	return oldMsg.Reject(false)
+}
+
+// CreateQueue does nothing, as in memory queues always exist
+func (mq *memoryQueue) CreateQueue() error {
+	return nil
+}
+
+// Ack acknowledges the delivery with the given tag
+func (mq *memoryQueue) Ack(tag uint64, multiple bool) error {
+	return mq.settle(tag, multiple, false)
+}
+
+// Nack negatively acknowledges the delivery with the given tag
+func (mq *memoryQueue) Nack(tag uint64, multiple bool, requeue bool) error {
+	return mq.settle(tag, multiple, requeue)
+}
+
+// Reject rejects the delivery with the given tag
+func (mq *memoryQueue) Reject(tag uint64, requeue bool) error {
+	return mq.settle(tag, false, requeue)
+}
+
+func (mq *memoryQueue) settle(tag uint64, multiple bool, requeue bool) error {
+	mq.mux.Lock()
+	defer mq.mux.Unlock()
+	tags := []uint64{tag}
+	if multiple {
+		tags = []uint64{}
+		for unacked := range mq.unacked {
+			if unacked <= tag {
+				tags = append(tags, unacked)
+			}
+		}
+	}
+	for _, tag := range tags {
+		msg, ok := mq.unacked[tag]
+		if !ok {
+			continue
+		}
+		delete(mq.unacked, tag)
+		if requeue {
+			msg.Redelivered = true
+			mq.pending = append([]amqp.Delivery{msg}, mq.pending...)
+			mq.cond.Signal()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package bus
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, msgs <-chan amqp.Delivery) amqp.Delivery {
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a message")
+	}
+	return amqp.Delivery{}
+}
+
+func TestMemoryBroker_Queue(t *testing.T) {
+	broker := NewMemoryBroker(0, logrus.New())
+	assert.True(t, broker.Queue("test") == broker.Queue("test"))
+	assert.False(t, broker.Queue("test") == broker.Queue("other"))
+}
+
+func TestMemoryQueue_SendConsume(t *testing.T) {
+	q := NewMemoryBroker(0, logrus.New()).Queue("test")
+	require.NoError(t, q.CreateQueue())
+
+	headers := amqp.Table{"retryCount": int64(1)}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, q.Send(amqp.Publishing{
+			Headers: headers,
+			Body:    []byte(strconv.Itoa(i)),
+		}))
+	}
+	headers["retryCount"] = int64(5)
+
+	msgs, err := q.Consume()
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		msg := receive(t, msgs)
+		assert.Equal(t, []byte(strconv.Itoa(i)), msg.Body)
+		assert.Equal(t, int64(1), msg.Headers["retryCount"])
+		assert.Equal(t, "test", msg.RoutingKey)
+		assert.NoError(t, msg.Ack(false))
+	}
+}
+
+func TestMemoryQueue_Limit(t *testing.T) {
+	q := NewMemoryBroker(2, logrus.New()).Queue("test")
+	for i := 0; i < 2; i++ {
+		require.NoError(t, q.Send(amqp.Publishing{Body: []byte(strconv.Itoa(i))}))
+	}
+	assert.Equal(t, ErrQueueFull, q.Send(amqp.Publishing{Body: []byte("2")}))
+
+	msgs, err := q.Consume()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("0"), receive(t, msgs).Body)
+	assert.Equal(t, []byte("1"), receive(t, msgs).Body)
+}
+
+func TestMemoryBroker_Get(t *testing.T) {
+	broker := NewMemoryBroker(0, logrus.New())
+	_, ok := broker.Get("test")
+	assert.False(t, ok)
+
+	require.NoError(t, broker.Queue("test").Send(amqp.Publishing{Body: []byte("first")}))
+	require.NoError(t, broker.Queue("test").Send(amqp.Publishing{Body: []byte("second")}))
+
+	msg, ok := broker.Get("test")
+	require.True(t, ok)
+	assert.Equal(t, []byte("first"), msg.Body)
+	msg, ok = broker.Get("test")
+	require.True(t, ok)
+	assert.Equal(t, []byte("second"), msg.Body)
+	_, ok = broker.Get("test")
+	assert.False(t, ok)
+	assert.Empty(t, broker.Queue("test").(*memoryQueue).unacked)
+}
+
+func TestMemoryQueue_Settle(t *testing.T) {
+	var tests = []struct {
+		settle     func(msg amqp.Delivery) error
+		redelivers bool
+	}{
+		{settle: func(msg amqp.Delivery) error { return msg.Ack(false) }, redelivers: false},
+		{settle: func(msg amqp.Delivery) error { return msg.Nack(false, true) }, redelivers: true},
+		{settle: func(msg amqp.Delivery) error { return msg.Nack(false, false) }, redelivers: false},
+		{settle: func(msg amqp.Delivery) error { return msg.Reject(true) }, redelivers: true},
+		{settle: func(msg amqp.Delivery) error { return msg.Reject(false) }, redelivers: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			q := NewMemoryBroker(0, logrus.New()).Queue("test")
+			require.NoError(t, q.Send(amqp.Publishing{Body: []byte("first")}))
+
+			msgs, err := q.Consume()
+			require.NoError(t, err)
+			msg := receive(t, msgs)
+			require.NoError(t, tt.settle(msg))
+
+			require.NoError(t, q.Send(amqp.Publishing{Body: []byte("second")}))
+			msg = receive(t, msgs)
+			if tt.redelivers {
+				assert.Equal(t, []byte("first"), msg.Body)
+				assert.True(t, msg.Redelivered)
+			} else {
+				assert.Equal(t, []byte("second"), msg.Body)
+			}
+		})
+	}
+}
+
+func TestMemoryQueue_Requeue(t *testing.T) {
+	q := NewMemoryBroker(0, logrus.New()).Queue("test")
+	require.NoError(t, q.Send(amqp.Publishing{Body: []byte("old")}))
+
+	msgs, err := q.Consume()
+	require.NoError(t, err)
+	old := receive(t, msgs)
+
+	require.NoError(t, q.Requeue(old, amqp.Publishing{Body: []byte("new")}))
+	assert.Equal(t, []byte("new"), receive(t, msgs).Body)
+	assert.Len(t, q.(*memoryQueue).unacked, 1)
+}
@@ -0,0 +1,263 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package bus
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+const (
+	// HeadersHeader is the NATS header which carries the AMQP headers of a message, as JSON
+	HeadersHeader = "Genesis-Headers"
+	// ContentTypeHeader is the NATS header which carries the content type of a message
+	ContentTypeHeader = "Content-Type"
+	// NotBeforeHeader is the AMQP header holding the unix time in milliseconds before which a
+	// message should not be delivered. NATS queues hold such messages back with a delayed nak,
+	// so that they stay in the stream while they wait.
+	NotBeforeHeader = "notBefore"
+)
+
+type natsQueue struct {
+	js      nats.JetStreamContext
+	name    string
+	ackWait time.Duration
+	log     logrus.Ext1FieldLogger
+}
+
+// NewNATSQueue creates a new Queue backed by a NATS JetStream work queue stream of the given
+// name. Messages which are not acknowledged within ackWait of Genesis exiting are redelivered.
+// Until then, each delivered message is kept from being redelivered by marking it as in progress
+// every half of ackWait, so that steps may wait in the scheduler and execute for longer than it.
+func NewNATSQueue(
+	js nats.JetStreamContext,
+	name string,
+	ackWait time.Duration,
+	log logrus.Ext1FieldLogger) Queue {
+	return &natsQueue{js: js, name: name, ackWait: ackWait, log: log}
+}
+
+// Consume immediately starts delivering queued messages
+func (nq *natsQueue) Consume() (<-chan amqp.Delivery, error) {
+	sub, err := nq.js.PullSubscribe(nq.name, nq.name, nats.ManualAck(), nats.AckExplicit(),
+		nats.AckWait(nq.ackWait))
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan amqp.Delivery)
+	go func() {
+		for {
+			msgs, err := sub.Fetch(1)
+			if err == nats.ErrTimeout {
+				continue
+			}
+			if err != nil {
+				nq.log.WithFields(logrus.Fields{
+					"queue": nq.name,
+					"err":   err}).Warn("unable to fetch a message")
+				time.Sleep(time.Second)
+				continue
+			}
+			for _, msg := range msgs {
+				delivery, err := natsToDelivery(msg)
+				if err != nil {
+					nq.log.WithFields(logrus.Fields{
+						"queue": nq.name,
+						"err":   err}).Error("received a malformed message")
+				}
+				if wait := notBefore(delivery); wait > 0 {
+					err = msg.NakWithDelay(wait)
+					if err != nil {
+						nq.log.WithFields(logrus.Fields{
+							"queue": nq.name,
+							"err":   err}).Warn("unable to delay a message")
+					}
+					continue
+				}
+				go delivery.Acknowledger.(*natsAcknowledger).heartbeat(nq.ackWait/2, nq.log)
+				out <- delivery
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Send places a message into the queue
+func (nq *natsQueue) Send(pub amqp.Publishing) error {
+	msg, err := publishingToNATS(nq.name, pub)
+	if err != nil {
+		return err
+	}
+	_, err = nq.js.PublishMsg(msg)
+	return err
+}
+
+// Requeue rejects the oldMsg and queues the newMsg
+func (nq *natsQueue) Requeue(oldMsg amqp.Delivery, newMsg amqp.Publishing) error {
+	err := nq.Send(newMsg)
+	if err != nil {
+		return err
+	}
+	return oldMsg.Reject(false)
+}
+
+// CreateQueue creates the stream for this queue, if it does not already exist
+func (nq *natsQueue) CreateQueue() error {
+	_, err := nq.js.StreamInfo(nq.name)
+	if err == nil {
+		return nil
+	}
+	_, err = nq.js.AddStream(&nats.StreamConfig{
+		Name:      nq.name,
+		Subjects:  []string{nq.name},
+		Retention: nats.WorkQueuePolicy,
+		Storage:   nats.FileStorage,
+	})
+	return err
+}
+
+func publishingToNATS(subject string, pub amqp.Publishing) (*nats.Msg, error) {
+	msg := nats.NewMsg(subject)
+	msg.Data = pub.Body
+	if pub.ContentType != "" {
+		msg.Header.Set(ContentTypeHeader, pub.ContentType)
+	}
+	if len(pub.Headers) == 0 {
+		return msg, nil
+	}
+	headers, err := json.Marshal(pub.Headers)
+	if err != nil {
+		return nil, err
+	}
+	msg.Header.Set(HeadersHeader, string(headers))
+	return msg, nil
+}
+
+// notBefore gets how much longer the delivery must wait before it can be delivered
+func notBefore(delivery amqp.Delivery) time.Duration {
+	millis, ok := delivery.Headers[NotBeforeHeader].(int64)
+	if !ok {
+		return 0
+	}
+	return time.Until(time.Unix(0, millis*int64(time.Millisecond)))
+}
+
+// natsToDelivery converts a NATS message to an AMQP delivery. If the headers cannot be
+// decoded, the delivery is still returned without them, so that it can be settled.
+func natsToDelivery(msg *nats.Msg) (amqp.Delivery, error) {
+	out := amqp.Delivery{
+		Acknowledger: newNATSAcknowledger(msg),
+		RoutingKey:   msg.Subject,
+		ContentType:  msg.Header.Get(ContentTypeHeader),
+		Body:         msg.Data,
+	}
+	if meta, err := msg.Metadata(); err == nil {
+		out.DeliveryTag = meta.Sequence.Stream
+		out.Redelivered = meta.NumDelivered > 1
+		out.Timestamp = meta.Timestamp
+	}
+
+	raw := msg.Header.Get(HeadersHeader)
+	if raw == "" {
+		return out, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	dec.UseNumber()
+	headers := map[string]interface{}{}
+	err := dec.Decode(&headers)
+	if err != nil {
+		return out, err
+	}
+	out.Headers = amqp.Table(fromJSONNumbers(headers).(map[string]interface{}))
+	return out, nil
+}
+
+// fromJSONNumbers restores the integer header values, such as the retry count, to int64
+func fromJSONNumbers(val interface{}) interface{} {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = fromJSONNumbers(v[key])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = fromJSONNumbers(v[i])
+		}
+		return v
+	}
+	return val
+}
+
+// natsAcknowledger settles AMQP deliveries by acknowledging the underlying NATS message
+type natsAcknowledger struct {
+	msg  *nats.Msg
+	done chan struct{}
+	once *sync.Once
+}
+
+func newNATSAcknowledger(msg *nats.Msg) *natsAcknowledger {
+	return &natsAcknowledger{msg: msg, done: make(chan struct{}), once: &sync.Once{}}
+}
+
+// heartbeat marks the message as in progress every interval, until it is settled
+func (na *natsAcknowledger) heartbeat(interval time.Duration, log logrus.Ext1FieldLogger) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-na.done:
+			return
+		case <-ticker.C:
+			err := na.msg.InProgress()
+			if err != nil {
+				log.WithFields(logrus.Fields{
+					"subject": na.msg.Subject,
+					"err":     err}).Warn("unable to mark a message as in progress")
+			}
+		}
+	}
+}
+
+func (na *natsAcknowledger) settle() {
+	na.once.Do(func() { close(na.done) })
+}
+
+// Ack acknowledges the message
+func (na *natsAcknowledger) Ack(tag uint64, multiple bool) error {
+	na.settle()
+	return na.msg.Ack()
+}
+
+// Nack either requests redelivery of the message, or terminates it
+func (na *natsAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	na.settle()
+	if requeue {
+		return na.msg.Nak()
+	}
+	return na.msg.Term()
+}
+
+// Reject either requests redelivery of the message, or terminates it
+func (na *natsAcknowledger) Reject(tag uint64, requeue bool) error {
+	return na.Nack(tag, false, requeue)
+}
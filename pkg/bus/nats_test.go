@@ -0,0 +1,92 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishingToNATS(t *testing.T) {
+	pub := amqp.Publishing{
+		Headers: amqp.Table{
+			"retryCount": int64(3),
+			"attempts":   `[{"error":"test"}]`,
+			"ratio":      0.5,
+		},
+		ContentType: "application/json",
+		Body:        []byte(`{"id":"test"}`),
+	}
+	msg, err := publishingToNATS("commands", pub)
+	require.NoError(t, err)
+	assert.Equal(t, "commands", msg.Subject)
+	assert.Equal(t, pub.Body, msg.Data)
+
+	delivery, err := natsToDelivery(msg)
+	require.NoError(t, err)
+	assert.Equal(t, pub.Headers, delivery.Headers)
+	assert.Equal(t, pub.ContentType, delivery.ContentType)
+	assert.Equal(t, pub.Body, delivery.Body)
+	assert.Equal(t, "commands", delivery.RoutingKey)
+}
+
+func TestPublishingToNATS_NoHeaders(t *testing.T) {
+	msg, err := publishingToNATS("commands", amqp.Publishing{Body: []byte("test")})
+	require.NoError(t, err)
+	assert.Empty(t, msg.Header.Get(HeadersHeader))
+
+	delivery, err := natsToDelivery(msg)
+	require.NoError(t, err)
+	assert.Nil(t, delivery.Headers)
+}
+
+func TestNATSToDelivery_Malformed(t *testing.T) {
+	msg := nats.NewMsg("commands")
+	msg.Data = []byte("test")
+	msg.Header.Set(HeadersHeader, "{")
+
+	delivery, err := natsToDelivery(msg)
+	assert.Error(t, err)
+	assert.Equal(t, msg.Data, delivery.Body)
+	assert.NotNil(t, delivery.Acknowledger)
+}
+
+func TestNotBefore(t *testing.T) {
+	future := time.Now().Add(time.Minute).UnixNano() / int64(time.Millisecond)
+	past := time.Now().Add(-time.Minute).UnixNano() / int64(time.Millisecond)
+
+	assert.Zero(t, notBefore(amqp.Delivery{}))
+	assert.True(t, notBefore(amqp.Delivery{Headers: amqp.Table{NotBeforeHeader: past}}) <= 0)
+	wait := notBefore(amqp.Delivery{Headers: amqp.Table{NotBeforeHeader: future}})
+	assert.True(t, wait > 59*time.Second && wait <= time.Minute)
+}
+
+func TestNATSAcknowledger_Heartbeat(t *testing.T) {
+	delivery, err := natsToDelivery(nats.NewMsg("commands"))
+	require.NoError(t, err)
+	ack := delivery.Acknowledger.(*natsAcknowledger)
+
+	stopped := make(chan struct{})
+	go func() {
+		ack.heartbeat(time.Millisecond, logrus.New())
+		close(stopped)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	delivery.Ack(false)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the heartbeat did not stop once the message was settled")
+	}
+}
@@ -0,0 +1,215 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/service"
+	"github.com/whiteblock/genesis/pkg/tracing"
+
+	"github.com/whiteblock/definition/command"
+	util "github.com/whiteblock/utility/utils"
+)
+
+// DefaultPollInterval is how often a job is polled when no interval is configured
+const DefaultPollInterval = 2 * time.Second
+
+// Config is the configuration of a Client
+type Config struct {
+	// Endpoint is the base url of the REST api, such as https://genesis:8000
+	Endpoint string
+	// HTTPClient sends the requests. If nil, http.DefaultClient is used. It should be given a
+	// TLS config to use a client certificate.
+	HTTPClient *http.Client
+	// Token is a bearer token sent with every request, if it is not empty
+	Token string
+	// HMACKeyID and HMACSecret sign every request, if HMACKeyID is not empty
+	HMACKeyID  string
+	HMACSecret string
+	// PollInterval is how often Watch and Wait poll a job
+	PollInterval time.Duration
+}
+
+// StatusError is the error returned when the REST api responds with an unexpected status
+type StatusError struct {
+	// StatusCode is the status code of the response
+	StatusCode int
+	// Message is the body of the response
+	Message string
+}
+
+// Error returns the status along with the message from the REST api
+func (se StatusError) Error() string {
+	return fmt.Sprintf("%d %s: %s", se.StatusCode, http.StatusText(se.StatusCode), se.Message)
+}
+
+// Client submits instructions to Genesis over its REST api and follows their progress
+type Client interface {
+	// Submit submits instructions without waiting for them to execute, returning their id.
+	// An id is generated for the instructions if they do not have one.
+	Submit(ctx context.Context, inst command.Instructions) (string, error)
+	// Run submits instructions and waits for their final result. If timeout is not zero, the
+	// REST api stops executing them once it passes.
+	Run(ctx context.Context, inst command.Instructions, timeout time.Duration) (entity.Result, error)
+	// Job gets the progress of the instructions with the given id
+	Job(ctx context.Context, id string) (entity.Job, error)
+	// Watch polls the job with the given id until it finishes, calling fn with the job each
+	// time it changes. It returns the finished job.
+	Watch(ctx context.Context, id string, fn func(entity.Job)) (entity.Job, error)
+	// Wait waits for the job with the given id to finish
+	Wait(ctx context.Context, id string) (entity.Job, error)
+}
+
+type client struct {
+	conf Config
+	http *http.Client
+}
+
+// NewClient creates a new Client for the REST api at conf.Endpoint
+func NewClient(conf Config) Client {
+	conf.Endpoint = strings.TrimSuffix(conf.Endpoint, "/")
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = DefaultPollInterval
+	}
+	httpClient := conf.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &client{conf: conf, http: httpClient}
+}
+
+func (c client) do(ctx context.Context, method, uri string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.conf.Endpoint+uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	tracing.InjectHTTP(ctx, req.Header)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.conf.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.conf.Token)
+	}
+	if c.conf.HMACKeyID != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(service.HMACKeyIDHeader, c.conf.HMACKeyID)
+		req.Header.Set(service.HMACTimestampHeader, timestamp)
+		req.Header.Set(service.HMACSignatureHeader, service.SignRequest(c.conf.HMACSecret,
+			method, req.URL.RequestURI(), timestamp, body))
+	}
+	return c.http.Do(req)
+}
+
+func statusError(res *http.Response) error {
+	data, _ := ioutil.ReadAll(io.LimitReader(res.Body, 4096))
+	return StatusError{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(data))}
+}
+
+// Submit submits instructions without waiting for them to execute, returning their id
+func (c client) Submit(ctx context.Context, inst command.Instructions) (string, error) {
+	if inst.ID == "" {
+		inst.ID = util.GetUUIDString()
+	}
+	data, err := json.Marshal(inst)
+	if err != nil {
+		return "", err
+	}
+	res, err := c.do(ctx, http.MethodPost, "/command", data)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", statusError(res)
+	}
+	return inst.ID, nil
+}
+
+// Run submits instructions and waits for their final result. The result is returned whenever
+// the REST api gives one, even if the instructions failed.
+func (c client) Run(ctx context.Context, inst command.Instructions,
+	timeout time.Duration) (entity.Result, error) {
+	data, err := json.Marshal(inst)
+	if err != nil {
+		return entity.Result{}, err
+	}
+	query := url.Values{"wait": {"true"}}
+	if timeout > 0 {
+		query.Set("timeout", timeout.String())
+	}
+	res, err := c.do(ctx, http.MethodPost, "/command?"+query.Encode(), data)
+	if err != nil {
+		return entity.Result{}, err
+	}
+	defer res.Body.Close()
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") {
+		return entity.Result{}, statusError(res)
+	}
+	var out entity.Result
+	return out, json.NewDecoder(res.Body).Decode(&out)
+}
+
+// Job gets the progress of the instructions with the given id
+func (c client) Job(ctx context.Context, id string) (entity.Job, error) {
+	res, err := c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), nil)
+	if err != nil {
+		return entity.Job{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return entity.Job{}, statusError(res)
+	}
+	var out entity.Job
+	return out, json.NewDecoder(res.Body).Decode(&out)
+}
+
+// Watch polls the job with the given id until it finishes, calling fn with the job each time
+// it changes. fn may be nil.
+func (c client) Watch(ctx context.Context, id string, fn func(entity.Job)) (entity.Job, error) {
+	ticker := time.NewTicker(c.conf.PollInterval)
+	defer ticker.Stop()
+
+	var last entity.Job
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil {
+			return last, err
+		}
+		changed := job.Status != last.Status || !job.Updated.Equal(last.Updated) ||
+			len(job.Steps) != len(last.Steps)
+		if changed && fn != nil {
+			fn(job)
+		}
+		last = job
+		if job.Status.IsFinished() {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Wait waits for the job with the given id to finish
+func (c client) Wait(ctx context.Context, id string) (entity.Job, error) {
+	return c.Watch(ctx, id, nil)
+}
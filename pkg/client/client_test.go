@@ -0,0 +1,179 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whiteblock/definition/command"
+)
+
+func TestClient_Submit(t *testing.T) {
+	keys := map[string]entity.Credential{"key": {Subject: "test", Secret: "secret"}}
+	auth := service.NewHMACAuthenticator(keys, time.Minute)
+
+	var got command.Instructions
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		_, ok, err := auth.Authenticate(r, body)
+		if !ok || err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.NoError(t, json.Unmarshal(body, &got))
+		w.Write([]byte("Success"))
+	}))
+	defer srv.Close()
+
+	cli := NewClient(Config{Endpoint: srv.URL + "/", Token: "token", HMACKeyID: "key",
+		HMACSecret: "secret"})
+	id, err := cli.Submit(context.Background(), command.Instructions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+	assert.Equal(t, id, got.ID)
+
+	id, err = cli.Submit(context.Background(), command.Instructions{ID: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, "test", id)
+
+	cli = NewClient(Config{Endpoint: srv.URL, HMACKeyID: "key", HMACSecret: "wrong"})
+	_, err = cli.Submit(context.Background(), command.Instructions{})
+	var statusErr StatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+	assert.Equal(t, "unauthorized", statusErr.Message)
+}
+
+func TestClient_Run(t *testing.T) {
+	var tests = []struct {
+		res     interface{}
+		status  int
+		timeout time.Duration
+		query   string
+		resType entity.ResultType
+		code    entity.ErrorCode
+	}{
+		{res: entity.NewAllDoneResult(), status: http.StatusOK, query: "wait=true",
+			resType: entity.AllDoneType},
+		{
+			res:     entity.NewFatalResult("err").WithCode(entity.CodeInvalidPayload),
+			status:  http.StatusBadRequest,
+			timeout: time.Minute,
+			query:   "timeout=1m0s&wait=true",
+			resType: entity.FatalType,
+			code:    entity.CodeInvalidPayload,
+		},
+		{res: "not json", status: http.StatusBadRequest, query: "wait=true"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.query, r.URL.RawQuery)
+				if msg, ok := tt.res.(string); ok {
+					http.Error(w, msg, tt.status)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(tt.res)
+			}))
+			defer srv.Close()
+
+			res, err := NewClient(Config{Endpoint: srv.URL}).Run(context.Background(),
+				command.Instructions{ID: "test"}, tt.timeout)
+			if tt.resType == 0 {
+				assert.Equal(t, StatusError{StatusCode: tt.status, Message: tt.res.(string)}, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.resType, res.Type)
+			assert.Equal(t, tt.code, res.ErrorCode())
+		})
+	}
+}
+
+func TestClient_Job(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jobs/test" {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(entity.Job{ID: "test", Status: entity.JobRunning})
+	}))
+	defer srv.Close()
+
+	cli := NewClient(Config{Endpoint: srv.URL})
+	job, err := cli.Job(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, "test", job.ID)
+	assert.Equal(t, entity.JobRunning, job.Status)
+
+	_, err = cli.Job(context.Background(), "missing")
+	assert.Equal(t, StatusError{StatusCode: http.StatusNotFound, Message: "job not found"}, err)
+}
+
+func TestClient_Watch(t *testing.T) {
+	now := time.Now()
+	jobs := []entity.Job{
+		{ID: "test", Status: entity.JobRunning, Updated: now},
+		{ID: "test", Status: entity.JobRunning, Updated: now},
+		{ID: "test", Status: entity.JobRunning, Updated: now.Add(time.Second),
+			Steps: []entity.JobStep{{Round: 0}}},
+		{ID: "test", Status: entity.JobCompleted, Updated: now.Add(2 * time.Second),
+			Steps: []entity.JobStep{{Round: 0}, {Round: 1}}},
+	}
+
+	var mu sync.Mutex
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(jobs[polls])
+		polls++
+	}))
+	defer srv.Close()
+
+	cli := NewClient(Config{Endpoint: srv.URL, PollInterval: time.Millisecond})
+	seen := []entity.JobStatus{}
+	job, err := cli.Watch(context.Background(), "test", func(job entity.Job) {
+		seen = append(seen, job.Status)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobCompleted, job.Status)
+	assert.Equal(t, []entity.JobStatus{entity.JobRunning, entity.JobRunning, entity.JobCompleted}, seen)
+	assert.Equal(t, len(jobs), polls)
+}
+
+func TestClient_Wait_Canceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(entity.Job{ID: "test", Status: entity.JobRunning})
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	job, err := NewClient(Config{Endpoint: srv.URL, PollInterval: time.Millisecond}).Wait(ctx, "test")
+	assert.Error(t, err)
+	assert.Equal(t, entity.JobRunning, job.Status)
+}
@@ -0,0 +1,48 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/whiteblock/definition/command/biome"
+	"github.com/whiteblock/utility/common"
+)
+
+// DecodeResult decodes a result, such as the body of a message on the errors queue or the
+// result of a step of a job
+func DecodeResult(data []byte) (entity.Result, error) {
+	var out entity.Result
+	return out, json.Unmarshal(data, &out)
+}
+
+// DecodeStatus decodes the body of a message on the status queue
+func DecodeStatus(data []byte) (common.Status, error) {
+	var out common.Status
+	return out, json.Unmarshal(data, &out)
+}
+
+// DecodeCompletion decodes the body of a message on the completion queue
+func DecodeCompletion(data []byte) (biome.DestroyBiome, error) {
+	var out biome.DestroyBiome
+	return out, json.Unmarshal(data, &out)
+}
+
+// StepResults decodes the result of each step of a job, in the order they were attempted
+func StepResults(job entity.Job) ([]entity.Result, error) {
+	out := make([]entity.Result, len(job.Steps))
+	for i, step := range job.Steps {
+		res, err := DecodeResult(step.Result)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = res
+	}
+	return out, nil
+}
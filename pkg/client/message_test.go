@@ -0,0 +1,69 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	queue "github.com/whiteblock/amqp"
+	"github.com/whiteblock/definition/command"
+	"github.com/whiteblock/definition/command/biome"
+)
+
+func TestDecodeResult(t *testing.T) {
+	msg, err := queue.CreateMessage(entity.NewFatalResult("err").WithCode(entity.CodeTimeout))
+	require.NoError(t, err)
+
+	res, err := DecodeResult(msg.Body)
+	require.NoError(t, err)
+	assert.True(t, res.IsFatal())
+	assert.EqualError(t, res.Error, "err")
+	assert.Equal(t, entity.CodeTimeout, res.ErrorCode())
+
+	_, err = DecodeResult([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestDecodeStatus(t *testing.T) {
+	inst := command.Instructions{ID: "test", OrgID: "org", Commands: [][]command.Command{{}}}
+	msg, err := queue.CreateMessage(inst.Status())
+	require.NoError(t, err)
+
+	status, err := DecodeStatus(msg.Body)
+	require.NoError(t, err)
+	assert.Equal(t, inst.Status(), status)
+}
+
+func TestDecodeCompletion(t *testing.T) {
+	msg, err := queue.CreateMessage(biome.DestroyBiome{TestID: "test", DefinitionID: "def"})
+	require.NoError(t, err)
+
+	done, err := DecodeCompletion(msg.Body)
+	require.NoError(t, err)
+	assert.Equal(t, biome.DestroyBiome{TestID: "test", DefinitionID: "def"}, done)
+}
+
+func TestStepResults(t *testing.T) {
+	first, err := json.Marshal(entity.NewErrorResult("err"))
+	require.NoError(t, err)
+	second, err := json.Marshal(entity.NewSuccessResult())
+	require.NoError(t, err)
+
+	results, err := StepResults(entity.Job{Steps: []entity.JobStep{{Result: first}, {Result: second}}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.EqualError(t, results[0].Error, "err")
+	assert.True(t, results[1].IsSuccess())
+
+	_, err = StepResults(entity.Job{Steps: []entity.JobStep{{Result: []byte("[")}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Auth is the configuration for the authentication of REST requests. Each method of
+// authentication is enabled by giving the file which holds its credentials.
+type Auth struct {
+	// TokensFile is the location of a JSON file mapping each bearer token to its credential
+	TokensFile string `mapstructure:"authTokensFile"`
+	// HMACKeysFile is the location of a JSON file mapping each HMAC key id to its credential,
+	// including its secret
+	HMACKeysFile string `mapstructure:"authHMACKeysFile"`
+	// HMACMaxSkew is how far the timestamp of a signed request may be from the current time
+	HMACMaxSkew time.Duration `mapstructure:"authHMACMaxSkew"`
+	// JWKSFile is the location of the JSON web key set used to verify JWTs
+	JWKSFile string `mapstructure:"authJWKSFile"`
+	// JWTIssuer is the required issuer of JWTs, if not empty
+	JWTIssuer string `mapstructure:"authJWTIssuer"`
+	// JWTAudience is the required audience of JWTs, if not empty
+	JWTAudience string `mapstructure:"authJWTAudience"`
+	// JWTOrgClaim is the claim of a JWT which holds the org ids it may act on
+	JWTOrgClaim string `mapstructure:"authJWTOrgClaim"`
+}
+
+// Enabled returns true if any method of authentication is configured
+func (a Auth) Enabled() bool {
+	return a.TokensFile != "" || a.HMACKeysFile != "" || a.JWKSFile != ""
+}
+
+// NewAuth creates a new Auth config from the given viper
+func NewAuth(v *viper.Viper) (out Auth, err error) {
+	return out, v.Unmarshal(&out)
+}
+
+func setAuthBindings(v *viper.Viper) error {
+	err := v.BindEnv("authTokensFile", "AUTH_TOKENS_FILE")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("authHMACKeysFile", "AUTH_HMAC_KEYS_FILE")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("authHMACMaxSkew", "AUTH_HMAC_MAX_SKEW")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("authJWKSFile", "AUTH_JWKS_FILE")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("authJWTIssuer", "AUTH_JWT_ISSUER")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("authJWTAudience", "AUTH_JWT_AUDIENCE")
+	if err != nil {
+		return err
+	}
+	return v.BindEnv("authJWTOrgClaim", "AUTH_JWT_ORG_CLAIM")
+}
+
+func setAuthDefaults(v *viper.Viper) {
+	v.SetDefault("authHMACMaxSkew", "5m")
+	v.SetDefault("authJWTOrgClaim", "orgs")
+}
@@ -0,0 +1,64 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// AMQPBus is the RabbitMQ message bus
+	AMQPBus = "amqp"
+	// NATSBus is the NATS JetStream message bus
+	NATSBus = "nats"
+	// MemoryBus is the in process message bus
+	MemoryBus = "memory"
+)
+
+// Bus is the configuration for the message bus which carries the instructions
+type Bus struct {
+	// Backend is the message bus implementation to use, one of amqp, nats or memory
+	Backend string `mapstructure:"messageBus"`
+	// NATSURL is the url of the NATS server
+	NATSURL string `mapstructure:"natsURL"`
+	// NATSAckWait is how long NATS waits for a message to be marked as in progress or settled
+	// before redelivering it
+	NATSAckWait time.Duration `mapstructure:"natsAckWait"`
+	// MemoryQueueLimit is the maximum number of messages an in memory queue holds before
+	// sending to it fails. Zero means unlimited.
+	MemoryQueueLimit int `mapstructure:"memoryQueueLimit"`
+}
+
+// NewBus creates a new Bus config from the given viper
+func NewBus(v *viper.Viper) (out Bus, err error) {
+	return out, v.Unmarshal(&out)
+}
+
+func setBusBindings(v *viper.Viper) error {
+	err := v.BindEnv("messageBus", "MESSAGE_BUS")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("natsURL", "NATS_URL")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("natsAckWait", "NATS_ACK_WAIT")
+	if err != nil {
+		return err
+	}
+	return v.BindEnv("memoryQueueLimit", "MEMORY_QUEUE_LIMIT")
+}
+
+func setBusDefaults(v *viper.Viper) {
+	v.SetDefault("messageBus", AMQPBus)
+	v.SetDefault("natsURL", "nats://127.0.0.1:4222")
+	v.SetDefault("natsAckWait", "10m")
+	v.SetDefault("memoryQueueLimit", 10000)
+}
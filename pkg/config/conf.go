@@ -7,11 +7,15 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/whiteblock/genesis/pkg/entity"
 
 	joonix "github.com/joonix/log"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"github.com/streadway/amqp"
 	queue "github.com/whiteblock/amqp"
 )
 
@@ -24,6 +28,7 @@ type Config struct {
 	CommandQueueName    string `mapstructure:"commandQueueName"`
 	ErrorQueueName      string `mapstructure:"errorQueueName"`
 	StatusQueueName     string `mapstructure:"statusQueueName"`
+	DeadLetterQueueName string `mapstructure:"deadLetterQueueName"`
 
 	// LocalMode indicates that Genesis is operating in standalone mode
 	LocalMode        bool              `mapstructure:"localMode"`
@@ -33,9 +38,25 @@ type Config struct {
 	FluentDLogging   bool              `mapstructure:"fluentDLogging"`
 	Listen           string            `mapstructure:"listen"`
 
+	// RestCertPath and RestKeyPath are the certificate and key the REST api is served with.
+	// If they are empty, it is served without TLS.
+	RestCertPath string `mapstructure:"restCertPath"`
+	RestKeyPath  string `mapstructure:"restKeyPath"`
+	// RestClientCAPath is the CA which REST clients must present a certificate from, if not empty
+	RestClientCAPath string `mapstructure:"restClientCAPath"`
+	// RestMinTLSVersion is the minimum version of TLS accepted by the REST api
+	RestMinTLSVersion string `mapstructure:"restMinTLSVersion"`
+
 	Execution   Execution   `mapstructure:"-"`
 	Docker      Docker      `mapstructure:"-"`
 	FileHandler FileHandler `mapstructure:"-"`
+	Retry       Retry       `mapstructure:"-"`
+	Bus         Bus         `mapstructure:"-"`
+	Scheduler   Scheduler   `mapstructure:"-"`
+	State       State       `mapstructure:"-"`
+	Auth        Auth        `mapstructure:"-"`
+	Tracing     Tracing     `mapstructure:"-"`
+	Readiness   Readiness   `mapstructure:"-"`
 }
 
 // GetLogger gets a logger according to the config
@@ -84,13 +105,41 @@ func (c Config) StatusAMQP() (queue.AMQPConfig, error) {
 	return conf, err
 }
 
+// DeadLetterAMQP gets the AMQP for the dead letter queue
+func (c Config) DeadLetterAMQP() (queue.AMQPConfig, error) {
+	conf, err := queue.NewAMQPConfig(viper.GetViper())
+	conf.QueueName = c.DeadLetterQueueName
+	return conf, err
+}
+
+// RetryAMQP gets the AMQP for the delay queue of the given delay. Messages placed on this
+// queue expire after the delay and are dead-lettered back onto the command queue
+func (c Config) RetryAMQP(delay time.Duration) (queue.AMQPConfig, error) {
+	conf, err := queue.NewAMQPConfig(viper.GetViper())
+	conf.QueueName = fmt.Sprintf("%s-retry-%d", c.CommandQueueName, delay.Milliseconds())
+	conf.Queue.Args = amqp.Table{
+		"x-message-ttl":             delay.Milliseconds(),
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": c.CommandQueueName,
+	}
+	conf.Publish.Exchange = ""
+	return conf, err
+}
+
 // GetRestConfig extracts the fields of this object representing RestConfig
 func (c Config) GetRestConfig() entity.RestConfig {
-	return entity.RestConfig{Listen: c.Listen}
+	return entity.RestConfig{
+		Listen:        c.Listen,
+		CertPath:      c.RestCertPath,
+		KeyPath:       c.RestKeyPath,
+		ClientCAPath:  c.RestClientCAPath,
+		MinTLSVersion: c.RestMinTLSVersion,
+	}
 }
 
 func setViperEnvBindings() {
 	viper.BindEnv("statusQueueName", "STATUS_QUEUE_NAME")
+	viper.BindEnv("deadLetterQueueName", "DEAD_LETTER_QUEUE_NAME")
 	viper.BindEnv("fluentDLogging", "FLUENT_D_LOGGING")
 	viper.BindEnv("maxMessageRetries", "MAX_MESSAGE_RETRIES")
 	viper.BindEnv("queueMaxConcurrency", "QUEUE_MAX_CONCURRENCY")
@@ -100,16 +149,28 @@ func setViperEnvBindings() {
 	viper.BindEnv("volumeDriverOpts", "VOLUME_DRIVER_OPTS")
 	viper.BindEnv("verbosity", "VERBOSITY")
 	viper.BindEnv("listen", "LISTEN")
+	viper.BindEnv("restCertPath", "REST_CERT_PATH")
+	viper.BindEnv("restKeyPath", "REST_KEY_PATH")
+	viper.BindEnv("restClientCAPath", "REST_CLIENT_CA_PATH")
+	viper.BindEnv("restMinTLSVersion", "REST_MIN_TLS_VERSION")
 	viper.BindEnv("completionQueueName", "COMPLETION_QUEUE_NAME")
 	viper.BindEnv("commandQueueName", "COMMAND_QUEUE_NAME")
 	viper.BindEnv("errorQueueName", "ERROR_QUEUE_NAME")
 	setExecutionBindings(viper.GetViper())
 	setDockerBindings(viper.GetViper())
 	setFileHandlerBindings(viper.GetViper())
+	setRetryBindings(viper.GetViper())
+	setBusBindings(viper.GetViper())
+	setSchedulerBindings(viper.GetViper())
+	setStateBindings(viper.GetViper())
+	setAuthBindings(viper.GetViper())
+	setTracingBindings(viper.GetViper())
+	setReadinessBindings(viper.GetViper())
 }
 
 func setViperDefaults() {
 	viper.SetDefault("statusQueueName", "status")
+	viper.SetDefault("deadLetterQueueName", "deadLetters")
 	viper.SetDefault("fluentDLogging", true)
 	viper.SetDefault("completionQueueName", "teardownRequests")
 	viper.SetDefault("commandQueueName", "commands")
@@ -117,12 +178,20 @@ func setViperDefaults() {
 	viper.SetDefault("queueMaxConcurrency", 20)
 	viper.SetDefault("verbosity", "INFO")
 	viper.SetDefault("listen", "0.0.0.0:8000")
+	viper.SetDefault("restMinTLSVersion", "1.2")
 	viper.SetDefault("localMode", true)
 	viper.SetDefault("errorQueueName", "errors")
 
 	setExecutionDefaults(viper.GetViper())
 	setDockerDefaults(viper.GetViper())
 	setFileHandlerDefaults(viper.GetViper())
+	setRetryDefaults(viper.GetViper())
+	setBusDefaults(viper.GetViper())
+	setSchedulerDefaults(viper.GetViper())
+	setStateDefaults(viper.GetViper())
+	setAuthDefaults(viper.GetViper())
+	setTracingDefaults(viper.GetViper())
+	setReadinessDefaults(viper.GetViper())
 }
 
 func init() {
@@ -154,6 +223,41 @@ func NewConfig() (conf Config, err error) {
 		return
 	}
 
+	conf.Retry, err = NewRetry(viper.GetViper())
+	if err != nil {
+		return
+	}
+
+	conf.Bus, err = NewBus(viper.GetViper())
+	if err != nil {
+		return
+	}
+
+	conf.Scheduler, err = NewScheduler(viper.GetViper())
+	if err != nil {
+		return
+	}
+
+	conf.State, err = NewState(viper.GetViper())
+	if err != nil {
+		return
+	}
+
+	conf.Auth, err = NewAuth(viper.GetViper())
+	if err != nil {
+		return
+	}
+
+	conf.Tracing, err = NewTracing(viper.GetViper())
+	if err != nil {
+		return
+	}
+
+	conf.Readiness, err = NewReadiness(viper.GetViper())
+	if err != nil {
+		return
+	}
+
 	conf.Docker, err = NewDocker(viper.GetViper())
 	return
 }
@@ -9,11 +9,14 @@ package config
 import (
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/whiteblock/genesis/pkg/entity"
 
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfig_GetRestConfig(t *testing.T) {
@@ -29,6 +32,22 @@ func TestConfig_GetRestConfig(t *testing.T) {
 				Listen: "129.9.9.0:3000",
 			},
 		},
+		{
+			conf: Config{
+				Listen:            "129.9.9.0:3000",
+				RestCertPath:      "/cert.pem",
+				RestKeyPath:       "/key.pem",
+				RestClientCAPath:  "/ca.pem",
+				RestMinTLSVersion: "1.3",
+			},
+			expectedRestConf: entity.RestConfig{
+				Listen:        "129.9.9.0:3000",
+				CertPath:      "/cert.pem",
+				KeyPath:       "/key.pem",
+				ClientCAPath:  "/ca.pem",
+				MinTLSVersion: "1.3",
+			},
+		},
 	}
 
 	for i, tt := range tests {
@@ -74,3 +93,224 @@ func TestConfig_CommandAMQP(t *testing.T) {
 	res, _ := conf.CommandAMQP()
 	assert.Equal(t, conf.CommandQueueName, res.QueueName)
 }
+
+func TestRetry_Delays(t *testing.T) {
+	var tests = []struct {
+		conf     Retry
+		expected []time.Duration
+	}{
+		{
+			conf:     Retry{},
+			expected: nil,
+		},
+		{
+			conf:     Retry{BaseDelay: time.Second, MaxDelay: time.Second},
+			expected: []time.Duration{time.Second},
+		},
+		{
+			conf: Retry{BaseDelay: time.Second, MaxDelay: 5 * time.Second},
+			expected: []time.Duration{time.Second, 2 * time.Second,
+				4 * time.Second, 5 * time.Second},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.conf.Delays())
+		})
+	}
+}
+
+func TestConfig_RetryAMQP(t *testing.T) {
+	conf := Config{
+		CommandQueueName: "comm",
+	}
+	res, _ := conf.RetryAMQP(2 * time.Second)
+	assert.Equal(t, "comm-retry-2000", res.QueueName)
+	assert.Equal(t, "comm", res.Queue.Args["x-dead-letter-routing-key"])
+	assert.Equal(t, int64(2000), res.Queue.Args["x-message-ttl"])
+}
+
+func TestNewScheduler(t *testing.T) {
+	var tests = []struct {
+		weights  interface{}
+		expected map[string]int
+	}{
+		{weights: `{"a":3,"b":2}`, expected: map[string]int{"a": 3, "b": 2}},
+		{weights: map[string]interface{}{"a": 3}, expected: map[string]int{"a": 3}},
+		{weights: nil, expected: map[string]int{}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := viper.New()
+			setSchedulerDefaults(v)
+			v.Set("queueMaxConcurrency", 5)
+			if tt.weights != nil {
+				v.Set("orgWeights", tt.weights)
+			}
+			conf, err := NewScheduler(v)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), conf.MaxConcurrency)
+			assert.Equal(t, tt.expected, conf.OrgWeights)
+			assert.Equal(t, 1, conf.Weight("c"))
+		})
+	}
+}
+
+func TestNewDocker_Hosts(t *testing.T) {
+	var tests = []struct {
+		hosts    interface{}
+		expected map[string]entity.DockerHost
+		err      bool
+	}{
+		{hosts: nil, expected: map[string]entity.DockerHost{}},
+		{hosts: `{"10.0.0.2":{"endpoint":"ssh://genesis@{host}"}}`,
+			expected: map[string]entity.DockerHost{"10.0.0.2": {Endpoint: "ssh://genesis@{host}"}}},
+		{hosts: map[string]interface{}{"*": map[string]interface{}{"serverName": "docker"}},
+			expected: map[string]entity.DockerHost{"*": {ServerName: "docker"}}},
+		{hosts: `[1,2]`, err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := viper.New()
+			setDockerDefaults(v)
+			if tt.hosts != nil {
+				v.Set("dockerHosts", tt.hosts)
+			}
+			conf, err := NewDocker(v)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, conf.TLSVerify)
+			assert.Equal(t, 30*time.Second, conf.DiagnosisTimeout)
+			assert.Equal(t, tt.expected, conf.Hosts)
+		})
+	}
+}
+
+func TestDocker_Host(t *testing.T) {
+	yes, no := true, false
+	conf := Docker{
+		CACertPath: "/ca.pem",
+		CertPath:   "/cert.pem",
+		KeyPath:    "/key.pem",
+		DaemonPort: "2376",
+		TLSVerify:  true,
+		Hosts: map[string]entity.DockerHost{
+			"10.0.0.0/16": {CACertPath: "/pool/ca.pem", ServerName: "{host}.docker"},
+			"10.0.1.0/24": {Endpoint: "ssh://genesis@{host}:2222"},
+			"10.0.1.5":    {Endpoint: "unix:///var/run/docker.sock"},
+			"10.0.1.6":    {InsecureSkipVerify: &yes},
+		},
+	}
+
+	var tests = []struct {
+		conf     Docker
+		host     string
+		expected entity.DockerHost
+	}{
+		{
+			conf: conf,
+			host: "192.168.0.2",
+			expected: entity.DockerHost{Endpoint: "tcp://192.168.0.2:2376", CACertPath: "/ca.pem",
+				CertPath: "/cert.pem", KeyPath: "/key.pem", ServerName: "192.168.0.2",
+				InsecureSkipVerify: &no},
+		},
+		{
+			conf: conf,
+			host: "10.0.2.3",
+			expected: entity.DockerHost{Endpoint: "tcp://10.0.2.3:2376", CACertPath: "/pool/ca.pem",
+				CertPath: "/cert.pem", KeyPath: "/key.pem", ServerName: "10.0.2.3.docker",
+				InsecureSkipVerify: &no},
+		},
+		{
+			conf: conf,
+			host: "10.0.1.3",
+			expected: entity.DockerHost{Endpoint: "ssh://genesis@10.0.1.3:2222", CACertPath: "/ca.pem",
+				CertPath: "/cert.pem", KeyPath: "/key.pem", ServerName: "10.0.1.3",
+				InsecureSkipVerify: &no},
+		},
+		{
+			conf: conf,
+			host: "10.0.1.5",
+			expected: entity.DockerHost{Endpoint: "unix:///var/run/docker.sock", CACertPath: "/ca.pem",
+				CertPath: "/cert.pem", KeyPath: "/key.pem", ServerName: "10.0.1.5",
+				InsecureSkipVerify: &no},
+		},
+		{
+			conf: Docker{DaemonPort: "2376", Hosts: map[string]entity.DockerHost{
+				AllHosts: {Endpoint: "ssh://{host}"}}},
+			host: "10.0.0.1",
+			expected: entity.DockerHost{Endpoint: "ssh://10.0.0.1", ServerName: "10.0.0.1",
+				InsecureSkipVerify: &yes},
+		},
+		{
+			conf: conf,
+			host: "10.0.1.6",
+			expected: entity.DockerHost{Endpoint: "tcp://10.0.1.6:2376", CACertPath: "/ca.pem",
+				CertPath: "/cert.pem", KeyPath: "/key.pem", ServerName: "10.0.1.6",
+				InsecureSkipVerify: &yes},
+		},
+		{
+			conf: Docker{DaemonPort: "2376", Hosts: map[string]entity.DockerHost{
+				AllHosts:   {InsecureSkipVerify: &yes},
+				"10.0.0.1": {InsecureSkipVerify: &no}}},
+			host: "10.0.0.1",
+			expected: entity.DockerHost{Endpoint: "tcp://10.0.0.1:2376", ServerName: "10.0.0.1",
+				InsecureSkipVerify: &no},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.conf.Host(tt.host))
+		})
+	}
+}
+
+func TestTracingSanityCheck(t *testing.T) {
+	var tests = []struct {
+		conf   Tracing
+		panics bool
+	}{
+		{conf: Tracing{}, panics: false},
+		{conf: Tracing{Exporter: OTLPTracing, OTLPEndpoint: "localhost:4318", SampleRatio: 1}, panics: false},
+		{conf: Tracing{Exporter: FileTracing, File: "traces.json", SampleRatio: 0.5}, panics: false},
+		{conf: Tracing{Exporter: FileTracing, SampleRatio: 1}, panics: true},
+		{conf: Tracing{Exporter: OTLPTracing, OTLPEndpoint: "localhost:4318", SampleRatio: 2}, panics: true},
+		{conf: Tracing{Exporter: "zipkin"}, panics: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if tt.panics {
+				assert.Panics(t, func() { tracingSanityCheck(tt.conf) })
+			} else {
+				assert.NotPanics(t, func() { tracingSanityCheck(tt.conf) })
+			}
+		})
+	}
+}
+
+func TestNewReadiness(t *testing.T) {
+	v := viper.New()
+	setReadinessDefaults(v)
+	conf, err := NewReadiness(v)
+	require.NoError(t, err)
+	assert.Empty(t, conf.DockerHosts)
+	assert.Equal(t, 5*time.Second, conf.Timeout)
+	assert.Equal(t, 10*time.Second, conf.CacheTTL)
+
+	v.Set("readyDockerHosts", "10.0.0.2,10.0.0.3")
+	v.Set("readyTimeout", "1s")
+	v.Set("readyCacheTTL", "0s")
+	conf, err = NewReadiness(v)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3"}, conf.DockerHosts)
+	assert.Equal(t, time.Second, conf.Timeout)
+	assert.Zero(t, conf.CacheTTL)
+}
@@ -7,9 +7,19 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
 	"github.com/spf13/viper"
 )
 
+// AllHosts is the key in Docker.Hosts of the settings used for every host without its own
+const AllHosts = "*"
+
 // Docker represents the configuration needed to communicate with docker daemons
 type Docker struct {
 	// CACertPath is the filepath to the CA Certificate
@@ -18,6 +28,18 @@ type Docker struct {
 	CertPath string `mapstructure:"dockerCertPath"`
 	// KeyPath is the filepath to the private key for TLS
 	KeyPath string `mapstructure:"dockerKeyPath"`
+	// TLSVerify causes the certificates of the docker daemons to be verified
+	TLSVerify bool `mapstructure:"dockerTLSVerify"`
+	// Hosts overrides how the docker daemons of some hosts are connected to. The keys are
+	// either the address of a host, a CIDR covering a pool of hosts or AllHosts.
+	Hosts map[string]entity.DockerHost `mapstructure:"-"`
+	// ClientIdleTimeout is how long a pooled docker client is kept without being used. If it
+	// is zero, clients are not pooled.
+	ClientIdleTimeout time.Duration `mapstructure:"dockerClientIdleTimeout"`
+	// ClientPingInterval is how often a pooled docker client is pinged before being reused
+	ClientPingInterval time.Duration `mapstructure:"dockerClientPingInterval"`
+	// DiagnosisTimeout is how long diagnosing the connection to a docker daemon may take
+	DiagnosisTimeout time.Duration `mapstructure:"dockerDiagnosisTimeout"`
 	// LocalMode causes the TLS parameters to be ignored and Genesis
 	// to assume that the docker daemon is on the local machine
 	LocalMode bool `mapstructure:"localMode"`
@@ -38,7 +60,71 @@ type Docker struct {
 
 // NewDocker creates a new docker configuration from viper
 func NewDocker(v *viper.Viper) (out Docker, err error) {
-	return out, v.Unmarshal(&out)
+	err = v.Unmarshal(&out)
+	if err != nil {
+		return
+	}
+	out.Hosts = map[string]entity.DockerHost{}
+	var data []byte
+	switch raw := v.Get("dockerHosts").(type) {
+	case nil:
+		return
+	case string:
+		if raw == "" {
+			return
+		}
+		data = []byte(raw)
+	default:
+		data, err = json.Marshal(raw)
+		if err != nil {
+			return
+		}
+	}
+	err = json.Unmarshal(data, &out.Hosts)
+	if err != nil {
+		err = fmt.Errorf("invalid docker hosts: %w", err)
+	}
+	return
+}
+
+// Defaults gets the settings of hosts which are not in Hosts
+func (d Docker) Defaults() entity.DockerHost {
+	insecure := !d.TLSVerify
+	return entity.DockerHost{
+		Endpoint:           "tcp://" + entity.HostPlaceholder + ":" + d.DaemonPort,
+		CACertPath:         d.CACertPath,
+		CertPath:           d.CertPath,
+		KeyPath:            d.KeyPath,
+		ServerName:         entity.HostPlaceholder,
+		InsecureSkipVerify: &insecure,
+	}
+}
+
+// Host gets the settings for connecting to the docker daemon of the given host. The
+// settings of the host itself are used first, then those of the smallest pool containing it,
+// then those for all hosts and finally the defaults.
+func (d Docker) Host(host string) entity.DockerHost {
+	defaults := d.Defaults()
+	if all, ok := d.Hosts[AllHosts]; ok {
+		defaults = all.Inherit(defaults, entity.HostPlaceholder)
+	}
+	if dh, ok := d.Hosts[host]; ok {
+		return dh.Inherit(defaults, host)
+	}
+	ip := net.ParseIP(host)
+	best := -1
+	var out entity.DockerHost
+	for key, dh := range d.Hosts {
+		_, pool, err := net.ParseCIDR(key)
+		if err != nil || ip == nil || !pool.Contains(ip) {
+			continue
+		}
+		if ones, _ := pool.Mask.Size(); ones > best {
+			best = ones
+			out = dh
+		}
+	}
+	return out.Inherit(defaults, host)
 }
 
 func setDockerBindings(v *viper.Viper) error {
@@ -82,6 +168,31 @@ func setDockerBindings(v *viper.Viper) error {
 		return err
 	}
 
+	err = v.BindEnv("dockerTLSVerify", "DOCKER_TLS_VERIFY")
+	if err != nil {
+		return err
+	}
+
+	err = v.BindEnv("dockerHosts", "DOCKER_HOSTS")
+	if err != nil {
+		return err
+	}
+
+	err = v.BindEnv("dockerClientIdleTimeout", "DOCKER_CLIENT_IDLE_TIMEOUT")
+	if err != nil {
+		return err
+	}
+
+	err = v.BindEnv("dockerClientPingInterval", "DOCKER_CLIENT_PING_INTERVAL")
+	if err != nil {
+		return err
+	}
+
+	err = v.BindEnv("dockerDiagnosisTimeout", "DOCKER_DIAGNOSIS_TIMEOUT")
+	if err != nil {
+		return err
+	}
+
 	return v.BindEnv("dockerKeyPath", "DOCKER_KEY_PATH")
 }
 
@@ -90,6 +201,10 @@ func setDockerDefaults(v *viper.Viper) {
 	v.SetDefault("dockerLogLabels", "org,name,testRun,test,phase")
 	v.SetDefault("dockerSwarmPort", 2477)
 	v.SetDefault("dockerDaemonPort", "2376")
+	v.SetDefault("dockerTLSVerify", true)
+	v.SetDefault("dockerClientIdleTimeout", "5m")
+	v.SetDefault("dockerClientPingInterval", "30s")
+	v.SetDefault("dockerDiagnosisTimeout", "30s")
 	v.SetDefault("dockerGlusterImage", "gcr.io/whiteblock/gluster:latest")
 	v.SetDefault("dockerGlusterDriver", "glusterfs")
 }
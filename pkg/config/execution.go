@@ -16,6 +16,12 @@ type Execution struct {
 	LimitPerTest      int64         `mapstructure:"executionLimitPerTest"`
 	ConnectionRetries int           `mapstructure:"executionConnectionRetries"`
 	RetryDelay        time.Duration `mapstructure:"executionRetryDelay"`
+	// BreakerThreshold is the number of times in a row a docker host may not be reached before
+	// its commands fail fast
+	BreakerThreshold int `mapstructure:"executionBreakerThreshold"`
+	// BreakerCooldown is how long the commands of an unreachable host fail fast for, before
+	// the host is probed again
+	BreakerCooldown time.Duration `mapstructure:"executionBreakerCooldown"`
 	// DebugMode causes Fatal errors to be replaced with trapping errors, which do
 	// not signal completion
 	DebugMode bool `mapstructure:"debugMode"`
@@ -35,6 +41,14 @@ func setExecutionBindings(v *viper.Viper) error {
 	if err != nil {
 		return err
 	}
+	err = v.BindEnv("executionBreakerThreshold", "EXECUTION_BREAKER_THRESHOLD")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("executionBreakerCooldown", "EXECUTION_BREAKER_COOLDOWN")
+	if err != nil {
+		return err
+	}
 	err = v.BindEnv("debugMode", "DEBUG_MODE")
 	if err != nil {
 		return err
@@ -46,5 +60,7 @@ func setExecutionDefaults(v *viper.Viper) {
 	v.SetDefault("executionLimitPerTest", 40)
 	v.SetDefault("executionConnectionRetries", 5)
 	v.SetDefault("executionRetryDelay", "10s")
+	v.SetDefault("executionBreakerThreshold", 3)
+	v.SetDefault("executionBreakerCooldown", "30s")
 	v.SetDefault("debugMode", true)
 }
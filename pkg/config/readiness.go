@@ -0,0 +1,46 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Readiness is the configuration for the checking of whether Genesis can reach its dependencies
+type Readiness struct {
+	// DockerHosts are the docker hosts which must be reachable for Genesis to be ready
+	DockerHosts []string `mapstructure:"readyDockerHosts"`
+	// Timeout is how long each dependency has to respond
+	Timeout time.Duration `mapstructure:"readyTimeout"`
+	// CacheTTL is how long the outcome of the checks is reused for, before they are run again
+	CacheTTL time.Duration `mapstructure:"readyCacheTTL"`
+}
+
+// NewReadiness creates a new Readiness config from the given viper
+func NewReadiness(v *viper.Viper) (out Readiness, err error) {
+	return out, v.Unmarshal(&out)
+}
+
+func setReadinessBindings(v *viper.Viper) error {
+	err := v.BindEnv("readyDockerHosts", "READY_DOCKER_HOSTS")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("readyTimeout", "READY_TIMEOUT")
+	if err != nil {
+		return err
+	}
+	return v.BindEnv("readyCacheTTL", "READY_CACHE_TTL")
+}
+
+func setReadinessDefaults(v *viper.Viper) {
+	v.SetDefault("readyDockerHosts", []string{})
+	v.SetDefault("readyTimeout", 5*time.Second)
+	v.SetDefault("readyCacheTTL", 10*time.Second)
+}
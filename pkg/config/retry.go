@@ -0,0 +1,61 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Retry is the configuration for the delayed redelivery of failed instructions
+type Retry struct {
+	// BaseDelay is the delay before the first redelivery of a failed message
+	BaseDelay time.Duration `mapstructure:"retryBaseDelay"`
+	// MaxDelay caps the exponential growth of the delay
+	MaxDelay time.Duration `mapstructure:"retryMaxDelay"`
+	// Jitter is the fraction of each delay which is randomized, between 0 and 1
+	Jitter float64 `mapstructure:"retryJitter"`
+}
+
+// NewRetry creates a new Retry config from the given viper
+func NewRetry(v *viper.Viper) (out Retry, err error) {
+	return out, v.Unmarshal(&out)
+}
+
+// Delays gets the delay for each level of backoff, doubling from BaseDelay
+// until it reaches MaxDelay. Each delay is given its own TTL queue.
+func (r Retry) Delays() []time.Duration {
+	if r.BaseDelay <= 0 {
+		return nil
+	}
+	out := []time.Duration{}
+	for delay := r.BaseDelay; ; delay *= 2 {
+		if delay >= r.MaxDelay {
+			return append(out, r.MaxDelay)
+		}
+		out = append(out, delay)
+	}
+}
+
+func setRetryBindings(v *viper.Viper) error {
+	err := v.BindEnv("retryBaseDelay", "RETRY_BASE_DELAY")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("retryMaxDelay", "RETRY_MAX_DELAY")
+	if err != nil {
+		return err
+	}
+	return v.BindEnv("retryJitter", "RETRY_JITTER")
+}
+
+func setRetryDefaults(v *viper.Viper) {
+	v.SetDefault("retryBaseDelay", "2s")
+	v.SetDefault("retryMaxDelay", "2m")
+	v.SetDefault("retryJitter", 0.5)
+}
@@ -8,8 +8,13 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"regexp"
+	"strings"
+
+	"github.com/whiteblock/genesis/pkg/entity"
 )
 
 func assertNotEmpty(s string, errMsg string) {
@@ -24,18 +29,144 @@ func SanityCheck(conf Config) {
 
 	dockerSanityCheck(conf.Docker)
 	log.Info("docker configuration checks passed")
+
+	if conf.Execution.BreakerThreshold < 1 {
+		panic("execution breaker threshold must be at least 1")
+	}
+	if conf.Execution.BreakerCooldown <= 0 {
+		panic("execution breaker cooldown must be positive")
+	}
+
+	retrySanityCheck(conf.Retry)
+	log.Info("retry configuration checks passed")
+
+	busSanityCheck(conf.Bus)
+	log.Info("message bus configuration checks passed")
+
+	schedulerSanityCheck(conf.Scheduler)
+	log.Info("scheduler configuration checks passed")
+
+	if conf.State.LedgerRetention <= 0 {
+		panic("ledger retention must be positive")
+	}
+	if conf.State.JobRetention <= 0 {
+		panic("job retention must be positive")
+	}
+
+	restSanityCheck(conf.GetRestConfig())
+	log.Info("rest configuration checks passed")
+
+	if conf.Auth.HMACMaxSkew <= 0 {
+		panic("hmac max skew must be positive")
+	}
+	if conf.Auth.JWKSFile != "" {
+		assertNotEmpty(conf.Auth.JWTOrgClaim, "missing jwt org claim")
+	}
+	if !conf.Auth.Enabled() {
+		log.Warn("no REST authentication is configured, so anyone who can reach the " +
+			"REST api can execute commands")
+	}
+
+	tracingSanityCheck(conf.Tracing)
+	log.Info("tracing configuration checks passed")
+}
+
+func tracingSanityCheck(conf Tracing) {
+	switch conf.Exporter {
+	case "":
+		return
+	case OTLPTracing:
+		assertNotEmpty(conf.OTLPEndpoint, "missing tracing otlp endpoint")
+	case FileTracing:
+		assertNotEmpty(conf.File, "missing tracing file")
+	default:
+		panic(fmt.Sprintf(`unknown tracing exporter "%s"`, conf.Exporter))
+	}
+	if conf.SampleRatio < 0 || conf.SampleRatio > 1 {
+		panic("tracing sample ratio must be between 0 and 1")
+	}
+}
+
+func restSanityCheck(conf entity.RestConfig) {
+	if _, ok := entity.TLSVersions[conf.MinTLSVersion]; !ok {
+		panic(fmt.Sprintf(`unknown minimum tls version "%s"`, conf.MinTLSVersion))
+	}
+	if !conf.TLSEnabled() {
+		if conf.KeyPath != "" || conf.ClientCAPath != "" {
+			panic("the rest key and client ca require a rest certificate")
+		}
+		return
+	}
+	assertNotEmpty(conf.KeyPath, "missing rest key path")
+	for _, path := range []string{conf.CertPath, conf.KeyPath, conf.ClientCAPath} {
+		if path == "" {
+			continue
+		}
+		_, err := os.Lstat(path)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func schedulerSanityCheck(conf Scheduler) {
+	if conf.MaxConcurrency < 1 {
+		panic("queue max concurrency must be at least 1")
+	}
+	if conf.OrgMaxConcurrency < 0 {
+		panic("org max concurrency cannot be negative")
+	}
+	if conf.DefaultOrgWeight < 1 {
+		panic("default org weight must be at least 1")
+	}
+	for org, weight := range conf.OrgWeights {
+		if weight < 1 {
+			panic(fmt.Sprintf(`weight of org "%s" must be at least 1`, org))
+		}
+	}
+}
+
+func busSanityCheck(conf Bus) {
+	switch conf.Backend {
+	case AMQPBus, MemoryBus:
+	case NATSBus:
+		assertNotEmpty(conf.NATSURL, "missing nats url")
+		if conf.NATSAckWait <= 0 {
+			panic("nats ack wait must be positive")
+		}
+	default:
+		panic(fmt.Sprintf(`unknown message bus "%s"`, conf.Backend))
+	}
+	if conf.MemoryQueueLimit < 0 {
+		panic("memory queue limit cannot be negative")
+	}
+}
+
+func retrySanityCheck(conf Retry) {
+	if conf.BaseDelay <= 0 {
+		panic("retry base delay must be positive")
+	}
+	if conf.MaxDelay < conf.BaseDelay {
+		panic("retry max delay cannot be less than the base delay")
+	}
+	if conf.Jitter < 0 || conf.Jitter > 1 {
+		panic(fmt.Sprintf("retry jitter must be between 0 and 1, got %f", conf.Jitter))
+	}
 }
 
 var portRegexp = regexp.MustCompile(`[0-9]+`)
 
 func dockerSanityCheck(conf Docker) {
 	if !conf.LocalMode {
-		dockerFilesConfCheck(conf)
+		dockerHostsConfCheck(conf)
 	}
 	if conf.SwarmPort == 0 {
 		panic("invalid docker swarm port given")
 	}
 	assertNotEmpty(conf.DaemonPort, "invalid docker daemon port given")
+	if conf.ClientIdleTimeout < 0 || conf.ClientPingInterval < 0 {
+		panic("docker client idle timeout and ping interval cannot be negative")
+	}
 	assertNotEmpty(conf.GlusterImage, "missing gluster image")
 	assertNotEmpty(conf.GlusterDriver, "missing gluster driver")
 
@@ -44,7 +175,36 @@ func dockerSanityCheck(conf Docker) {
 	}
 }
 
-func dockerFilesConfCheck(conf Docker) {
+func dockerHostsConfCheck(conf Docker) {
+	hosts := []entity.DockerHost{conf.Host("")}
+	for key := range conf.Hosts {
+		if key == AllHosts || net.ParseIP(key) != nil {
+			hosts = append(hosts, conf.Host(key))
+			continue
+		}
+		ip, _, err := net.ParseCIDR(key)
+		if err != nil {
+			panic(fmt.Sprintf(`docker host "%s" is not an ip address or a cidr`, key))
+		}
+		hosts = append(hosts, conf.Host(ip.String()))
+	}
+	for _, host := range hosts {
+		endpoint, err := url.Parse(strings.Replace(host.Endpoint, entity.HostPlaceholder,
+			"127.0.0.1", -1))
+		if err != nil {
+			panic(err)
+		}
+		switch endpoint.Scheme {
+		case "tcp":
+			dockerFilesConfCheck(host)
+		case "ssh", "unix":
+		default:
+			panic(fmt.Sprintf(`unsupported docker endpoint "%s"`, host.Endpoint))
+		}
+	}
+}
+
+func dockerFilesConfCheck(conf entity.DockerHost) {
 	_, err := os.Lstat(conf.CACertPath)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,63 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package config
+
+import (
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// Scheduler is the configuration for the fair scheduling of instructions across organizations
+type Scheduler struct {
+	// MaxConcurrency is the maximum number of instructions executed at once, across all organizations
+	MaxConcurrency int64 `mapstructure:"queueMaxConcurrency"`
+	// OrgMaxConcurrency is the maximum number of instructions executed at once for a single
+	// organization. Zero means only MaxConcurrency applies.
+	OrgMaxConcurrency int64 `mapstructure:"orgMaxConcurrency"`
+	// DefaultOrgWeight is the number of instructions an organization may start in its turn,
+	// unless it is given a weight in OrgWeights
+	DefaultOrgWeight int `mapstructure:"defaultOrgWeight"`
+	// OrgWeights is the weight of each organization with a non-default weight
+	OrgWeights map[string]int `mapstructure:"-"`
+}
+
+// NewScheduler creates a new Scheduler config from the given viper. The org weights may be
+// given either as a map in the config file, or as a JSON object in the environment.
+func NewScheduler(v *viper.Viper) (out Scheduler, err error) {
+	err = v.Unmarshal(&out)
+	if err != nil {
+		return
+	}
+	out.OrgWeights, err = cast.ToStringMapIntE(v.Get("orgWeights"))
+	return
+}
+
+// Weight gets the weight of the given organization
+func (s Scheduler) Weight(org string) int {
+	if weight, ok := s.OrgWeights[org]; ok {
+		return weight
+	}
+	return s.DefaultOrgWeight
+}
+
+func setSchedulerBindings(v *viper.Viper) error {
+	err := v.BindEnv("orgMaxConcurrency", "ORG_MAX_CONCURRENCY")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("defaultOrgWeight", "DEFAULT_ORG_WEIGHT")
+	if err != nil {
+		return err
+	}
+	return v.BindEnv("orgWeights", "ORG_WEIGHTS")
+}
+
+func setSchedulerDefaults(v *viper.Viper) {
+	v.SetDefault("orgMaxConcurrency", 0)
+	v.SetDefault("defaultOrgWeight", 1)
+	v.SetDefault("orgWeights", map[string]int{})
+}
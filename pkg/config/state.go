@@ -0,0 +1,55 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// State is the configuration for the state Genesis keeps on disk
+type State struct {
+	// Path is the location of the state database. If empty, no state is kept.
+	Path string `mapstructure:"statePath"`
+	// LedgerRetention is how long completed commands are remembered for instructions
+	// which never finish
+	LedgerRetention time.Duration `mapstructure:"ledgerRetention"`
+	// JobRetention is how long finished REST jobs are kept
+	JobRetention time.Duration `mapstructure:"jobRetention"`
+	// ResumeJobs causes the REST jobs which were executing when Genesis stopped to be resumed
+	// on startup, rather than marked as interrupted
+	ResumeJobs bool `mapstructure:"resumeJobs"`
+}
+
+// NewState creates a new State config from the given viper
+func NewState(v *viper.Viper) (out State, err error) {
+	return out, v.Unmarshal(&out)
+}
+
+func setStateBindings(v *viper.Viper) error {
+	err := v.BindEnv("statePath", "STATE_PATH")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("ledgerRetention", "LEDGER_RETENTION")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("jobRetention", "JOB_RETENTION")
+	if err != nil {
+		return err
+	}
+	return v.BindEnv("resumeJobs", "RESUME_JOBS")
+}
+
+func setStateDefaults(v *viper.Viper) {
+	v.SetDefault("statePath", "/var/lib/whiteblock/genesis.db")
+	v.SetDefault("ledgerRetention", "168h")
+	v.SetDefault("jobRetention", "168h")
+	v.SetDefault("resumeJobs", true)
+}
@@ -0,0 +1,72 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	// OTLPTracing is the tracing exporter which sends spans to an OpenTelemetry collector
+	// over OTLP/HTTP
+	OTLPTracing = "otlp"
+	// FileTracing is the tracing exporter which writes spans to a local file as JSON
+	FileTracing = "file"
+)
+
+// Tracing is the configuration for the tracing of instructions through Genesis
+type Tracing struct {
+	// Exporter is where spans are sent, either OTLPTracing or FileTracing. If it is empty,
+	// trace context is still propagated, but no spans are recorded.
+	Exporter string `mapstructure:"tracingExporter"`
+	// OTLPEndpoint is the host and port of the collector
+	OTLPEndpoint string `mapstructure:"tracingOTLPEndpoint"`
+	// OTLPInsecure causes spans to be sent to the collector over plain HTTP
+	OTLPInsecure bool `mapstructure:"tracingOTLPInsecure"`
+	// File is the location of the file spans are appended to
+	File string `mapstructure:"tracingFile"`
+	// SampleRatio is the fraction of new traces which are recorded
+	SampleRatio float64 `mapstructure:"tracingSampleRatio"`
+	// ServiceName is the name Genesis is given in its spans
+	ServiceName string `mapstructure:"tracingServiceName"`
+}
+
+// NewTracing creates a new Tracing config from the given viper
+func NewTracing(v *viper.Viper) (out Tracing, err error) {
+	return out, v.Unmarshal(&out)
+}
+
+func setTracingBindings(v *viper.Viper) error {
+	err := v.BindEnv("tracingExporter", "TRACING_EXPORTER")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("tracingOTLPEndpoint", "TRACING_OTLP_ENDPOINT")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("tracingOTLPInsecure", "TRACING_OTLP_INSECURE")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("tracingFile", "TRACING_FILE")
+	if err != nil {
+		return err
+	}
+	err = v.BindEnv("tracingSampleRatio", "TRACING_SAMPLE_RATIO")
+	if err != nil {
+		return err
+	}
+	return v.BindEnv("tracingServiceName", "TRACING_SERVICE_NAME")
+}
+
+func setTracingDefaults(v *viper.Viper) {
+	v.SetDefault("tracingOTLPEndpoint", "localhost:4318")
+	v.SetDefault("tracingFile", "/var/log/whiteblock/genesis-traces.json")
+	v.SetDefault("tracingSampleRatio", 1.0)
+	v.SetDefault("tracingServiceName", "genesis")
+}
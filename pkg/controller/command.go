@@ -7,60 +7,68 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"sync"
 
+	"github.com/whiteblock/genesis/pkg/bus"
+	"github.com/whiteblock/genesis/pkg/entity"
 	"github.com/whiteblock/genesis/pkg/handler"
+	"github.com/whiteblock/genesis/pkg/handler/auxillary"
+	"github.com/whiteblock/genesis/pkg/metrics"
 
 	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
 	queue "github.com/whiteblock/amqp"
-	"golang.org/x/sync/semaphore"
 )
 
-// CommandController is a controller which brings in from an AMQP compatible provider
+// CommandController is a controller which brings in instructions from the message bus
 type CommandController interface {
 	// Start starts the client. This function should be called only once and does not return
 	Start()
 }
 
 type consumer struct {
-	completion queue.AMQPService
-	cmds       queue.AMQPService
-	errors     queue.AMQPService
-	status     queue.AMQPService
-	handle     handler.DeliveryHandler
-	log        logrus.Ext1FieldLogger
-	once       *sync.Once
-	sem        *semaphore.Weighted
+	completion  bus.Queue
+	cmds        bus.Queue
+	errors      bus.Queue
+	status      bus.Queue
+	deadLetters bus.Queue
+	retry       auxillary.Retrier
+	handle      handler.DeliveryHandler
+	log         logrus.Ext1FieldLogger
+	once        *sync.Once
+	sched       auxillary.Scheduler
 }
 
 // NewCommandController creates a new CommandController
 func NewCommandController(
-	maxConcurreny int64,
-	cmds queue.AMQPService,
-	errors queue.AMQPService,
-	completion queue.AMQPService,
-	status queue.AMQPService,
+	sched auxillary.Scheduler,
+	cmds bus.Queue,
+	errors bus.Queue,
+	completion bus.Queue,
+	status bus.Queue,
+	deadLetters bus.Queue,
+	retry auxillary.Retrier,
 
 	handle handler.DeliveryHandler,
 	log logrus.Ext1FieldLogger) (CommandController, error) {
 
-	if maxConcurreny < 1 {
-		return nil, fmt.Errorf("maxConcurreny must be at least 1")
+	if sched == nil {
+		return nil, fmt.Errorf("a scheduler is required")
 	}
 	out := &consumer{
-		log:        log,
-		completion: completion,
-		cmds:       cmds,
-		handle:     handle,
-		errors:     errors,
-		status:     status,
-		once:       &sync.Once{},
-		sem:        semaphore.NewWeighted(maxConcurreny),
+		log:         log,
+		completion:  completion,
+		cmds:        cmds,
+		handle:      handle,
+		errors:      errors,
+		status:      status,
+		deadLetters: deadLetters,
+		retry:       retry,
+		once:        &sync.Once{},
+		sched:       sched,
 	}
-	queue.TryCreateQueues(log, cmds, completion, errors, status)
+	bus.TryCreateQueues(log, cmds, completion, errors, status, deadLetters)
 
 	return out, nil
 }
@@ -78,13 +86,38 @@ func (c *consumer) reportStatus(status amqp.Publishing) {
 	}
 }
 
-func (c *consumer) handleMessage(msg amqp.Delivery) {
-	defer c.sem.Release(1)
+func (c *consumer) deadLetter(msg amqp.Delivery, res entity.Result) {
+	letter, err := entity.NewDeadLetter(msg.Body, msg.Headers, res)
+	if err != nil {
+		c.log.WithField("err", err).Error("failed to create a dead letter")
+		return
+	}
+	pub, err := queue.CreateMessage(letter)
+	if err != nil {
+		c.log.WithField("err", err).Error("failed to create a dead letter")
+		return
+	}
+	err = c.deadLetters.Send(pub)
+	if err != nil {
+		c.log.WithFields(logrus.Fields{
+			"err":     err,
+			"payload": string(msg.Body)}).Error("failed to send to the dead letter queue")
+		return
+	}
+	metrics.QueueMessages.WithLabelValues(metrics.QueueDeadLettered).Inc()
+	c.log.WithField("id", letter.ID).Info("sent the instructions to the dead letter queue")
+}
+
+func (c *consumer) handleMessage(msg amqp.Delivery, done func()) {
+	metrics.InFlight.WithLabelValues(metrics.ConsumerComponent).Inc()
+	defer metrics.InFlight.WithLabelValues(metrics.ConsumerComponent).Dec()
+	defer done()
 
 	pub, status, res := c.handle.Process(msg)
 	go c.reportStatus(status)
 	if res.IsIgnore() {
 		c.log.WithField("payload", string(msg.Body)).Error("ignoring a message")
+		metrics.QueueMessages.WithLabelValues(metrics.QueueIgnored).Inc()
 		msg.Ack(false)
 		return
 	}
@@ -94,19 +127,32 @@ func (c *consumer) handleMessage(msg amqp.Delivery) {
 		return
 	}
 	if res.IsRequeue() {
-		c.log.WithField("result", res).Info("a requeue is needed")
+		if !res.IsSuccess() {
+			c.log.WithField("result", res).Info("a delayed retry is needed")
+			err := c.retry.Retry(pub)
+			if err == nil {
+				metrics.QueueMessages.WithLabelValues(metrics.QueueRetried).Inc()
+				msg.Ack(false)
+				return
+			}
+			c.log.WithField("err", err).Error("failed to schedule a retry, re-queuing immediately")
+		} else {
+			c.log.WithField("result", res).Info("a requeue is needed")
+		}
 		err := c.cmds.Requeue(msg, pub)
 		if err != nil {
 			c.log.WithField("err", err).Error("failed to re-queue")
+			return
 		}
+		metrics.QueueMessages.WithLabelValues(metrics.QueueRequeued).Inc()
 		return
 	}
 	if res.IsAllDone() || res.IsFatal() {
 		if res.IsFatal() {
-			msg, err := queue.CreateMessage(res)
+			errMsg, err := queue.CreateMessage(res)
 			if err == nil {
 				go func() {
-					err := c.errors.Send(msg)
+					err := c.errors.Send(errMsg)
 					if err != nil {
 						c.log.WithField("err", err).WithField("res",
 							res).Error("an error occured while reporting an error")
@@ -115,6 +161,7 @@ func (c *consumer) handleMessage(msg amqp.Delivery) {
 			} else {
 				c.log.WithField("err", err).WithField("res", res).Error("an error occured while reporting an error")
 			}
+			c.deadLetter(msg, res)
 		}
 		c.log.Info("sending the all done signal")
 		err := c.completion.Send(pub)
@@ -122,6 +169,7 @@ func (c *consumer) handleMessage(msg amqp.Delivery) {
 			c.log.WithField("err", err).Error("failed to send to the completion queue")
 			return
 		}
+		metrics.QueueMessages.WithLabelValues(metrics.QueueCompleted).Inc()
 	}
 	c.log.Info("successfully completed a message")
 
@@ -133,9 +181,15 @@ func (c *consumer) loop() {
 	if err != nil {
 		c.log.Fatal(err)
 	}
-	for msg := range msgs {
-		c.log.Info("received a message")
-		c.sem.Acquire(context.Background(), 1)
-		go c.handleMessage(msg)
+	go func() {
+		for msg := range msgs {
+			c.log.Info("received a message")
+			metrics.QueueMessages.WithLabelValues(metrics.QueueConsumed).Inc()
+			c.sched.Submit(msg)
+		}
+	}()
+	for {
+		msg, done := c.sched.Next()
+		go c.handleMessage(msg, done)
 	}
 }
@@ -7,22 +7,40 @@
 package controller
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	queue "github.com/whiteblock/genesis/mocks/amqp"
 	handler "github.com/whiteblock/genesis/mocks/pkg/handler"
+	auxMocks "github.com/whiteblock/genesis/mocks/pkg/handler/auxillary"
+	"github.com/whiteblock/genesis/pkg/bus"
+	"github.com/whiteblock/genesis/pkg/config"
 	"github.com/whiteblock/genesis/pkg/entity"
+	handlers "github.com/whiteblock/genesis/pkg/handler"
+	"github.com/whiteblock/genesis/pkg/handler/auxillary"
 
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+func testScheduler(t *testing.T) auxillary.Scheduler {
+	sched, err := auxillary.NewScheduler(config.Scheduler{MaxConcurrency: 2, DefaultOrgWeight: 1},
+		logrus.New())
+	require.NoError(t, err)
+	return sched
+}
+
 func TestNewCommandController_Failure(t *testing.T) {
-	ctl, err := NewCommandController(0, nil, nil, nil, nil, nil, logrus.New())
+	ctl, err := NewCommandController(nil, nil, nil, nil, nil, nil, nil, nil, logrus.New())
 	assert.Nil(t, ctl)
 	assert.Error(t, err)
 }
@@ -36,8 +54,10 @@ func TestNewCommandController_Ignore_CreateQueueFailure(t *testing.T) {
 	serv2.On("CreateQueue").Return(fmt.Errorf("err")).Once()
 	serv3.On("CreateQueue").Return(fmt.Errorf("err")).Once()
 	serv4.On("CreateQueue").Return(fmt.Errorf("err")).Once()
+	serv5 := new(queue.AMQPService)
+	serv5.On("CreateQueue").Return(fmt.Errorf("err")).Once()
 
-	control, err := NewCommandController(2, serv, serv3, serv2, serv4, nil, logrus.New())
+	control, err := NewCommandController(testScheduler(t), serv, serv3, serv2, serv4, serv5, nil, nil, logrus.New())
 	assert.NotNil(t, control)
 	assert.NoError(t, err)
 
@@ -60,13 +80,15 @@ func TestCommandController_Consumption(t *testing.T) {
 	serv2.On("CreateQueue").Return(nil).Once()
 	serv3.On("CreateQueue").Return(nil).Once()
 	serv4.On("CreateQueue").Return(nil).Once()
+	serv5 := new(queue.AMQPService)
+	serv5.On("CreateQueue").Return(nil).Once()
 	serv4.On("Send", mock.Anything).Return(nil)
 	hand := new(handler.DeliveryHandler)
 	hand.On("Process", mock.Anything).Run(func(_ mock.Arguments) {
 		processedChan <- true
 	}).Return(amqp.Publishing{}, amqp.Publishing{}, entity.NewSuccessResult()).Times(items)
 
-	control, err := NewCommandController(2, serv, serv3, serv2, serv4, hand, logrus.New())
+	control, err := NewCommandController(testScheduler(t), serv, serv3, serv2, serv4, serv5, nil, hand, logrus.New())
 	assert.Equal(t, err, nil)
 	go control.Start()
 
@@ -101,6 +123,8 @@ func TestCommandController_ConsumptionAllDone(t *testing.T) {
 	serv2.On("CreateQueue").Return(nil).Once()
 	serv3.On("CreateQueue").Return(nil).Once()
 	serv4.On("CreateQueue").Return(nil).Once()
+	serv5 := new(queue.AMQPService)
+	serv5.On("CreateQueue").Return(nil).Once()
 	serv4.On("Send", mock.Anything).Return(nil)
 	serv3.On("Send", mock.Anything).Return(nil)
 	serv2.On("Send", mock.Anything).Return(nil).Times(items).Run(func(_ mock.Arguments) {
@@ -111,7 +135,7 @@ func TestCommandController_ConsumptionAllDone(t *testing.T) {
 	hand.On("Process", mock.Anything).Return(amqp.Publishing{}, amqp.Publishing{},
 		entity.NewAllDoneResult()).Times(items)
 
-	control, err := NewCommandController(2, serv, serv3, serv2, serv4, hand, logrus.New())
+	control, err := NewCommandController(testScheduler(t), serv, serv3, serv2, serv4, serv5, nil, hand, logrus.New())
 	assert.Equal(t, err, nil)
 	go control.Start()
 
@@ -150,12 +174,14 @@ func TestCommandController_ConsumptionAllDone_Send_Err(t *testing.T) {
 	serv4.On("Send", mock.Anything).Return(nil)
 	serv3.On("CreateQueue").Return(nil).Once()
 	serv4.On("CreateQueue").Return(nil).Once()
+	serv5 := new(queue.AMQPService)
+	serv5.On("CreateQueue").Return(nil).Once()
 
 	hand := new(handler.DeliveryHandler)
 	hand.On("Process", mock.Anything).Return(amqp.Publishing{}, amqp.Publishing{},
 		entity.NewAllDoneResult()).Times(items)
 
-	control, err := NewCommandController(2, serv, serv3, serv2, serv4, hand, logrus.New())
+	control, err := NewCommandController(testScheduler(t), serv, serv3, serv2, serv4, serv5, nil, hand, logrus.New())
 	assert.Equal(t, err, nil)
 	go control.Start()
 
@@ -193,13 +219,62 @@ func TestCommandController_Requeue(t *testing.T) {
 	serv2.On("CreateQueue").Return(nil).Once()
 	serv3.On("CreateQueue").Return(nil).Once()
 	serv4.On("CreateQueue").Return(nil).Once()
+	serv5 := new(queue.AMQPService)
+	serv5.On("CreateQueue").Return(nil).Once()
+	serv4.On("Send", mock.Anything).Return(nil)
+
+	hand := new(handler.DeliveryHandler)
+	hand.On("Process", mock.Anything).Return(amqp.Publishing{}, amqp.Publishing{},
+		entity.NewRequeueResult()).Times(items)
+
+	control, err := NewCommandController(testScheduler(t), serv, serv3, serv2, serv4, serv5, nil, hand, logrus.New())
+	assert.Equal(t, err, nil)
+	go control.Start()
+
+	for i := 0; i < items; i++ {
+		deliveryChan <- amqp.Delivery{}
+	}
+	for i := 0; i < items; i++ {
+		select {
+		case <-processedChan:
+		case <-time.After(5 * time.Second):
+			t.Fatal("messages were not consumed within 5 seconds")
+		}
+	}
+
+	close(deliveryChan)
+	hand.AssertExpectations(t)
+	serv.AssertExpectations(t)
+}
+
+func TestCommandController_Retry(t *testing.T) {
+	items := 10
+
+	processedChan := make(chan bool, items)
+	deliveryChan := make(chan amqp.Delivery, items)
+	serv := new(queue.AMQPService)
+	serv2 := new(queue.AMQPService)
+	serv3 := new(queue.AMQPService)
+	serv4 := new(queue.AMQPService)
+	serv.On("Consume").Return((<-chan amqp.Delivery)(deliveryChan), nil).Once()
+	serv.On("CreateQueue").Return(nil).Once()
+	serv2.On("CreateQueue").Return(nil).Once()
+	serv3.On("CreateQueue").Return(nil).Once()
+	serv4.On("CreateQueue").Return(nil).Once()
+	serv5 := new(queue.AMQPService)
+	serv5.On("CreateQueue").Return(nil).Once()
 	serv4.On("Send", mock.Anything).Return(nil)
 
+	retry := new(auxMocks.Retrier)
+	retry.On("Retry", mock.Anything).Run(func(_ mock.Arguments) {
+		processedChan <- true
+	}).Return(nil).Times(items)
+
 	hand := new(handler.DeliveryHandler)
 	hand.On("Process", mock.Anything).Return(amqp.Publishing{}, amqp.Publishing{},
 		entity.NewErrorResult(fmt.Errorf("some non-fatal error"))).Times(items)
 
-	control, err := NewCommandController(2, serv, serv3, serv2, serv4, hand, logrus.New())
+	control, err := NewCommandController(testScheduler(t), serv, serv3, serv2, serv4, serv5, retry, hand, logrus.New())
 	assert.Equal(t, err, nil)
 	go control.Start()
 
@@ -216,5 +291,151 @@ func TestCommandController_Requeue(t *testing.T) {
 
 	close(deliveryChan)
 	hand.AssertExpectations(t)
+	retry.AssertExpectations(t)
+	serv.AssertNotCalled(t, "Requeue", mock.Anything, mock.Anything)
+}
+
+func TestCommandController_Retry_Failure(t *testing.T) {
+	processedChan := make(chan bool, 1)
+	deliveryChan := make(chan amqp.Delivery, 1)
+	serv := new(queue.AMQPService)
+	serv2 := new(queue.AMQPService)
+	serv3 := new(queue.AMQPService)
+	serv4 := new(queue.AMQPService)
+	serv.On("Consume").Return((<-chan amqp.Delivery)(deliveryChan), nil).Once()
+	serv.On("CreateQueue").Return(nil).Once()
+	serv.On("Requeue", mock.Anything, mock.Anything).Run(func(_ mock.Arguments) {
+		processedChan <- true
+	}).Return(nil).Once()
+	serv2.On("CreateQueue").Return(nil).Once()
+	serv3.On("CreateQueue").Return(nil).Once()
+	serv4.On("CreateQueue").Return(nil).Once()
+	serv5 := new(queue.AMQPService)
+	serv5.On("CreateQueue").Return(nil).Once()
+	serv4.On("Send", mock.Anything).Return(nil)
+
+	retry := new(auxMocks.Retrier)
+	retry.On("Retry", mock.Anything).Return(fmt.Errorf("err")).Once()
+
+	hand := new(handler.DeliveryHandler)
+	hand.On("Process", mock.Anything).Return(amqp.Publishing{}, amqp.Publishing{},
+		entity.NewErrorResult(fmt.Errorf("some non-fatal error"))).Once()
+
+	control, err := NewCommandController(testScheduler(t), serv, serv3, serv2, serv4, serv5, retry, hand, logrus.New())
+	assert.Equal(t, err, nil)
+	go control.Start()
+
+	deliveryChan <- amqp.Delivery{}
+	select {
+	case <-processedChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not re-queued within 5 seconds")
+	}
+
+	close(deliveryChan)
+	hand.AssertExpectations(t)
+	retry.AssertExpectations(t)
 	serv.AssertExpectations(t)
 }
+
+func TestCommandController_DeadLetter(t *testing.T) {
+	processedChan := make(chan bool, 1)
+	deliveryChan := make(chan amqp.Delivery, 1)
+	serv := new(queue.AMQPService)
+	serv2 := new(queue.AMQPService)
+	serv3 := new(queue.AMQPService)
+	serv4 := new(queue.AMQPService)
+	serv.On("Consume").Return((<-chan amqp.Delivery)(deliveryChan), nil).Once()
+	serv.On("CreateQueue").Return(nil).Once()
+	serv2.On("CreateQueue").Return(nil).Once()
+	serv2.On("Send", mock.Anything).Return(nil).Once()
+	serv3.On("CreateQueue").Return(nil).Once()
+	serv3.On("Send", mock.Anything).Return(nil).Once()
+	serv4.On("CreateQueue").Return(nil).Once()
+	serv4.On("Send", mock.Anything).Return(nil)
+	serv5 := new(queue.AMQPService)
+	serv5.On("CreateQueue").Return(nil).Once()
+	serv5.On("Send", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		pub, ok := args.Get(0).(amqp.Publishing)
+		require.True(t, ok)
+
+		var letter entity.DeadLetter
+		require.NoError(t, json.Unmarshal(pub.Body, &letter))
+		assert.Equal(t, "test", letter.TestID)
+		assert.NotEmpty(t, letter.ID)
+		assert.NotEmpty(t, letter.Result)
+		processedChan <- true
+	}).Once()
+
+	hand := new(handler.DeliveryHandler)
+	hand.On("Process", mock.Anything).Return(amqp.Publishing{}, amqp.Publishing{},
+		entity.NewFatalResult("some fatal error")).Once()
+
+	control, err := NewCommandController(testScheduler(t), serv, serv3, serv2, serv4, serv5, nil, hand, logrus.New())
+	assert.Equal(t, err, nil)
+	go control.Start()
+
+	deliveryChan <- amqp.Delivery{Body: []byte(`{"id":"test"}`)}
+	select {
+	case <-processedChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not dead-lettered within 5 seconds")
+	}
+
+	close(deliveryChan)
+	hand.AssertExpectations(t)
+	serv5.AssertExpectations(t)
+}
+
+func TestCommandController_MemoryBus(t *testing.T) {
+	broker := bus.NewMemoryBroker(10, logrus.New())
+	rest := handlers.NewBusHandler(broker, "commands",
+		[]string{"errors", "completion", "status", "deadLetters"}, logrus.New())
+
+	hand := new(handler.DeliveryHandler)
+	hand.On("Process", mock.Anything).Return(
+		amqp.Publishing{Body: []byte(`{"id":"test"}`)},
+		amqp.Publishing{Body: []byte(`{"status":"finished"}`)},
+		entity.NewAllDoneResult()).Once()
+
+	control, err := NewCommandController(testScheduler(t), broker.Queue("commands"),
+		broker.Queue("errors"), broker.Queue("completion"), broker.Queue("status"),
+		broker.Queue("deadLetters"), nil, hand, logrus.New())
+	require.NoError(t, err)
+	go control.Start()
+
+	req := httptest.NewRequest("POST", "/bus/commands", strings.NewReader(`{"id":"test"}`))
+	req = mux.SetURLVars(req, map[string]string{"queue": "commands"})
+	rr := httptest.NewRecorder()
+	rest.Publish(rr, req)
+	require.Equal(t, http.StatusAccepted, rr.Code)
+
+	receive := func(name string) []json.RawMessage {
+		req := httptest.NewRequest("GET", "/bus/"+name, nil)
+		req = mux.SetURLVars(req, map[string]string{"queue": name})
+		rr := httptest.NewRecorder()
+		rest.Receive(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out []json.RawMessage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		return out
+	}
+
+	var completion []json.RawMessage
+	for i := 0; i < 50 && len(completion) == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+		completion = receive("completion")
+	}
+	require.Len(t, completion, 1)
+	assert.JSONEq(t, `{"id":"test"}`, string(completion[0]))
+
+	var status []json.RawMessage
+	for i := 0; i < 50 && len(status) == 0; i++ {
+		status = receive("status")
+		time.Sleep(20 * time.Millisecond)
+	}
+	require.Len(t, status, 1)
+	assert.JSONEq(t, `{"status":"finished"}`, string(status[0]))
+	assert.Empty(t, receive("errors"))
+	hand.AssertExpectations(t)
+}
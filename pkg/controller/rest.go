@@ -13,7 +13,10 @@ import (
 	"github.com/whiteblock/genesis/pkg/entity"
 	"github.com/whiteblock/genesis/pkg/handler"
 	"github.com/whiteblock/genesis/pkg/helper"
+	"github.com/whiteblock/genesis/pkg/service"
+	"github.com/whiteblock/genesis/pkg/tracing"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,21 +27,44 @@ type RestController interface {
 }
 
 type restController struct {
-	conf entity.RestConfig
-	hand handler.RestHandler
-	mux  helper.Router
-	log  logrus.Ext1FieldLogger
+	conf        entity.RestConfig
+	hand        handler.RestHandler
+	validation  handler.ValidationHandler
+	plan        handler.PlanHandler
+	deadLetters handler.DeadLetterHandler
+	sched       handler.SchedulerHandler
+	hosts       handler.HostHandler
+	ready       handler.ReadinessHandler
+	diagnostics handler.DiagnosticsHandler
+	bus         handler.BusHandler
+	auth        handler.AuthHandler
+	mux         helper.Router
+	log         logrus.Ext1FieldLogger
 }
 
-//NewRestController creates a new rest controller
+//NewRestController creates a new rest controller. The validation, plan, dead letter, queue, host,
+//readiness, diagnostics and bus routes are only served if validation, plan, deadLetters, sched,
+//hosts, ready, diagnostics and bus are not nil. If auth is not nil, every request must pass
+//through it.
 func NewRestController(
 	conf entity.RestConfig,
 	hand handler.RestHandler,
+	validation handler.ValidationHandler,
+	plan handler.PlanHandler,
+	deadLetters handler.DeadLetterHandler,
+	sched handler.SchedulerHandler,
+	hosts handler.HostHandler,
+	ready handler.ReadinessHandler,
+	diagnostics handler.DiagnosticsHandler,
+	bus handler.BusHandler,
+	auth handler.AuthHandler,
 	mux helper.Router,
 	log logrus.Ext1FieldLogger) RestController {
 
 	log.Trace("creating a new rest controller")
-	return &restController{conf: conf, hand: hand, mux: mux, log: log}
+	return &restController{conf: conf, hand: hand, validation: validation, plan: plan,
+		deadLetters: deadLetters, sched: sched, hosts: hosts, ready: ready, diagnostics: diagnostics,
+		bus: bus, auth: auth, mux: mux, log: log}
 }
 
 // Start starts the rest server, blocking the calling thread from returning
@@ -46,9 +72,65 @@ func (rc restController) Start() {
 
 	rc.mux.HandleFunc("/command", rc.hand.AddCommands).Methods("POST")
 	rc.mux.HandleFunc("/health", rc.hand.HealthCheck).Methods("GET")
+	rc.mux.HandleFunc("/jobs/{id}", rc.hand.GetJob).Methods("GET")
+	rc.mux.HandleFunc("/metrics", promhttp.Handler().ServeHTTP).Methods("GET")
 
-	rc.log.WithFields(logrus.Fields{"socket": rc.conf.Listen}).Info("listening for requests")
-	rc.log.Fatal(http.ListenAndServe(rc.conf.Listen, removeTrailingSlash(rc.mux)))
+	if rc.validation != nil {
+		rc.mux.HandleFunc("/command/validate", rc.validation.Validate).Methods("POST")
+	}
+
+	if rc.plan != nil {
+		rc.mux.HandleFunc("/command/plan", rc.plan.Plan).Methods("POST")
+	}
+
+	if rc.deadLetters != nil {
+		rc.mux.HandleFunc("/deadletter", rc.deadLetters.List).Methods("GET")
+		rc.mux.HandleFunc("/deadletter/{id}", rc.deadLetters.Get).Methods("GET")
+		rc.mux.HandleFunc("/deadletter/{id}/replay", rc.deadLetters.Replay).Methods("POST")
+	}
+
+	if rc.sched != nil {
+		rc.mux.HandleFunc("/queue", rc.sched.Load).Methods("GET")
+	}
+
+	if rc.hosts != nil {
+		rc.mux.HandleFunc("/hosts", rc.hosts.List).Methods("GET")
+		rc.mux.HandleFunc("/hosts/{host}", rc.hosts.Get).Methods("GET")
+	}
+
+	if rc.ready != nil {
+		rc.mux.HandleFunc("/ready", rc.ready.Ready).Methods("GET")
+	}
+
+	if rc.diagnostics != nil {
+		rc.mux.HandleFunc("/diagnose/{host}", rc.diagnostics.Diagnose).Methods("POST")
+	}
+
+	if rc.bus != nil {
+		rc.mux.HandleFunc("/bus/{queue}", rc.bus.Publish).Methods("POST")
+		rc.mux.HandleFunc("/bus/{queue}", rc.bus.Receive).Methods("GET")
+	}
+
+	var hand http.Handler = removeTrailingSlash(rc.mux)
+	if rc.auth != nil {
+		hand = rc.auth.Middleware(hand)
+	}
+	hand = tracing.Middleware(hand)
+	server := &http.Server{Addr: rc.conf.Listen, Handler: hand}
+	if !rc.conf.TLSEnabled() {
+		rc.log.WithFields(logrus.Fields{"socket": rc.conf.Listen}).Info("listening for requests")
+		rc.log.Fatal(server.ListenAndServe())
+	}
+
+	certs, err := service.NewCertReloader(rc.conf, rc.log)
+	if err != nil {
+		rc.log.Fatal(err)
+	}
+	server.TLSConfig = certs.TLSConfig()
+	rc.log.WithFields(logrus.Fields{
+		"socket": rc.conf.Listen,
+		"mtls":   rc.conf.ClientCAPath != ""}).Info("listening for requests over tls")
+	rc.log.Fatal(server.ListenAndServeTLS("", ""))
 }
 
 func removeTrailingSlash(next http.Handler) http.Handler {
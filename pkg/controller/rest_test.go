@@ -16,5 +16,5 @@ import (
 )
 
 func TestRestController(t *testing.T) {
-	assert.NotNil(t, NewRestController(entity.RestConfig{}, nil, nil, logrus.New()))
+	assert.NotNil(t, NewRestController(entity.RestConfig{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logrus.New()))
 }
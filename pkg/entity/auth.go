@@ -0,0 +1,41 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+// AllOrgs is the org id which grants access to every org
+const AllOrgs = "*"
+
+// Credential describes a caller of the REST api and what it may act on
+type Credential struct {
+	// Subject identifies the caller
+	Subject string `json:"subject"`
+	// Orgs are the org ids the caller may act on. AllOrgs grants access to every org, as well
+	// as to the instructions without an org and to the routes which are not scoped to an org.
+	Orgs []string `json:"orgs"`
+	// Secret is the shared secret of a HMAC key
+	Secret string `json:"secret,omitempty"`
+}
+
+// Unrestricted returns true if the caller may act on every org
+func (c Credential) Unrestricted() bool {
+	for _, org := range c.Orgs {
+		if org == AllOrgs {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOrg returns true if the caller may act on the given org
+func (c Credential) AllowsOrg(org string) bool {
+	for _, allowed := range c.Orgs {
+		if allowed == AllOrgs || (org != "" && allowed == org) {
+			return true
+		}
+	}
+	return false
+}
@@ -23,6 +23,9 @@ import (
 type Client interface {
 	// Close the transport used by the client
 	Close() error
+	// ClientVersion returns the API version used by this client.
+	ClientVersion() string
+
 	// ContainerAttach attaches a connection to a container in the server. It returns a types.HijackedConnection with
 	// the hijacked connection and the a reader to get output. It's up to the called to close
 	// the hijacked connection by calling types.HijackedResponse.Close.
@@ -68,6 +71,9 @@ type Client interface {
 	// DaemonHost returns the host address used by the client
 	DaemonHost() string
 
+	// DiskUsage requests the current data usage from the daemon
+	DiskUsage(ctx context.Context) (types.DiskUsage, error)
+
 	// HTTPClient returns a copy of the HTTP client bound to the server
 	HTTPClient() *http.Client
 
@@ -80,6 +86,10 @@ type Client interface {
 	//ImagePull is used to pull a docker image
 	ImagePull(ctx context.Context, refStr string, options types.ImagePullOptions) (io.ReadCloser, error)
 
+	// NegotiateAPIVersion queries the API and updates the version to match the
+	// API version. Any errors are silently ignored.
+	NegotiateAPIVersion(ctx context.Context)
+
 	// NetworkCreate sends a request to the docker daemon to create a network
 	NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error)
 
@@ -103,6 +113,9 @@ type Client interface {
 	// falls back to GET if HEAD is not supported by the daemon.
 	Ping(ctx context.Context) (types.Ping, error)
 
+	// ServerVersion returns information of the docker client and server host.
+	ServerVersion(ctx context.Context) (types.Version, error)
+
 	// SwarmInit initializes the swarm.
 	SwarmInit(ctx context.Context, req swarm.InitRequest) (string, error)
 
@@ -0,0 +1,332 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/blkiodev"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
+	units "github.com/docker/go-units"
+	"github.com/whiteblock/definition/command"
+	"github.com/whiteblock/utility/utils"
+)
+
+// Container is the payload of a create container command. It extends the container of the
+// definition with the settings of docker which the definition does not cover.
+type Container struct {
+	command.Container
+
+	// Resources limits what the container may use of its host
+	Resources Resources `json:"resources,omitempty"`
+
+	// Sysctls are the kernel parameters set in the namespace of the container
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+
+	// ShmSize is the size of /dev/shm, in the same units as the memory
+	ShmSize string `json:"shmSize,omitempty"`
+
+	// Tmpfs maps the paths tmpfs filesystems are mounted on to their mount options
+	Tmpfs map[string]string `json:"tmpfs,omitempty"`
+
+	// CapAdd are the kernel capabilities added to the container
+	CapAdd []string `json:"capAdd,omitempty"`
+
+	// CapDrop are the kernel capabilities dropped from the container
+	CapDrop []string `json:"capDrop,omitempty"`
+
+	// Command is the command run by the entrypoint. If it and the entrypoint are both empty,
+	// the args are used as the command instead.
+	Command []string `json:"command,omitempty"`
+
+	// User is the user, and optionally the group, the processes of the container run as
+	User string `json:"user,omitempty"`
+
+	// WorkingDir is the directory the command is run in
+	WorkingDir string `json:"workingDir,omitempty"`
+
+	// StopSignal is the signal sent to the container to stop it, such as "SIGINT"
+	StopSignal string `json:"stopSignal,omitempty"`
+
+	// StopTimeout is how many seconds the container has to stop before it is killed
+	StopTimeout *int `json:"stopTimeout,omitempty"`
+
+	// DNS are the nameservers of the container
+	DNS []string `json:"dns,omitempty"`
+
+	// DNSSearch are the search domains of the container
+	DNSSearch []string `json:"dnsSearch,omitempty"`
+
+	// DNSOptions are the options of the resolver of the container
+	DNSOptions []string `json:"dnsOptions,omitempty"`
+
+	// ExtraHosts are added to /etc/hosts in the container, in the form "host:ip"
+	ExtraHosts []string `json:"extraHosts,omitempty"`
+
+	// RestartPolicy is what docker does when the container exits
+	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+
+	// AutoRemove causes the container to be removed once it exits. It defaults to true, unless
+	// the container has a restart policy, which it cannot be combined with.
+	AutoRemove *bool `json:"autoRemove,omitempty"`
+
+	// Networks are the networks the container is attached to when it is created, along with
+	// the network of the container, which comes first if it is given
+	Networks []NetworkAttachment `json:"networks,omitempty"`
+}
+
+// NetworkAttachment is a network a container is attached to, and its settings on that network
+type NetworkAttachment struct {
+	// Name is the name of the network
+	Name string `json:"name"`
+	// IPv4Address is the static ipv4 address of the container on the network
+	IPv4Address string `json:"ipv4Address,omitempty"`
+	// IPv6Address is the static ipv6 address of the container on the network
+	IPv6Address string `json:"ipv6Address,omitempty"`
+	// Aliases are the names the container can be reached by on the network
+	Aliases []string `json:"aliases,omitempty"`
+	// MacAddress is the mac address of the container on the network
+	MacAddress string `json:"macAddress,omitempty"`
+}
+
+// EndpointSettings gets the settings of the attachment in the format expected by docker
+func (na NetworkAttachment) EndpointSettings() *network.EndpointSettings {
+	out := &network.EndpointSettings{
+		NetworkID:  na.Name,
+		IPAddress:  na.IPv4Address,
+		Aliases:    na.Aliases,
+		MacAddress: na.MacAddress,
+	}
+	if na.IPv4Address != "" || na.IPv6Address != "" {
+		out.IPAMConfig = &network.EndpointIPAMConfig{
+			IPv4Address: na.IPv4Address,
+			IPv6Address: na.IPv6Address,
+		}
+	}
+	return out
+}
+
+// RestartPolicy is what docker does when a container exits
+type RestartPolicy struct {
+	// Name is one of "no", "always", "unless-stopped" or "on-failure"
+	Name string `json:"name"`
+	// MaximumRetryCount is how many times an "on-failure" container is restarted
+	MaximumRetryCount int `json:"maximumRetryCount,omitempty"`
+}
+
+// IsNone returns true if the policy never restarts the container
+func (rp RestartPolicy) IsNone() bool {
+	return rp.Name == "" || rp.Name == "no"
+}
+
+// Resources are the limits on the resources of the host a container may use, beyond its cpus
+// and memory. Sizes take the same units as the memory of the container, and are unset if empty.
+type Resources struct {
+	// CPUSetCPUs are the cpus the container may run on, such as "0-3,6". The bound cpus of the
+	// container are used if this is empty.
+	CPUSetCPUs string `json:"cpusetCpus,omitempty"`
+
+	// CPUSetMems are the memory nodes the container may use, such as "0,1"
+	CPUSetMems string `json:"cpusetMems,omitempty"`
+
+	// CPUShares is the weight of the container against others competing for cpu time
+	CPUShares int64 `json:"cpuShares,omitempty"`
+
+	// CPUPeriod is the length of a cfs period, in microseconds
+	CPUPeriod int64 `json:"cpuPeriod,omitempty"`
+
+	// CPUQuota is the cpu time the container may use in each cfs period, in microseconds
+	CPUQuota int64 `json:"cpuQuota,omitempty"`
+
+	// MemorySwap is the total of the memory and swap the container may use, or -1 for unlimited
+	MemorySwap string `json:"memorySwap,omitempty"`
+
+	// MemoryReservation is the soft limit on the memory of the container
+	MemoryReservation string `json:"memoryReservation,omitempty"`
+
+	// PidsLimit is the maximum number of processes in the container, or -1 for unlimited
+	PidsLimit int64 `json:"pidsLimit,omitempty"`
+
+	// Ulimits are the resource limits of the processes in the container
+	Ulimits []Ulimit `json:"ulimits,omitempty"`
+
+	// BlkioWeight is the block io weight of the container, between 10 and 1000
+	BlkioWeight uint16 `json:"blkioWeight,omitempty"`
+
+	// DeviceReadBps limits the rate the container may read from devices
+	DeviceReadBps []DeviceRate `json:"deviceReadBps,omitempty"`
+
+	// DeviceWriteBps limits the rate the container may write to devices
+	DeviceWriteBps []DeviceRate `json:"deviceWriteBps,omitempty"`
+}
+
+// Ulimit is a resource limit of the processes in a container
+type Ulimit struct {
+	// Name is the resource limited, such as "nofile"
+	Name string `json:"name"`
+	// Soft is the soft limit
+	Soft int64 `json:"soft"`
+	// Hard is the hard limit
+	Hard int64 `json:"hard"`
+}
+
+// DeviceRate is the rate per second a container may transfer to or from a device
+type DeviceRate struct {
+	// Path is the path of the device, such as "/dev/sda"
+	Path string `json:"path"`
+	// Rate is the number of bytes per second, in the same units as the memory of a container
+	Rate string `json:"rate"`
+}
+
+// ParseSize converts a size in the units of the memory of a container into bytes. An empty
+// size is zero, and -1 stays as it is, meaning unlimited.
+func ParseSize(size string) (int64, error) {
+	switch strings.TrimSpace(size) {
+	case "":
+		return 0, nil
+	case "-1":
+		return -1, nil
+	}
+	return utils.Memconv(size, utils.Mibi)
+}
+
+// GetNetworks gets every network the container is attached to when it is created, starting
+// with its network if it has one
+func (c Container) GetNetworks() []NetworkAttachment {
+	out := []NetworkAttachment{}
+	if c.Network != "" {
+		primary := NetworkAttachment{Name: c.Network, IPv4Address: c.IP}
+		if ip := net.ParseIP(c.IP); ip != nil && ip.To4() == nil {
+			primary = NetworkAttachment{Name: c.Network, IPv6Address: c.IP}
+		}
+		out = append(out, primary)
+	}
+	return append(out, c.Networks...)
+}
+
+// GetCmd gets the command of the container in the format expected by docker
+func (c Container) GetCmd() strslice.StrSlice {
+	if len(c.Command) > 0 {
+		return strslice.StrSlice(c.Command)
+	}
+	if len(c.EntryPoint) == 0 && len(c.Args) > 0 {
+		return strslice.StrSlice(c.Args)
+	}
+	return nil
+}
+
+// GetAutoRemove returns true if the container should be removed once it exits
+func (c Container) GetAutoRemove() bool {
+	if c.AutoRemove != nil {
+		return *c.AutoRemove
+	}
+	return c.RestartPolicy.IsNone()
+}
+
+// GetRestartPolicy gets the restart policy of the container in the format expected by docker
+func (c Container) GetRestartPolicy() container.RestartPolicy {
+	return container.RestartPolicy{
+		Name:              c.RestartPolicy.Name,
+		MaximumRetryCount: c.RestartPolicy.MaximumRetryCount,
+	}
+}
+
+// GetCPUSetCPUs gets the cpus the container may run on, falling back to its bound cpus
+func (c Container) GetCPUSetCPUs() string {
+	if c.Resources.CPUSetCPUs != "" || len(c.BoundCPUs) == 0 {
+		return c.Resources.CPUSetCPUs
+	}
+	cpus := make([]string, len(c.BoundCPUs))
+	for i, cpu := range c.BoundCPUs {
+		cpus[i] = strconv.Itoa(cpu)
+	}
+	return strings.Join(cpus, ",")
+}
+
+// GetShmSize gets the size of /dev/shm in bytes
+func (c Container) GetShmSize() (int64, error) {
+	return ParseSize(c.ShmSize)
+}
+
+// GetCapAdd gets the capabilities added to the container in the format expected by docker
+func (c Container) GetCapAdd() strslice.StrSlice {
+	if len(c.CapAdd) == 0 {
+		return nil
+	}
+	return strslice.StrSlice(c.CapAdd)
+}
+
+// GetCapDrop gets the capabilities dropped from the container in the format expected by docker
+func (c Container) GetCapDrop() strslice.StrSlice {
+	if len(c.CapDrop) == 0 {
+		return nil
+	}
+	return strslice.StrSlice(c.CapDrop)
+}
+
+// GetResources gets the resource limits of the container in the format expected by docker,
+// including its cpus and memory
+func (c Container) GetResources() (out container.Resources, err error) {
+	cpus, err := strconv.ParseFloat(c.Cpus, 64)
+	if err != nil {
+		return out, fmt.Errorf("invalid cpus %q: %w", c.Cpus, err)
+	}
+	out.NanoCPUs = int64(1000000000 * cpus)
+
+	out.Memory, err = c.GetMemory()
+	if err != nil {
+		return out, fmt.Errorf("invalid memory %q: %w", c.Memory, err)
+	}
+	out.MemorySwap, err = ParseSize(c.Resources.MemorySwap)
+	if err != nil {
+		return out, fmt.Errorf("invalid memory swap %q: %w", c.Resources.MemorySwap, err)
+	}
+	out.MemoryReservation, err = ParseSize(c.Resources.MemoryReservation)
+	if err != nil {
+		return out, fmt.Errorf("invalid memory reservation %q: %w",
+			c.Resources.MemoryReservation, err)
+	}
+
+	out.CpusetCpus = c.GetCPUSetCPUs()
+	out.CpusetMems = c.Resources.CPUSetMems
+	out.CPUShares = c.Resources.CPUShares
+	out.CPUPeriod = c.Resources.CPUPeriod
+	out.CPUQuota = c.Resources.CPUQuota
+	out.BlkioWeight = c.Resources.BlkioWeight
+	if c.Resources.PidsLimit != 0 {
+		limit := c.Resources.PidsLimit
+		out.PidsLimit = &limit
+	}
+
+	for _, ulimit := range c.Resources.Ulimits {
+		out.Ulimits = append(out.Ulimits, &units.Ulimit{
+			Name: ulimit.Name, Soft: ulimit.Soft, Hard: ulimit.Hard})
+	}
+	out.BlkioDeviceReadBps, err = throttleDevices(c.Resources.DeviceReadBps)
+	if err != nil {
+		return out, err
+	}
+	out.BlkioDeviceWriteBps, err = throttleDevices(c.Resources.DeviceWriteBps)
+	return out, err
+}
+
+func throttleDevices(rates []DeviceRate) ([]*blkiodev.ThrottleDevice, error) {
+	var out []*blkiodev.ThrottleDevice
+	for _, rate := range rates {
+		bps, err := ParseSize(rate.Rate)
+		if err != nil || bps < 0 {
+			return nil, fmt.Errorf("invalid rate %q for device %q", rate.Rate, rate.Path)
+		}
+		out = append(out, &blkiodev.ThrottleDevice{Path: rate.Path, Rate: uint64(bps)})
+	}
+	return out, nil
+}
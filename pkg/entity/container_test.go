@@ -0,0 +1,135 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/stretchr/testify/assert"
+	"github.com/whiteblock/definition/command"
+)
+
+func TestContainer_GetCmd(t *testing.T) {
+	var tests = []struct {
+		cntr     Container
+		expected strslice.StrSlice
+	}{
+		{cntr: Container{}, expected: nil},
+		{cntr: Container{Command: []string{"run"}}, expected: strslice.StrSlice{"run"}},
+		{
+			cntr:     Container{Container: command.Container{EntryPoint: "geth", Args: []string{"--dev"}}},
+			expected: nil,
+		},
+		{
+			cntr:     Container{Container: command.Container{Args: []string{"--dev"}}},
+			expected: strslice.StrSlice{"--dev"},
+		},
+		{
+			cntr: Container{Container: command.Container{Args: []string{"--dev"}},
+				Command: []string{"run"}},
+			expected: strslice.StrSlice{"run"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cntr.GetCmd())
+		})
+	}
+}
+
+func TestContainer_GetAutoRemove(t *testing.T) {
+	no := false
+	var tests = []struct {
+		cntr     Container
+		expected bool
+	}{
+		{cntr: Container{}, expected: true},
+		{cntr: Container{RestartPolicy: RestartPolicy{Name: "no"}}, expected: true},
+		{cntr: Container{RestartPolicy: RestartPolicy{Name: "always"}}, expected: false},
+		{cntr: Container{AutoRemove: &no}, expected: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cntr.GetAutoRemove())
+		})
+	}
+}
+
+func TestContainer_GetCPUSetCPUs(t *testing.T) {
+	var tests = []struct {
+		cntr     Container
+		expected string
+	}{
+		{cntr: Container{}, expected: ""},
+		{cntr: Container{Container: command.Container{BoundCPUs: []int{1, 3}}}, expected: "1,3"},
+		{
+			cntr: Container{Container: command.Container{BoundCPUs: []int{1, 3}},
+				Resources: Resources{CPUSetCPUs: "0-2"}},
+			expected: "0-2",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cntr.GetCPUSetCPUs())
+		})
+	}
+}
+
+func TestContainer_GetNetworks(t *testing.T) {
+	extra := NetworkAttachment{Name: "private", IPv4Address: "10.1.0.2"}
+	var tests = []struct {
+		cntr     Container
+		expected []NetworkAttachment
+	}{
+		{cntr: Container{}, expected: []NetworkAttachment{}},
+		{
+			cntr:     Container{Container: command.Container{Network: "public", IP: "10.0.0.2"}},
+			expected: []NetworkAttachment{{Name: "public", IPv4Address: "10.0.0.2"}},
+		},
+		{
+			cntr:     Container{Container: command.Container{Network: "public", IP: "fd00::2"}},
+			expected: []NetworkAttachment{{Name: "public", IPv6Address: "fd00::2"}},
+		},
+		{
+			cntr: Container{Container: command.Container{Network: "public"},
+				Networks: []NetworkAttachment{extra}},
+			expected: []NetworkAttachment{{Name: "public"}, extra},
+		},
+		{
+			cntr:     Container{Networks: []NetworkAttachment{extra}},
+			expected: []NetworkAttachment{extra},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cntr.GetNetworks())
+		})
+	}
+}
+
+func TestNetworkAttachment_EndpointSettings(t *testing.T) {
+	endpoint := NetworkAttachment{Name: "n", Aliases: []string{"a"},
+		MacAddress: "02:42:ac:11:00:02"}.EndpointSettings()
+	assert.Equal(t, "n", endpoint.NetworkID)
+	assert.Equal(t, []string{"a"}, endpoint.Aliases)
+	assert.Equal(t, "02:42:ac:11:00:02", endpoint.MacAddress)
+	assert.Nil(t, endpoint.IPAMConfig)
+
+	endpoint = NetworkAttachment{Name: "n", IPv4Address: "10.0.0.2",
+		IPv6Address: "fd00::2"}.EndpointSettings()
+	assert.Equal(t, "10.0.0.2", endpoint.IPAddress)
+	if assert.NotNil(t, endpoint.IPAMConfig) {
+		assert.Equal(t, "10.0.0.2", endpoint.IPAMConfig.IPv4Address)
+		assert.Equal(t, "fd00::2", endpoint.IPAMConfig.IPv6Address)
+	}
+}
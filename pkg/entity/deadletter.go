@@ -0,0 +1,103 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	util "github.com/whiteblock/utility/utils"
+)
+
+// AttemptsHeader is the header which holds the attempt history of a message
+const AttemptsHeader = "attempts"
+
+// Attempt is a record of a failed attempt at executing a step
+type Attempt struct {
+	// Timestamp is the time at which the attempt failed
+	Timestamp time.Time `json:"timestamp"`
+	// Error is the error the attempt failed with
+	Error string `json:"error"`
+	// Failed are the ids of the commands which failed, if only some of them did
+	Failed []string `json:"failed,omitempty"`
+}
+
+// DeadLetter is an instruction which could not be completed, along with why
+type DeadLetter struct {
+	// ID is the unique identifier of this dead letter
+	ID string `json:"id"`
+	// TestID is the id of the instructions, if they could be parsed
+	TestID string `json:"testID,omitempty"`
+	// OrgID is the id of the organization, if the instructions could be parsed
+	OrgID string `json:"orgID,omitempty"`
+	// Timestamp is the time at which the instructions were dead-lettered
+	Timestamp time.Time `json:"timestamp"`
+	// Body is the original message body. If the body was not valid JSON, it is stored as a string
+	Body json.RawMessage `json:"body"`
+	// Result is the final result, as JSON
+	Result json.RawMessage `json:"result"`
+	// Attempts is the history of the failed attempts which preceded the final result
+	Attempts []Attempt `json:"attempts"`
+}
+
+// GetAttempts extracts the attempt history from the given message headers
+func GetAttempts(headers map[string]interface{}) []Attempt {
+	out := []Attempt{}
+	raw, ok := headers[AttemptsHeader].(string)
+	if !ok {
+		return out
+	}
+	if json.Unmarshal([]byte(raw), &out) != nil {
+		return []Attempt{}
+	}
+	return out
+}
+
+// AddAttempt records the given failed result in the attempt history of the given message headers
+func AddAttempt(headers map[string]interface{}, res Result) {
+	if headers == nil || res.IsSuccess() {
+		return
+	}
+	attempt := Attempt{Timestamp: time.Now(), Error: res.Error.Error()}
+	if failed, ok := res.Meta["failed"].([]string); ok {
+		attempt.Failed = failed
+	}
+	data, err := json.Marshal(append(GetAttempts(headers), attempt))
+	if err != nil {
+		return
+	}
+	headers[AttemptsHeader] = string(data)
+}
+
+// NewDeadLetter creates a new dead letter from the original message and its final result
+func NewDeadLetter(body []byte, headers map[string]interface{}, res Result) (DeadLetter, error) {
+	out := DeadLetter{
+		ID:        util.GetUUIDString(),
+		Timestamp: time.Now(),
+		Attempts:  GetAttempts(headers),
+	}
+	var inst struct {
+		ID    string `json:"id"`
+		OrgID string `json:"orgID"`
+	}
+	if json.Valid(body) {
+		out.Body = json.RawMessage(body)
+		json.Unmarshal(body, &inst)
+	} else {
+		data, err := json.Marshal(string(body))
+		if err != nil {
+			return DeadLetter{}, err
+		}
+		out.Body = json.RawMessage(data)
+	}
+	out.TestID = inst.ID
+	out.OrgID = inst.OrgID
+
+	var err error
+	out.Result, err = json.Marshal(res)
+	return out, err
+}
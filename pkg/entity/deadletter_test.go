@@ -0,0 +1,64 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddAttempt(t *testing.T) {
+	headers := map[string]interface{}{}
+	assert.Len(t, GetAttempts(headers), 0)
+
+	AddAttempt(headers, NewSuccessResult())
+	assert.Len(t, GetAttempts(headers), 0)
+
+	AddAttempt(headers, NewErrorResult("first"))
+	AddAttempt(headers, NewErrorResult("second").InjectMeta(map[string]interface{}{
+		"failed": []string{"1", "2"},
+	}))
+
+	attempts := GetAttempts(headers)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, "first", attempts[0].Error)
+	assert.Equal(t, "second", attempts[1].Error)
+	assert.ElementsMatch(t, []string{"1", "2"}, attempts[1].Failed)
+}
+
+func TestNewDeadLetter(t *testing.T) {
+	headers := map[string]interface{}{}
+	AddAttempt(headers, NewErrorResult("first"))
+
+	letter, err := NewDeadLetter([]byte(`{"id":"test","orgID":"org"}`), headers, NewFatalResult("final"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, letter.ID)
+	assert.Equal(t, "test", letter.TestID)
+	assert.Equal(t, "org", letter.OrgID)
+	assert.Len(t, letter.Attempts, 1)
+
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal(letter.Result, &res))
+	assert.Equal(t, "final", res["error"])
+	assert.Equal(t, "Fatal", res["type"])
+}
+
+func TestNewDeadLetter_Malformed(t *testing.T) {
+	letter, err := NewDeadLetter([]byte("should be a failure"), nil, NewFatalResult("final"))
+	require.NoError(t, err)
+	assert.Empty(t, letter.TestID)
+
+	var body string
+	require.NoError(t, json.Unmarshal(letter.Body, &body))
+	assert.Equal(t, "should be a failure", body)
+
+	_, err = json.Marshal(letter)
+	assert.NoError(t, err)
+}
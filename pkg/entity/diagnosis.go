@@ -0,0 +1,41 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+// DiagnosticStep is the outcome of one step of diagnosing the connection to a docker daemon
+type DiagnosticStep struct {
+	// Name identifies the step
+	Name string `json:"name"`
+	// OK is true if the step succeeded
+	OK bool `json:"ok"`
+	// LatencyMillis is how long the step took, in milliseconds
+	LatencyMillis float64 `json:"latencyMs"`
+	// Error is why the step failed
+	Error string `json:"error,omitempty"`
+	// Detail is what the step found out, such as the response of the daemon
+	Detail interface{} `json:"detail,omitempty"`
+}
+
+// Diagnosis is the outcome of diagnosing the connection to the docker daemon of a host
+type Diagnosis struct {
+	// Host is the address of the host
+	Host string `json:"host"`
+	// Healthy is true if the docker api of the host responded
+	Healthy bool `json:"healthy"`
+	// Steps are the steps of the diagnosis, in the order they ran
+	Steps []DiagnosticStep `json:"steps"`
+}
+
+// Failure gets the first step of the diagnosis which failed
+func (d Diagnosis) Failure() (DiagnosticStep, bool) {
+	for _, step := range d.Steps {
+		if !step.OK {
+			return step, true
+		}
+	}
+	return DiagnosticStep{}, false
+}
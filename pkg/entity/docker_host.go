@@ -0,0 +1,64 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"strings"
+)
+
+// HostPlaceholder is replaced by the address of the host in the endpoint of a DockerHost
+const HostPlaceholder = "{host}"
+
+// DockerHost describes how to connect to the docker daemon of a host
+type DockerHost struct {
+	// Endpoint is the address of the daemon, such as tcp://10.0.0.2:2376,
+	// ssh://user@10.0.0.2 or unix:///var/run/docker.sock
+	Endpoint string `json:"endpoint"`
+	// CACertPath is the filepath to the CA certificate which the daemon's certificate is
+	// verified against
+	CACertPath string `json:"caCertPath"`
+	// CertPath is the filepath to the client certificate
+	CertPath string `json:"certPath"`
+	// KeyPath is the filepath to the private key of the client certificate
+	KeyPath string `json:"keyPath"`
+	// ServerName is the name the daemon's certificate must be valid for
+	ServerName string `json:"serverName"`
+	// InsecureSkipVerify disables the verification of the daemon's certificate if true, or
+	// enables it if false. If it is not set, it is inherited.
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// GetInsecureSkipVerify returns true if the daemon's certificate should not be verified
+func (dh DockerHost) GetInsecureSkipVerify() bool {
+	return dh.InsecureSkipVerify != nil && *dh.InsecureSkipVerify
+}
+
+// Inherit fills in the empty fields of dh from defaults, and then replaces HostPlaceholder
+// in the endpoint and server name with host
+func (dh DockerHost) Inherit(defaults DockerHost, host string) DockerHost {
+	if dh.Endpoint == "" {
+		dh.Endpoint = defaults.Endpoint
+	}
+	if dh.CACertPath == "" {
+		dh.CACertPath = defaults.CACertPath
+	}
+	if dh.CertPath == "" {
+		dh.CertPath = defaults.CertPath
+	}
+	if dh.KeyPath == "" {
+		dh.KeyPath = defaults.KeyPath
+	}
+	if dh.ServerName == "" {
+		dh.ServerName = defaults.ServerName
+	}
+	if dh.InsecureSkipVerify == nil {
+		dh.InsecureSkipVerify = defaults.InsecureSkipVerify
+	}
+	dh.Endpoint = strings.Replace(dh.Endpoint, HostPlaceholder, host, -1)
+	dh.ServerName = strings.Replace(dh.ServerName, HostPlaceholder, host, -1)
+	return dh
+}
@@ -0,0 +1,81 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// ErrorCode classifies the error of a result, so that what to do about it can be decided
+// without inspecting its message
+type ErrorCode string
+
+const (
+	// CodeUnknown is the code of errors which could not be classified
+	CodeUnknown ErrorCode = "Unknown"
+	// CodeDaemonUnreachable is the code of errors from failing to connect to a docker daemon
+	CodeDaemonUnreachable ErrorCode = "DaemonUnreachable"
+	// CodeHostUnavailable is the code of commands which were not sent to their host, as its
+	// docker daemon has recently been unreachable
+	CodeHostUnavailable ErrorCode = "HostUnavailable"
+	// CodeNotFound is the code of errors from a docker object which does not exist
+	CodeNotFound ErrorCode = "NotFound"
+	// CodeConflict is the code of errors from a docker object which conflicts with an
+	// existing one
+	CodeConflict ErrorCode = "Conflict"
+	// CodeInvalidPayload is the code of errors from a command which is malformed
+	CodeInvalidPayload ErrorCode = "InvalidPayload"
+	// CodeUnauthorized is the code of errors from a request which docker refused to allow
+	CodeUnauthorized ErrorCode = "Unauthorized"
+	// CodeTimeout is the code of errors from running out of time
+	CodeTimeout ErrorCode = "Timeout"
+	// CodeImagePullFailed is the code of errors from failing to pull an image
+	CodeImagePullFailed ErrorCode = "ImagePullFailed"
+)
+
+// Retryable returns false if errors with this code will happen again however many times
+// the command is retried
+func (ec ErrorCode) Retryable() bool {
+	return ec != CodeInvalidPayload && ec != CodeUnauthorized
+}
+
+// ClassifyError gets the code of the given error from its type, or from the type of any error
+// which it wraps. It returns an empty code if err is nil.
+func ClassifyError(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CodeTimeout
+	}
+	for cause := err; cause != nil; cause = errors.Unwrap(cause) {
+		switch {
+		case client.IsErrConnectionFailed(cause):
+			return CodeDaemonUnreachable
+		case errdefs.IsNotFound(cause):
+			return CodeNotFound
+		case errdefs.IsConflict(cause):
+			return CodeConflict
+		case errdefs.IsInvalidParameter(cause):
+			return CodeInvalidPayload
+		case errdefs.IsUnauthorized(cause), errdefs.IsForbidden(cause):
+			return CodeUnauthorized
+		case errdefs.IsDeadline(cause):
+			return CodeTimeout
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return CodeDaemonUnreachable
+	}
+	return CodeUnknown
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	var tests = []struct {
+		err      error
+		expected ErrorCode
+	}{
+		{err: nil, expected: ""},
+		{err: errors.New("test"), expected: CodeUnknown},
+		{err: client.ErrorConnectionFailed("10.0.0.2"), expected: CodeDaemonUnreachable},
+		{
+			err:      fmt.Errorf("creating: %w", client.ErrorConnectionFailed("10.0.0.2")),
+			expected: CodeDaemonUnreachable,
+		},
+		{err: &net.OpError{Op: "dial", Err: errors.New("refused")}, expected: CodeDaemonUnreachable},
+		{err: errdefs.NotFound(errors.New("no such container")), expected: CodeNotFound},
+		{err: fmt.Errorf("removing: %w", errdefs.NotFound(errors.New("test"))), expected: CodeNotFound},
+		{err: errdefs.Conflict(errors.New("name in use")), expected: CodeConflict},
+		{err: errdefs.InvalidParameter(errors.New("bad")), expected: CodeInvalidPayload},
+		{err: errdefs.Unauthorized(errors.New("test")), expected: CodeUnauthorized},
+		{err: errdefs.Forbidden(errors.New("test")), expected: CodeUnauthorized},
+		{err: errdefs.Deadline(errors.New("test")), expected: CodeTimeout},
+		{err: context.DeadlineExceeded, expected: CodeTimeout},
+		{err: fmt.Errorf("pulling: %w", context.DeadlineExceeded), expected: CodeTimeout},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestErrorCode_Retryable(t *testing.T) {
+	assert.False(t, CodeInvalidPayload.Retryable())
+	assert.False(t, CodeUnauthorized.Retryable())
+	for _, code := range []ErrorCode{CodeUnknown, CodeDaemonUnreachable, CodeHostUnavailable,
+		CodeNotFound, CodeConflict, CodeTimeout, CodeImagePullFailed} {
+		assert.True(t, code.Retryable(), string(code))
+	}
+}
+
+func TestResult_ErrorCode(t *testing.T) {
+	assert.Equal(t, ErrorCode(""), NewSuccessResult().ErrorCode())
+	assert.Equal(t, CodeNotFound, NewErrorResult(errdefs.NotFound(errors.New("test"))).ErrorCode())
+	assert.Equal(t, CodeImagePullFailed,
+		NewErrorResult("test").WithCode(CodeImagePullFailed).ErrorCode())
+	assert.Equal(t, CodeUnknown, NewFatalResult(errdefs.NotFound(errors.New("test"))).
+		Fatal(errors.New("test")).ErrorCode())
+}
+
+func TestResult_IsRetryable(t *testing.T) {
+	assert.False(t, NewSuccessResult().IsRetryable())
+	assert.False(t, NewFatalResult("test").IsRetryable())
+	assert.False(t, NewErrorResult("test").WithCode(CodeInvalidPayload).IsRetryable())
+	assert.True(t, NewErrorResult("test").IsRetryable())
+	assert.True(t, NewErrorResult(client.ErrorConnectionFailed("10.0.0.2")).IsRetryable())
+}
+
+func TestResult_MarshalJSON_Code(t *testing.T) {
+	var out map[string]interface{}
+	data, err := json.Marshal(NewErrorResult(errdefs.Conflict(errors.New("test"))))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, string(CodeConflict), out["code"])
+
+	out = nil
+	data, err = json.Marshal(NewSuccessResult())
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &out))
+	_, hasCode := out["code"]
+	assert.False(t, hasCode)
+}
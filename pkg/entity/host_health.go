@@ -0,0 +1,46 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"time"
+)
+
+// BreakerState is the state of the circuit breaker of a docker host
+type BreakerState string
+
+const (
+	// BreakerClosed means that commands are sent to the host as normal
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen means that the host's daemon is down, so its commands fail fast
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen means that the host is being probed to see whether its daemon is back
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// HostHealth is the health of a docker host, as seen by the commands sent to it
+type HostHealth struct {
+	// Host is the address of the host
+	Host string `json:"host"`
+	// State is the state of the host's circuit breaker
+	State BreakerState `json:"state"`
+	// ConsecutiveFailures is the number of times in a row that its daemon could not be reached
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// LastError is the error of the last failure to reach its daemon
+	LastError string `json:"lastError,omitempty"`
+	// LastSuccess is when its daemon was last reached
+	LastSuccess time.Time `json:"lastSuccess"`
+	// LastFailure is when its daemon last could not be reached
+	LastFailure time.Time `json:"lastFailure"`
+	// OpenedAt is when the breaker last opened
+	OpenedAt time.Time `json:"openedAt"`
+}
+
+// Healthy returns true if commands are being sent to the host
+func (hh HostHealth) Healthy() bool {
+	return hh.State == BreakerClosed
+}
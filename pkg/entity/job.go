@@ -0,0 +1,66 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobStatus is the state of a job
+type JobStatus string
+
+const (
+	// JobRunning is the status of a job which is still executing
+	JobRunning JobStatus = "running"
+	// JobCompleted is the status of a job which executed all of its steps
+	JobCompleted JobStatus = "completed"
+	// JobTrapped is the status of a job which stopped due to a trap
+	JobTrapped JobStatus = "trapped"
+	// JobFailed is the status of a job which could not be completed
+	JobFailed JobStatus = "failed"
+	// JobInterrupted is the status of a job which was running when Genesis stopped, and
+	// was not resumed
+	JobInterrupted JobStatus = "interrupted"
+)
+
+// IsFinished returns true if the job is no longer executing
+func (js JobStatus) IsFinished() bool {
+	return js != JobRunning
+}
+
+// JobStep is the outcome of a single attempt at executing a step of a job
+type JobStep struct {
+	// Round is the index of the step
+	Round int `json:"round"`
+	// Timestamp is when the attempt finished
+	Timestamp time.Time `json:"timestamp"`
+	// Result is the result of the attempt
+	Result json.RawMessage `json:"result"`
+	// Commands are the ids of the commands which were executed
+	Commands []string `json:"commands"`
+	// Failed are the ids of the commands which failed, if only some of them did
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Job is the progress of instructions submitted over the REST api
+type Job struct {
+	// ID is the id of the instructions
+	ID string `json:"id"`
+	// Status is the current state of the job
+	Status JobStatus `json:"status"`
+	// Instructions are the instructions, including only the steps which have not yet completed
+	Instructions json.RawMessage `json:"instructions"`
+	// Steps is the history of the attempted steps
+	Steps []JobStep `json:"steps"`
+	// Error is the reason the job failed, if it did
+	Error string `json:"error,omitempty"`
+	// Created is when the job was submitted
+	Created time.Time `json:"created"`
+	// Updated is when the job last changed
+	Updated time.Time `json:"updated"`
+}
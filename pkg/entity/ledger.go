@@ -0,0 +1,63 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/whiteblock/definition/command"
+)
+
+// LedgerKey identifies a single command within a single step of some instructions
+type LedgerKey struct {
+	// TestID is the id of the instructions
+	TestID string `json:"testID"`
+	// Round is the index of the step within the instructions
+	Round int `json:"round"`
+	// CommandID is the id of the command
+	CommandID string `json:"commandID"`
+}
+
+// NewLedgerKey creates the ledger key of the given command. It returns false if the command
+// does not belong to any instructions, as such commands cannot be identified on redelivery
+func NewLedgerKey(cmd command.Command) (LedgerKey, bool) {
+	parent := cmd.Parent()
+	if parent == nil || parent.ID == "" || cmd.ID == "" {
+		return LedgerKey{}, false
+	}
+	return LedgerKey{TestID: parent.ID, Round: parent.Round, CommandID: cmd.ID}, true
+}
+
+// String gets the key as a string, which shares its prefix with every other key of the same
+// instructions
+func (lk LedgerKey) String() string {
+	return fmt.Sprintf("%s/%d/%s", lk.TestID, lk.Round, lk.CommandID)
+}
+
+// LedgerEntry is the record of a completed command
+type LedgerEntry struct {
+	// Key identifies the command
+	Key LedgerKey `json:"key"`
+	// Timestamp is when the command completed
+	Timestamp time.Time `json:"timestamp"`
+	// Result is the result the command completed with
+	Result Result `json:"result"`
+}
+
+// Skipped gets the result the command completed with, marked as skipped, for when the command
+// is not executed again
+func (le LedgerEntry) Skipped() Result {
+	out := le.Result
+	out.Meta = map[string]interface{}{}
+	for key, val := range le.Result.Meta {
+		out.Meta[key] = val
+	}
+	out.Meta["skipped"] = true
+	out.Meta["completed"] = le.Timestamp
+	return out
+}
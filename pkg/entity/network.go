@@ -0,0 +1,187 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/whiteblock/definition/command"
+)
+
+// The drivers a network can be created with
+const (
+	BridgeDriver  = "bridge"
+	OverlayDriver = "overlay"
+	MacvlanDriver = "macvlan"
+	IPvlanDriver  = "ipvlan"
+)
+
+// The driver options set from the fields of a network
+const (
+	BridgeNameOption = "com.docker.network.bridge.name"
+	MTUOption        = "com.docker.network.driver.mtu"
+	ParentOption     = "parent"
+)
+
+// Network is the payload of a create network command. It extends the network of the definition
+// with more subnets, so that a network can be dual-stack, and with the choice of its driver.
+type Network struct {
+	command.Network
+
+	// Driver is the driver of the network, which is one of bridge, overlay, macvlan or ipvlan.
+	// It defaults to overlay for global networks, and to bridge otherwise.
+	Driver string `json:"driver,omitempty"`
+
+	// Parent is the host interface macvlan and ipvlan networks are created on, such as "eth0" or
+	// "eth0.10" for a vlan
+	Parent string `json:"parent,omitempty"`
+
+	// Mode is the macvlan mode or the ipvlan mode of the network
+	Mode string `json:"mode,omitempty"`
+
+	// Internal isolates the network from outside of its host, or of the swarm
+	Internal bool `json:"internal,omitempty"`
+
+	// MTU is the maximum transmission unit of the network, which the driver picks if it is zero
+	MTU int `json:"mtu,omitempty"`
+
+	// Options are passed through to the driver of the network
+	Options map[string]string `json:"options,omitempty"`
+
+	// IPRange is the range within the subnet of the network that addresses are allocated from
+	IPRange string `json:"ipRange,omitempty"`
+
+	// AuxAddresses maps host names to addresses within the subnet of the network which are
+	// never allocated to containers
+	AuxAddresses map[string]string `json:"auxAddresses,omitempty"`
+
+	// Subnets are the subnets of the network besides its subnet, such as an ipv6 subnet
+	Subnets []Subnet `json:"subnets,omitempty"`
+
+	// EnableIPv6 enables ipv6 on the network. It is enabled regardless when the network has an
+	// ipv6 subnet.
+	EnableIPv6 bool `json:"enableIPv6,omitempty"`
+}
+
+// Subnet is a subnet of a network, and how addresses are allocated from it
+type Subnet struct {
+	// Subnet is the subnet in CIDR notation
+	Subnet string `json:"subnet"`
+	// Gateway is the address of the gateway of the subnet
+	Gateway string `json:"gateway,omitempty"`
+	// IPRange is the range within the subnet that addresses are allocated from
+	IPRange string `json:"ipRange,omitempty"`
+	// AuxAddresses maps host names to addresses within the subnet which are never allocated
+	// to containers
+	AuxAddresses map[string]string `json:"auxAddresses,omitempty"`
+}
+
+// IPAMConfig gets the subnet in the format expected by docker
+func (s Subnet) IPAMConfig() network.IPAMConfig {
+	return network.IPAMConfig{
+		Subnet:     s.Subnet,
+		Gateway:    s.Gateway,
+		IPRange:    s.IPRange,
+		AuxAddress: s.AuxAddresses,
+	}
+}
+
+// IsIPv6 checks whether the subnet is an ipv6 subnet
+func (s Subnet) IsIPv6() bool {
+	ip, _, err := net.ParseCIDR(s.Subnet)
+	return err == nil && ip.To4() == nil
+}
+
+// GetSubnets gets every subnet of the network, starting with its subnet. The subnet of the network
+// is left out when it is not given and the network has other subnets, otherwise docker picks it.
+func (n Network) GetSubnets() []Subnet {
+	out := []Subnet{}
+	if n.Subnet != "" || n.Gateway != "" || n.IPRange != "" || len(n.Subnets) == 0 {
+		out = append(out, Subnet{
+			Subnet:       n.Subnet,
+			Gateway:      n.Gateway,
+			IPRange:      n.IPRange,
+			AuxAddresses: n.AuxAddresses,
+		})
+	}
+	return append(out, n.Subnets...)
+}
+
+// GetIPAMConfig gets the subnets of the network in the format expected by docker
+func (n Network) GetIPAMConfig() []network.IPAMConfig {
+	out := []network.IPAMConfig{}
+	for _, subnet := range n.GetSubnets() {
+		out = append(out, subnet.IPAMConfig())
+	}
+	return out
+}
+
+// GetDriver gets the driver of the network
+func (n Network) GetDriver() string {
+	if n.Driver != "" {
+		return n.Driver
+	}
+	if n.Global {
+		return OverlayDriver
+	}
+	return BridgeDriver
+}
+
+// GetScope gets the scope of the network, which is swarm for overlay networks and local otherwise
+func (n Network) GetScope() string {
+	if n.GetDriver() == OverlayDriver {
+		return "swarm"
+	}
+	return "local"
+}
+
+// GetModeOption gets the name of the driver option which sets the mode of the network, which is
+// empty if its driver has no modes
+func (n Network) GetModeOption() string {
+	switch n.GetDriver() {
+	case MacvlanDriver, IPvlanDriver:
+		return n.GetDriver() + "_mode"
+	}
+	return ""
+}
+
+// GetOptions gets the options of the driver of the network, including those set from its fields
+func (n Network) GetOptions() map[string]string {
+	out := map[string]string{}
+	for key, val := range n.Options {
+		out[key] = val
+	}
+	if _, ok := out[BridgeNameOption]; !ok && n.GetDriver() == BridgeDriver {
+		out[BridgeNameOption] = n.Name
+	}
+	if n.Parent != "" {
+		out[ParentOption] = n.Parent
+	}
+	if n.Mode != "" && n.GetModeOption() != "" {
+		out[n.GetModeOption()] = n.Mode
+	}
+	if n.MTU != 0 {
+		out[MTUOption] = strconv.Itoa(n.MTU)
+	}
+	return out
+}
+
+// GetEnableIPv6 checks whether ipv6 is enabled on the network, either explicitly or by giving it
+// an ipv6 subnet
+func (n Network) GetEnableIPv6() bool {
+	if n.EnableIPv6 {
+		return true
+	}
+	for _, subnet := range n.GetSubnets() {
+		if subnet.IsIPv6() {
+			return true
+		}
+	}
+	return false
+}
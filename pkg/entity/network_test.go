@@ -0,0 +1,131 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/whiteblock/definition/command"
+)
+
+func TestNetwork_GetSubnets(t *testing.T) {
+	v6 := Subnet{Subnet: "fd00::/64", Gateway: "fd00::1"}
+	var tests = []struct {
+		nw       Network
+		expected []Subnet
+	}{
+		{nw: Network{}, expected: []Subnet{{}}},
+		{
+			nw: Network{Network: command.Network{Subnet: "10.0.0.0/24", Gateway: "10.0.0.1"},
+				IPRange: "10.0.0.128/25", AuxAddresses: map[string]string{"router": "10.0.0.2"}},
+			expected: []Subnet{{Subnet: "10.0.0.0/24", Gateway: "10.0.0.1", IPRange: "10.0.0.128/25",
+				AuxAddresses: map[string]string{"router": "10.0.0.2"}}},
+		},
+		{
+			nw:       Network{Network: command.Network{Subnet: "10.0.0.0/24"}, Subnets: []Subnet{v6}},
+			expected: []Subnet{{Subnet: "10.0.0.0/24"}, v6},
+		},
+		{nw: Network{Subnets: []Subnet{v6}}, expected: []Subnet{v6}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.nw.GetSubnets())
+		})
+	}
+}
+
+func TestNetwork_GetIPAMConfig(t *testing.T) {
+	nw := Network{Network: command.Network{Subnet: "10.0.0.0/24", Gateway: "10.0.0.1"},
+		Subnets: []Subnet{{Subnet: "fd00::/64", IPRange: "fd00::/80",
+			AuxAddresses: map[string]string{"router": "fd00::2"}}}}
+	assert.Equal(t, []network.IPAMConfig{
+		{Subnet: "10.0.0.0/24", Gateway: "10.0.0.1"},
+		{Subnet: "fd00::/64", IPRange: "fd00::/80", AuxAddress: map[string]string{"router": "fd00::2"}},
+	}, nw.GetIPAMConfig())
+}
+
+func TestNetwork_GetEnableIPv6(t *testing.T) {
+	var tests = []struct {
+		nw       Network
+		expected bool
+	}{
+		{nw: Network{}, expected: false},
+		{nw: Network{Network: command.Network{Subnet: "10.0.0.0/24"}}, expected: false},
+		{nw: Network{EnableIPv6: true}, expected: true},
+		{nw: Network{Network: command.Network{Subnet: "fd00::/64"}}, expected: true},
+		{
+			nw: Network{Network: command.Network{Subnet: "10.0.0.0/24"},
+				Subnets: []Subnet{{Subnet: "fd00::/64"}}},
+			expected: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.nw.GetEnableIPv6())
+		})
+	}
+}
+
+func TestNetwork_GetDriver(t *testing.T) {
+	var tests = []struct {
+		nw     Network
+		driver string
+		scope  string
+	}{
+		{nw: Network{}, driver: BridgeDriver, scope: "local"},
+		{nw: Network{Network: command.Network{Global: true}}, driver: OverlayDriver, scope: "swarm"},
+		{nw: Network{Driver: MacvlanDriver}, driver: MacvlanDriver, scope: "local"},
+		{nw: Network{Driver: OverlayDriver}, driver: OverlayDriver, scope: "swarm"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.driver, tt.nw.GetDriver())
+			assert.Equal(t, tt.scope, tt.nw.GetScope())
+		})
+	}
+}
+
+func TestNetwork_GetOptions(t *testing.T) {
+	var tests = []struct {
+		nw       Network
+		expected map[string]string
+	}{
+		{
+			nw:       Network{Network: command.Network{Name: "n"}},
+			expected: map[string]string{BridgeNameOption: "n"},
+		},
+		{
+			nw: Network{Network: command.Network{Name: "n"}, MTU: 1400,
+				Options: map[string]string{BridgeNameOption: "br0", "foo": "bar"}},
+			expected: map[string]string{BridgeNameOption: "br0", MTUOption: "1400", "foo": "bar"},
+		},
+		{
+			nw:       Network{Network: command.Network{Name: "n", Global: true}},
+			expected: map[string]string{},
+		},
+		{
+			nw:       Network{Driver: MacvlanDriver, Parent: "eth0.10", Mode: "bridge"},
+			expected: map[string]string{ParentOption: "eth0.10", "macvlan_mode": "bridge"},
+		},
+		{
+			nw:       Network{Driver: IPvlanDriver, Parent: "eth1", Mode: "l3"},
+			expected: map[string]string{ParentOption: "eth1", "ipvlan_mode": "l3"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.nw.GetOptions())
+		})
+	}
+}
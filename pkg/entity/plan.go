@@ -0,0 +1,72 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/whiteblock/definition/command"
+)
+
+// PlanCall is a single call which would be made to a docker daemon
+type PlanCall struct {
+	// Host is the docker host the call would be made to
+	Host string `json:"host"`
+	// Method is the name of the docker client method
+	Method string `json:"method"`
+	// Summary is a human readable description of the call
+	Summary string `json:"summary"`
+	// Args holds the arguments of the call, such as the configs given to ContainerCreate
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// PlanCommand is the docker calls which a single command would make
+type PlanCommand struct {
+	// CommandID is the id of the command
+	CommandID string `json:"commandID"`
+	// Type is the type of the order of the command
+	Type command.OrderType `json:"type"`
+	// Target is the ip of the host the command targets
+	Target string `json:"target"`
+	// Calls are the docker calls made by the command, in order
+	Calls []PlanCall `json:"calls"`
+	// Error is set if the command would fail before completing
+	Error string `json:"error,omitempty"`
+}
+
+// PlanStep is the docker calls which a single step would make
+type PlanStep struct {
+	// Step is the index of the step in the instructions
+	Step int `json:"step"`
+	// Commands holds the commands of the step, which would execute concurrently
+	Commands []PlanCommand `json:"commands"`
+}
+
+// Plan is a preview of the docker calls which instructions would make
+type Plan struct {
+	// Steps holds each step of the instructions
+	Steps []PlanStep `json:"steps"`
+}
+
+// String gets the plan in a human readable form
+func (p Plan) String() string {
+	var out strings.Builder
+	for _, step := range p.Steps {
+		fmt.Fprintf(&out, "step %d\n", step.Step)
+		for _, cmd := range step.Commands {
+			fmt.Fprintf(&out, "  %s %s on %s\n", cmd.Type, cmd.CommandID, cmd.Target)
+			for _, call := range cmd.Calls {
+				fmt.Fprintf(&out, "    [%s] %s\n", call.Host, call.Summary)
+			}
+			if cmd.Error != "" {
+				fmt.Fprintf(&out, "    error: %s\n", cmd.Error)
+			}
+		}
+	}
+	return out.String()
+}
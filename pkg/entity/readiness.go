@@ -0,0 +1,41 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"time"
+)
+
+// DependencyStatus is whether Genesis could reach one of its dependencies
+type DependencyStatus struct {
+	// Name identifies the dependency
+	Name string `json:"name"`
+	// Critical is true if Genesis is not ready without the dependency
+	Critical bool `json:"critical"`
+	// OK is true if the dependency was reached
+	OK bool `json:"ok"`
+	// LatencyMillis is how long the check of the dependency took, in milliseconds
+	LatencyMillis float64 `json:"latencyMs"`
+	// Error is why the dependency could not be reached
+	Error string `json:"error,omitempty"`
+	// Details are any further information gathered by the check
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Readiness is whether Genesis can reach the dependencies it needs to execute commands
+type Readiness struct {
+	// Ready is false if any of the critical dependencies could not be reached
+	Ready bool `json:"ready"`
+	// Dependencies is the status of each dependency, which is left out for callers who may
+	// only see whether Genesis is ready
+	Dependencies []DependencyStatus `json:"dependencies,omitempty"`
+}
+
+// Millis converts d into milliseconds
+func Millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -6,8 +6,34 @@
 
 package entity
 
+import (
+	"crypto/tls"
+)
+
+//TLSVersions maps the accepted names of TLS versions to their value
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
 //RestConfig represents the configuration needed for the REST API
 type RestConfig struct {
 	//Listen is the socket to listen on
 	Listen string `json:"listen"`
+	//CertPath is the filepath to the certificate served over TLS
+	CertPath string `json:"certPath"`
+	//KeyPath is the filepath to the private key of the certificate
+	KeyPath string `json:"keyPath"`
+	//ClientCAPath is the filepath to the CA certificates which client certificates must be
+	//signed by. If empty, clients do not need a certificate.
+	ClientCAPath string `json:"clientCAPath"`
+	//MinTLSVersion is the minimum accepted version of TLS, as a key of TLSVersions
+	MinTLSVersion string `json:"minTLSVersion"`
+}
+
+//TLSEnabled returns true if the REST API should be served over TLS
+func (rc RestConfig) TLSEnabled() bool {
+	return rc.CertPath != ""
 }
@@ -8,6 +8,7 @@ package entity
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"runtime"
 
@@ -23,6 +24,8 @@ type Result struct {
 	Error error
 	// Type is the type of result
 	Type ResultType
+	// Code classifies the error, if there is one
+	Code ErrorCode
 
 	// Meta is additional information which can be added for debugging purposes
 	Meta map[string]interface{}
@@ -72,6 +75,7 @@ func (res Result) Fatal(err ...error) Result {
 	res.Type = FatalType
 	if len(err) > 0 {
 		res.Error = err[0]
+		res.Code = ClassifyError(err[0])
 	}
 	return res
 }
@@ -82,47 +86,103 @@ func (res Result) IsRequeue() bool {
 	return res.Type == RequeueType || !res.IsSuccess() && !res.IsFatal()
 }
 
+// WithCode sets the code of the error of this result, for when it is known better than it
+// can be classified from the error itself
+func (res Result) WithCode(code ErrorCode) Result {
+	res.Code = code
+	return res
+}
+
+// ErrorCode gets the code of the error of this result, classifying the error if no code was
+// given. It is empty if there is no error.
+func (res Result) ErrorCode() ErrorCode {
+	if res.Error == nil {
+		return ""
+	}
+	if res.Code == "" {
+		return ClassifyError(res.Error)
+	}
+	return res.Code
+}
+
+// IsRetryable returns true if this result is not successful and retrying the command could
+// give a different result
+func (res Result) IsRetryable() bool {
+	return !res.IsSuccess() && !res.IsFatal() && res.ErrorCode().Retryable()
+}
+
 // InjectMeta allows for chaining on New...Result for the return statement
 func (res Result) InjectMeta(meta map[string]interface{}) Result {
 	mergo.Map(&res.Meta, meta)
 	return res
 }
 
-//MarshalJSON allows Result to customize the marshaling into JSON
-func (res Result) MarshalJSON() ([]byte, error) {
-	resType := ""
-	switch res.Type {
-	case SuccessType:
-		resType = "Success"
-	case AllDoneType:
-		resType = "AllDone"
-	case TooSoonType:
-		resType = "TooSoon"
-	case FatalType:
-		resType = "Fatal"
-	case ErrorType:
-		resType = "Error"
-	case RequeueType:
-		resType = "Requeue"
-	case TrapType:
-		resType = "Trap"
-	default:
-		resType = "Unknown"
+// resultTypeNames are the names given to the types of results in JSON
+var resultTypeNames = map[ResultType]string{
+	SuccessType: "Success",
+	AllDoneType: "AllDone",
+	TooSoonType: "TooSoon",
+	FatalType:   "Fatal",
+	ErrorType:   "Error",
+	RequeueType: "Requeue",
+	TrapType:    "Trap",
+	IgnoreType:  "Ignore",
+}
+
+// String gets the name of the result type, as it is given in JSON
+func (rt ResultType) String() string {
+	name, ok := resultTypeNames[rt]
+	if !ok {
+		return "Unknown"
 	}
+	return name
+}
 
+//MarshalJSON allows Result to customize the marshaling into JSON
+func (res Result) MarshalJSON() ([]byte, error) {
 	jRes := map[string]interface{}{
-		"type":   resType,
+		"type":   res.Type.String(),
 		"meta":   res.Meta,
 		"caller": res.Caller,
 	}
 	if res.Error != nil {
 		jRes["error"] = res.Error.Error()
+		jRes["code"] = res.ErrorCode()
 	} else {
 		jRes["error"] = nil
 	}
 	return json.Marshal(jRes)
 }
 
+// UnmarshalJSON restores a result from the JSON created by MarshalJSON. The error only keeps
+// its message, so its code is taken from the JSON rather than classified again.
+func (res *Result) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type   string                 `json:"type"`
+		Error  *string                `json:"error"`
+		Code   ErrorCode              `json:"code"`
+		Meta   map[string]interface{} `json:"meta"`
+		Caller string                 `json:"caller"`
+	}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+	*res = Result{Code: raw.Code, Meta: raw.Meta, Caller: raw.Caller}
+	for resType, name := range resultTypeNames {
+		if name == raw.Type {
+			res.Type = resType
+		}
+	}
+	if raw.Error != nil {
+		res.Error = errors.New(*raw.Error)
+	}
+	if res.Meta == nil {
+		res.Meta = map[string]interface{}{}
+	}
+	return nil
+}
+
 const (
 	//SuccessType is the type of a successful result
 	SuccessType ResultType = iota + 1
@@ -159,6 +219,23 @@ func getCaller(n int) string {
 	return fmt.Sprintf("%s:%d", file, line)
 }
 
+// wrapError converts err to an error, keeping it in the chain of the error if it is one, so
+// that its cause can still be checked with errors.Is and errors.As
+func wrapError(err interface{}) error {
+	if e, ok := err.(error); ok {
+		return fmt.Errorf("%w", e)
+	}
+	return fmt.Errorf("%v", err)
+}
+
+// newErrorResult creates a result of the given type for err, classifying the error
+func newErrorResult(resType ResultType, err interface{}, depth int) Result {
+	out := Result{Type: resType, Error: wrapError(err),
+		Meta: map[string]interface{}{}, Caller: getCaller(depth + 1)}
+	out.Code = ClassifyError(out.Error)
+	return out
+}
+
 // NewResult creates a success result if err == nil other an error result,
 func NewResult(err interface{}, depth ...int) Result {
 	n := 2
@@ -169,8 +246,7 @@ func NewResult(err interface{}, depth ...int) Result {
 		return Result{Type: SuccessType, Error: nil,
 			Meta: map[string]interface{}{}, Caller: getCaller(n)}
 	}
-	return Result{Type: ErrorType, Error: fmt.Errorf("%v", err),
-		Meta: map[string]interface{}{}, Caller: getCaller(n)}
+	return newErrorResult(ErrorType, err, n)
 }
 
 // NewSuccessResult indicates a successful result
@@ -187,21 +263,18 @@ func NewTrapResult() Result {
 
 // NewFatalResult creates a fatal error result. Commands with fatal errors are not retried
 func NewFatalResult(err interface{}) Result {
-	return Result{Type: FatalType, Error: fmt.Errorf("%v", err),
-		Meta: map[string]interface{}{}, Caller: getCaller(2)}
+	return newErrorResult(FatalType, err, 2)
 }
 
 // NewErrorResult creates a result which indicates a non-fatal error.
 // Commands with this result should be requeued.
 func NewErrorResult(err interface{}) Result {
-	return Result{Type: ErrorType, Error: fmt.Errorf("%v", err),
-		Meta: map[string]interface{}{}, Caller: getCaller(2)}
+	return newErrorResult(ErrorType, err, 2)
 }
 
 // NewIgnoreResult creates a result which indicates to just ack the message, and ignore it
 func NewIgnoreResult(err interface{}) Result {
-	return Result{Type: IgnoreType, Error: fmt.Errorf("%v", err),
-		Meta: map[string]interface{}{}, Caller: getCaller(2)}
+	return newErrorResult(IgnoreType, err, 2)
 }
 
 // NewAllDoneResult creates a result for the all done condition
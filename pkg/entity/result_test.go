@@ -7,11 +7,13 @@
 package entity
 
 import (
+	"encoding/json"
 	"errors"
 	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResult_IsSuccess(t *testing.T) {
@@ -161,3 +163,53 @@ func TestNewErrorResult(t *testing.T) {
 func TestNewAllDoneResult(t *testing.T) {
 	assert.True(t, NewAllDoneResult().IsAllDone())
 }
+
+func TestNewErrorResult_KeepsCause(t *testing.T) {
+	cause := errors.New("cause")
+	for _, res := range []Result{NewResult(cause), NewErrorResult(cause), NewFatalResult(cause),
+		NewIgnoreResult(cause)} {
+		assert.True(t, errors.Is(res.Error, cause))
+		assert.Equal(t, "cause", res.Error.Error())
+	}
+	assert.Equal(t, "cause", NewErrorResult("cause").Error.Error())
+}
+
+func TestResult_UnmarshalJSON(t *testing.T) {
+	var tests = []Result{
+		NewSuccessResult(),
+		NewAllDoneResult(),
+		NewTrapResult(),
+		NewRequeueResult(),
+		NewFatalResult("fatal").InjectMeta(map[string]interface{}{"host": "10.0.0.2"}),
+		NewErrorResult("test").WithCode(CodeImagePullFailed),
+		NewIgnoreResult("ignored"),
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			data, err := json.Marshal(tt)
+			require.NoError(t, err)
+
+			var res Result
+			require.NoError(t, json.Unmarshal(data, &res))
+			assert.Equal(t, tt.Type, res.Type)
+			assert.Equal(t, tt.Meta, res.Meta)
+			assert.Equal(t, tt.Caller, res.Caller)
+			assert.Equal(t, tt.ErrorCode(), res.ErrorCode())
+			if tt.Error == nil {
+				assert.NoError(t, res.Error)
+			} else {
+				assert.EqualError(t, res.Error, tt.Error.Error())
+			}
+
+			again, err := json.Marshal(res)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(again))
+		})
+	}
+}
+
+func TestResult_UnmarshalJSON_Invalid(t *testing.T) {
+	var res Result
+	assert.Error(t, json.Unmarshal([]byte(`{"type": 1}`), &res))
+}
@@ -0,0 +1,17 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+// OrgLoad is the amount of work an organization has waiting and executing
+type OrgLoad struct {
+	// OrgID is the organization, or the test id if the instructions have no organization
+	OrgID string `json:"orgID"`
+	// Queued is the number of instructions waiting to be executed
+	Queued int `json:"queued"`
+	// Running is the number of instructions currently executing
+	Running int64 `json:"running"`
+}
@@ -0,0 +1,44 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package entity
+
+import (
+	"github.com/whiteblock/definition/command"
+)
+
+// Problem is an issue with a command which would stop it from executing
+type Problem struct {
+	// Step is the index of the step which contains the command
+	Step int `json:"step"`
+	// CommandID is the id of the command
+	CommandID string `json:"commandID"`
+	// Type is the type of the order of the command
+	Type command.OrderType `json:"type"`
+	// Message describes the issue
+	Message string `json:"message"`
+}
+
+// NewProblem creates a problem with the command at the given step
+func NewProblem(step int, cmd command.Command, err error) Problem {
+	return Problem{Step: step, CommandID: cmd.ID, Type: cmd.Order.Type, Message: err.Error()}
+}
+
+// Validation is the outcome of checking instructions for problems before executing them
+type Validation struct {
+	// Valid is true if no problems were found
+	Valid bool `json:"valid"`
+	// Problems holds every problem which was found
+	Problems []Problem `json:"problems"`
+}
+
+// NewValidation creates a validation from the given problems
+func NewValidation(problems []Problem) Validation {
+	if problems == nil {
+		problems = []Problem{}
+	}
+	return Validation{Valid: len(problems) == 0, Problems: problems}
+}
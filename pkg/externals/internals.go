@@ -8,9 +8,19 @@ package externals
 
 import (
 	"net"
+
+	"github.com/streadway/amqp"
+	queueExt "github.com/whiteblock/amqp/externals"
 )
 
 //NetConn is just net.Conn pulled into another interface for mocking
 type NetConn interface {
 	net.Conn
 }
+
+//AMQPChannel extends the amqp channel with the ability to fetch single messages,
+//which is needed for browsing a queue
+type AMQPChannel interface {
+	queueExt.AMQPChannel
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+}
@@ -0,0 +1,37 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"github.com/whiteblock/definition/command"
+)
+
+type placeholderSources struct {
+	remoteSources
+}
+
+//NewPlaceholderSources creates a RemoteSources which never fetches the files, instead providing
+//an empty file in their place. It is used to plan out instructions without executing them.
+func NewPlaceholderSources(log logrus.Ext1FieldLogger) RemoteSources {
+	return &placeholderSources{remoteSources{log: log}}
+}
+
+// GetTarReader creates a tar reader which holds an empty file in place of the given file
+func (ps placeholderSources) GetTarReader(testnetID string, file command.File) (io.Reader, error) {
+	var buf bytes.Buffer
+	tr := tar.NewWriter(&buf)
+	err := tr.WriteHeader(ps.getTarHeader(file, 0))
+	if err != nil {
+		return nil, err
+	}
+	return &buf, tr.Close()
+}
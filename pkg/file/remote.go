@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/metrics"
 
 	"github.com/sirupsen/logrus"
 	"github.com/whiteblock/definition/command"
@@ -117,6 +118,7 @@ func (rf remoteSources) GetTarReader(testnetID string, file command.File) (io.Re
 	}
 	defer fileReader.Close()
 	res, _ := ioutil.ReadAll(fileReader)
+	metrics.FileTransferBytes.Observe(float64(len(res)))
 	rdr := bytes.NewReader(res)
 
 	var buf bytes.Buffer
@@ -0,0 +1,188 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/sirupsen/logrus"
+	"github.com/whiteblock/definition/command"
+)
+
+//maxRequestBody is the largest request body which is read to authenticate a request
+const maxRequestBody = 10 << 20
+
+//AuthHandler authenticates REST requests and authorizes them against the orgs they act on
+type AuthHandler interface {
+	//Middleware wraps next, only passing on the requests which are authorized
+	Middleware(next http.Handler) http.Handler
+}
+
+type credentialKey struct{}
+
+// withCredential adds the credential which authenticated a request to its context
+func withCredential(ctx context.Context, cred entity.Credential) context.Context {
+	return context.WithValue(ctx, credentialKey{}, cred)
+}
+
+// allowsJob checks whether the caller of the request may act on each of the orgs of the job's
+// instructions. Requests which were not authenticated, as auth is disabled, are always allowed.
+func allowsJob(r *http.Request, job entity.Job) bool {
+	cred, ok := r.Context().Value(credentialKey{}).(entity.Credential)
+	if !ok || cred.Unrestricted() {
+		return true
+	}
+	var inst command.Instructions
+	if json.Unmarshal(job.Instructions, &inst) != nil {
+		return false
+	}
+	for _, org := range orgs(inst) {
+		if !cred.AllowsOrg(org) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsDetails checks whether the caller of the request may see the details of Genesis and its
+// dependencies, which requires access to every org. Requests which were not authenticated, as
+// auth is disabled, are always allowed.
+func allowsDetails(r *http.Request) bool {
+	cred, ok := r.Context().Value(credentialKey{}).(entity.Credential)
+	return !ok || cred.Unrestricted()
+}
+
+type authHandler struct {
+	auths []service.Authenticator
+	log   logrus.Ext1FieldLogger
+}
+
+//NewAuthHandler creates a new AuthHandler which accepts any of the given methods of
+//authentication
+func NewAuthHandler(auths []service.Authenticator, log logrus.Ext1FieldLogger) AuthHandler {
+	return &authHandler{auths: auths, log: log}
+}
+
+func (ah authHandler) authenticate(r *http.Request, body []byte) (entity.Credential, error) {
+	for _, auth := range ah.auths {
+		cred, ok, err := auth.Authenticate(r, body)
+		if err != nil {
+			return entity.Credential{}, err
+		}
+		if ok {
+			return cred, nil
+		}
+	}
+	return entity.Credential{}, service.ErrInvalidCredentials
+}
+
+// orgs gets the org ids which the instructions act on. Instructions without any org id
+// give an empty org id.
+func orgs(inst command.Instructions) []string {
+	out := []string{inst.OrgID}
+	for _, step := range inst.Commands {
+		for _, cmd := range step {
+			if org, ok := cmd.Meta[command.OrgIDKey]; ok {
+				out = append(out, org)
+			}
+		}
+	}
+	return out
+}
+
+// authorized checks whether the caller may make the request. Requests which submit instructions
+// are allowed if the caller may act on each of their orgs, and fail if the instructions cannot be
+// parsed. Requests for a job are left to the job handler, which checks the orgs of the job's
+// instructions. Every other request requires access to every org.
+func authorized(cred entity.Credential, r *http.Request, body []byte) (bool, error) {
+	if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/jobs/") {
+		return true, nil
+	}
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/command") {
+		return cred.Unrestricted(), nil
+	}
+	var inst command.Instructions
+	err := json.Unmarshal(body, &inst)
+	if err != nil {
+		return false, err
+	}
+	for _, org := range orgs(inst) {
+		if !cred.AllowsOrg(org) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+//Middleware wraps next, only passing on the requests which are authorized. Health and readiness
+//checks are always passed on, though readiness checks which fail to authenticate are passed on
+//without any access, so that they are only told whether Genesis is ready. Bodies larger than
+//maxRequestBody are rejected.
+func (ah authHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimSuffix(r.URL.Path, "/")
+		if r.Method == http.MethodGet && path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+		r.Body.Close()
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		cred, err := ah.authenticate(r, body)
+		if r.Method == http.MethodGet && path == "/ready" {
+			if err != nil {
+				cred = entity.Credential{}
+			}
+			next.ServeHTTP(w, r.WithContext(withCredential(r.Context(), cred)))
+			return
+		}
+		if err != nil {
+			ah.log.WithFields(logrus.Fields{
+				"path":   r.URL.Path,
+				"remote": r.RemoteAddr,
+				"error":  err}).Warn("rejected an unauthenticated request")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		ok, err := authorized(cred, r, body)
+		if err != nil {
+			http.Error(w, "malformed instructions: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			ah.log.WithFields(logrus.Fields{
+				"path":    r.URL.Path,
+				"subject": cred.Subject}).Warn("rejected an unauthorized request")
+			http.Error(w, "not authorized for this org", http.StatusForbidden)
+			return
+		}
+		ah.log.WithFields(logrus.Fields{
+			"path":    r.URL.Path,
+			"subject": cred.Subject}).Debug("authorized a request")
+		next.ServeHTTP(w, r.WithContext(withCredential(r.Context(), cred)))
+	})
+}
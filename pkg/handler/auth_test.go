@@ -0,0 +1,120 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	serviceMocks "github.com/whiteblock/genesis/mocks/pkg/service"
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthHandler_Middleware(t *testing.T) {
+	var tests = []struct {
+		method   string
+		path     string
+		body     string
+		cred     entity.Credential
+		ok       bool
+		err      error
+		expected int
+	}{
+		{method: "GET", path: "/health", expected: http.StatusOK},
+		{method: "GET", path: "/ready/", expected: http.StatusOK},
+		{method: "POST", path: "/command", body: `{"orgID":"a"}`, err: service.ErrInvalidCredentials,
+			expected: http.StatusUnauthorized},
+		{method: "POST", path: "/command", body: `{"orgID":"a"}`, expected: http.StatusUnauthorized},
+		{method: "POST", path: "/command", body: `{"orgID":"a"}`, ok: true,
+			cred: entity.Credential{Orgs: []string{"a"}}, expected: http.StatusOK},
+		{method: "POST", path: "/command?wait=true", body: `{"orgID":"b"}`, ok: true,
+			cred: entity.Credential{Orgs: []string{"a"}}, expected: http.StatusForbidden},
+		{method: "POST", path: "/command/validate",
+			body: `{"orgID":"a","commands":[[{"meta":{"org":"b"}}]]}`, ok: true,
+			cred: entity.Credential{Orgs: []string{"a"}}, expected: http.StatusForbidden},
+		{method: "POST", path: "/command", body: `{}`, ok: true,
+			cred: entity.Credential{Orgs: []string{"a"}}, expected: http.StatusForbidden},
+		{method: "POST", path: "/command", body: `{}`, ok: true,
+			cred: entity.Credential{Orgs: []string{entity.AllOrgs}}, expected: http.StatusOK},
+		{method: "GET", path: "/deadletters", ok: true,
+			cred: entity.Credential{Orgs: []string{"a"}}, expected: http.StatusForbidden},
+		{method: "GET", path: "/deadletters", ok: true,
+			cred: entity.Credential{Orgs: []string{entity.AllOrgs}}, expected: http.StatusOK},
+		{method: "GET", path: "/jobs/test", ok: true,
+			cred: entity.Credential{Orgs: []string{"a"}}, expected: http.StatusOK},
+		{method: "POST", path: "/command/plan", body: `{"orgID":"b"} x`, ok: true,
+			cred: entity.Credential{Orgs: []string{"a"}}, expected: http.StatusBadRequest},
+		{method: "POST", path: "/command", body: `nope`, ok: true,
+			cred: entity.Credential{Orgs: []string{"a"}}, expected: http.StatusBadRequest},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			auth := new(serviceMocks.Authenticator)
+			auth.On("Authenticate", mock.Anything, []byte(tt.body)).Return(
+				tt.cred, tt.ok, tt.err)
+
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				data, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.body, string(data))
+			})
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			NewAuthHandler([]service.Authenticator{auth}, logrus.New()).Middleware(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expected, rr.Code)
+			assert.Equal(t, tt.expected == http.StatusOK, called)
+			if tt.expected == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Middleware_TooLarge(t *testing.T) {
+	auth := new(serviceMocks.Authenticator)
+
+	body := `{"orgID":"` + strings.Repeat("a", maxRequestBody) + `"}`
+	req := httptest.NewRequest("POST", "/command", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	NewAuthHandler([]service.Authenticator{auth}, logrus.New()).Middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	auth.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+}
+
+func TestAuthHandler_Middleware_FirstMatchWins(t *testing.T) {
+	first := new(serviceMocks.Authenticator)
+	first.On("Authenticate", mock.Anything, mock.Anything).Return(entity.Credential{}, false, nil).Once()
+	second := new(serviceMocks.Authenticator)
+	second.On("Authenticate", mock.Anything, mock.Anything).Return(
+		entity.Credential{Orgs: []string{entity.AllOrgs}}, true, nil).Once()
+	third := new(serviceMocks.Authenticator)
+
+	req := httptest.NewRequest("GET", "/jobs", nil)
+	rr := httptest.NewRecorder()
+	NewAuthHandler([]service.Authenticator{first, second, third}, logrus.New()).Middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+	third.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+}
@@ -9,60 +9,185 @@ package auxillary
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/whiteblock/genesis/pkg/config"
 	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/metrics"
+	"github.com/whiteblock/genesis/pkg/service"
+	"github.com/whiteblock/genesis/pkg/tracing"
 	"github.com/whiteblock/genesis/pkg/usecase"
 
 	"github.com/sirupsen/logrus"
 	"github.com/whiteblock/definition/command"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/semaphore"
 )
 
 // Executor handles the  processing of mutliple commands
 type Executor interface {
-	ExecuteCommands(cmds []command.Command) entity.Result
+	// ExecuteCommands executes the commands concurrently, tracing them as a step of the
+	// instructions traced by ctx. Once ctx is done, the commands which are executing are
+	// cancelled and no more are started.
+	ExecuteCommands(ctx context.Context, cmds []command.Command) entity.Result
 }
 
 type executor struct {
-	usecase usecase.DockerUseCase
-	conf    config.Execution
-	log     logrus.Ext1FieldLogger
+	usecase   usecase.DockerUseCase
+	ledger    service.LedgerService
+	breaker   service.HostBreaker
+	diagnoser service.HostDiagnoser
+	conf      config.Execution
+	log       logrus.Ext1FieldLogger
 }
 
-// ErrDockerConnFailed is the error for when the docker daemon is unreachable
-var ErrDockerConnFailed = entity.NewFatalResult("could not connect to docker")
+var (
+	// ErrDockerConnFailed is the error for when the docker daemon is unreachable
+	ErrDockerConnFailed = entity.NewFatalResult("could not connect to docker").WithCode(
+		entity.CodeDaemonUnreachable)
+
+	// ErrHostUnavailable is the error for commands which are not sent to their host, as its
+	// docker daemon has recently been unreachable
+	ErrHostUnavailable = entity.NewErrorResult("docker host is unavailable").WithCode(
+		entity.CodeHostUnavailable)
+)
 
 // NewExecutor creates a new DeliveryHandler which uses the given usecase for
-// executing the extracted command
+// executing the extracted command. Commands already completed according to the ledger
+// are skipped, as are commands for the hosts which the breaker does not allow. The hosts which
+// cannot be connected to are diagnosed by the diagnoser.
 func NewExecutor(
 	conf config.Execution,
 	usecase usecase.DockerUseCase,
+	ledger service.LedgerService,
+	breaker service.HostBreaker,
+	diagnoser service.HostDiagnoser,
 	log logrus.Ext1FieldLogger) Executor {
-	return &executor{usecase: usecase, conf: conf, log: log}
+	return &executor{usecase: usecase, ledger: ledger, breaker: breaker, diagnoser: diagnoser,
+		conf: conf, log: log}
+}
+
+// stopped is the result of a command which was not completed, as ctx is done
+func stopped(ctx context.Context, cmd command.Command) entity.Result {
+	return entity.NewErrorResult(ctx.Err()).WithCode(entity.ClassifyError(ctx.Err())).InjectMeta(
+		map[string]interface{}{
+			"command": cmd,
+		})
+}
+
+func isConnFailure(res entity.Result) bool {
+	return res.ErrorCode() == entity.CodeDaemonUnreachable
+}
+
+// hostDiagnoses makes sure each host is only diagnosed once, no matter how many commands
+// fail to connect to it
+type hostDiagnoses struct {
+	mu    sync.Mutex
+	onces map[string]*diagnosisOnce
+}
+
+type diagnosisOnce struct {
+	once sync.Once
+	diag entity.Diagnosis
+}
+
+func (exec executor) diagnose(ctx context.Context, host string, diags *hostDiagnoses) entity.Diagnosis {
+	diags.mu.Lock()
+	do, ok := diags.onces[host]
+	if !ok {
+		do = &diagnosisOnce{}
+		diags.onces[host] = do
+	}
+	diags.mu.Unlock()
+
+	do.once.Do(func() {
+		do.diag = exec.diagnoser.Diagnose(ctx, host)
+		entry := exec.log.WithField("host", host)
+		if step, failed := do.diag.Failure(); failed {
+			entry = entry.WithFields(logrus.Fields{"step": step.Name, "error": step.Error})
+		}
+		entry.WithField("healthy", do.diag.Healthy).Warn(
+			"diagnosed a docker host which could not be connected to")
+	})
+	return do.diag
 }
 
-func (exec executor) ExecuteCommands(cmds []command.Command) entity.Result {
+func (exec executor) ExecuteCommands(ctx context.Context, cmds []command.Command) entity.Result {
+	ctx, span := tracing.Start(ctx, "step", attribute.Int("genesis.commands", len(cmds)))
+	res := exec.executeCommands(ctx, cmds)
+	tracing.End(span, res)
+	return res
+}
+
+func (exec executor) executeCommand(ctx context.Context, cmd command.Command, attempt int) entity.Result {
+	ctx, span := tracing.Start(ctx, "command",
+		attribute.String("genesis.command.id", cmd.ID),
+		attribute.String("genesis.command.type", string(cmd.Order.Type)),
+		attribute.String("genesis.command.host", cmd.Target.IP),
+		attribute.Int("genesis.command.attempt", attempt))
+	res := exec.usecase.Run(ctx, cmd)
+	tracing.End(span, res)
+	return res
+}
+
+func (exec executor) executeCommands(ctx context.Context, cmds []command.Command) entity.Result {
 	resultChan := make(chan entity.Result, len(cmds))
 	sem := semaphore.NewWeighted(exec.conf.LimitPerTest)
+	diags := &hostDiagnoses{onces: map[string]*diagnosisOnce{}}
 	for _, cmd := range cmds {
 		go func(cmd command.Command) {
+			if res, done := exec.ledger.Completed(cmd); done {
+				resultChan <- res.InjectMeta(map[string]interface{}{
+					"command": cmd,
+				})
+				return
+			}
 
+			host := cmd.Target.IP
 			for i := 0; i < exec.conf.ConnectionRetries; i++ {
-				sem.Acquire(context.Background(), 1)
-				res := exec.usecase.Run(cmd)
+				if !exec.breaker.Allow(host) {
+					exec.log.WithField("host", host).Debug("failing fast for an unavailable host")
+					resultChan <- ErrHostUnavailable.InjectMeta(map[string]interface{}{
+						"command": cmd,
+						"host":    host,
+					})
+					return
+				}
+				if sem.Acquire(ctx, 1) != nil {
+					resultChan <- stopped(ctx, cmd)
+					return
+				}
+				metrics.InFlight.WithLabelValues(metrics.ExecutorComponent).Inc()
+				start := time.Now()
+				res := exec.executeCommand(ctx, cmd, i)
+				metrics.CommandDuration.WithLabelValues(string(cmd.Order.Type)).Observe(
+					time.Since(start).Seconds())
+				metrics.Commands.WithLabelValues(string(cmd.Order.Type), res.Type.String()).Inc()
+				metrics.InFlight.WithLabelValues(metrics.ExecutorComponent).Dec()
 				sem.Release(1)
-				if !res.IsSuccess() && strings.Contains(res.Error.Error(), "connect to the Docker daemon") {
+				if ctx.Err() != nil {
+					//the command was cut short, so its result says nothing about the host
+					resultChan <- stopped(ctx, cmd)
+					return
+				}
+				if isConnFailure(res) {
+					exec.breaker.Failure(host, res.Error)
 					exec.log.WithFields(logrus.Fields{
 						"result":  res,
 						"time":    exec.conf.RetryDelay,
 						"attempt": i,
 					}).Info("connection to docker failed, retrying")
-					time.Sleep(exec.conf.RetryDelay)
+					select {
+					case <-time.After(exec.conf.RetryDelay):
+					case <-ctx.Done():
+						resultChan <- stopped(ctx, cmd)
+						return
+					}
 					continue
 				}
+				exec.breaker.Success(host)
+				exec.ledger.Record(cmd, res)
 				resultChan <- res.InjectMeta(map[string]interface{}{
 					"command": cmd,
 					"attempt": i,
@@ -71,11 +196,13 @@ func (exec executor) ExecuteCommands(cmds []command.Command) entity.Result {
 			}
 			resultChan <- ErrDockerConnFailed.InjectMeta(
 				map[string]interface{}{
-					"command": cmd,
+					"command":   cmd,
+					"diagnosis": exec.diagnose(ctx, host, diags),
 				})
 		}(cmd)
 	}
 	var err error
+	var code entity.ErrorCode
 	isTrap := false
 	failed := []string{}
 	for range cmds {
@@ -88,6 +215,15 @@ func (exec executor) ExecuteCommands(cmds []command.Command) entity.Result {
 				entry.Error("a command had a fatal error")
 				return result
 			}
+			if !result.IsRetryable() {
+				entry.Error("a command failed in a way which retrying cannot fix")
+				return result.Fatal()
+			}
+			if code == "" {
+				code = result.ErrorCode()
+			} else if code != result.ErrorCode() {
+				code = entity.CodeUnknown
+			}
 			failed = append(failed, result.Meta["command"].(command.Command).ID)
 			entry.Warn("a command failed to execute")
 			err = fmt.Errorf("%v;%v", err, result.Error.Error())
@@ -97,7 +233,7 @@ func (exec executor) ExecuteCommands(cmds []command.Command) entity.Result {
 		}
 	}
 	if err != nil {
-		return entity.NewErrorResult(err).InjectMeta(map[string]interface{}{
+		return entity.NewErrorResult(err).WithCode(code).InjectMeta(map[string]interface{}{
 			"failed": failed,
 		})
 	}
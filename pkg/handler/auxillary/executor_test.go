@@ -0,0 +1,290 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package auxillary
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	serviceMocks "github.com/whiteblock/genesis/mocks/pkg/service"
+	usecaseMocks "github.com/whiteblock/genesis/mocks/pkg/usecase"
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/metrics"
+
+	"github.com/docker/docker/client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/whiteblock/definition/command"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExecutor_ExecuteCommands_Ledger(t *testing.T) {
+	done := command.Command{ID: "done"}
+	todo := command.Command{ID: "todo"}
+
+	uc := new(usecaseMocks.DockerUseCase)
+	uc.On("Run", mock.Anything, todo).Return(entity.NewSuccessResult()).Once()
+
+	ledger := new(serviceMocks.LedgerService)
+	ledger.On("Completed", done).Return(entity.NewSuccessResult(), true).Once()
+	ledger.On("Completed", todo).Return(entity.Result{}, false).Once()
+	ledger.On("Record", todo, mock.Anything).Return().Once()
+
+	diagnoser := new(serviceMocks.HostDiagnoser)
+	breaker := new(serviceMocks.HostBreaker)
+	breaker.On("Allow", mock.Anything).Return(true).Once()
+	breaker.On("Success", mock.Anything).Return().Once()
+
+	exec := NewExecutor(config.Execution{LimitPerTest: 1, ConnectionRetries: 1}, uc, ledger,
+		breaker, diagnoser, logrus.New())
+	res := exec.ExecuteCommands(context.Background(), []command.Command{done, todo})
+	assert.True(t, res.IsSuccess())
+
+	uc.AssertExpectations(t)
+	ledger.AssertExpectations(t)
+	breaker.AssertExpectations(t)
+}
+
+func TestExecutor_ExecuteCommands_Breaker(t *testing.T) {
+	cmd := command.Command{ID: "a", Target: command.Target{IP: "10.0.0.2"}}
+	connErr := client.ErrorConnectionFailed(cmd.Target.IP)
+
+	var tests = []struct {
+		allow     []bool
+		results   []entity.Result
+		failures  int
+		success   bool
+		diagnosed bool
+		expected  error
+	}{
+		{allow: []bool{false}, expected: ErrHostUnavailable.Error},
+		{
+			allow:    []bool{true, true},
+			results:  []entity.Result{entity.NewErrorResult(connErr), entity.NewSuccessResult()},
+			failures: 1,
+			success:  true,
+		},
+		{
+			allow:    []bool{true, false},
+			results:  []entity.Result{entity.NewErrorResult(connErr)},
+			failures: 1,
+			expected: ErrHostUnavailable.Error,
+		},
+		{
+			allow:     []bool{true, true},
+			results:   []entity.Result{entity.NewErrorResult(connErr), entity.NewErrorResult(connErr)},
+			failures:  2,
+			diagnosed: true,
+			expected:  ErrDockerConnFailed.Error,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			uc := new(usecaseMocks.DockerUseCase)
+			for _, res := range tt.results {
+				uc.On("Run", mock.Anything, cmd).Return(res).Once()
+			}
+
+			ledger := new(serviceMocks.LedgerService)
+			ledger.On("Completed", cmd).Return(entity.Result{}, false).Once()
+			if tt.success {
+				ledger.On("Record", cmd, mock.Anything).Return().Once()
+			}
+
+			diagnoser := new(serviceMocks.HostDiagnoser)
+			breaker := new(serviceMocks.HostBreaker)
+			for _, allow := range tt.allow {
+				breaker.On("Allow", cmd.Target.IP).Return(allow).Once()
+			}
+			if tt.failures > 0 {
+				breaker.On("Failure", cmd.Target.IP, mock.Anything).Return().Times(tt.failures)
+			}
+			if tt.success {
+				breaker.On("Success", cmd.Target.IP).Return().Once()
+			}
+			diag := entity.Diagnosis{Host: cmd.Target.IP, Steps: []entity.DiagnosticStep{
+				{Name: "ping", Error: "connection refused"}}}
+			if tt.diagnosed {
+				diagnoser.On("Diagnose", mock.Anything, cmd.Target.IP).Return(diag).Once()
+			}
+
+			exec := NewExecutor(config.Execution{LimitPerTest: 1, ConnectionRetries: 2}, uc, ledger,
+				breaker, diagnoser, logrus.New())
+			res := exec.ExecuteCommands(context.Background(), []command.Command{cmd})
+			if tt.expected == nil {
+				assert.True(t, res.IsSuccess())
+			} else {
+				assert.Contains(t, res.Error.Error(), tt.expected.Error())
+			}
+			if tt.diagnosed {
+				assert.Equal(t, diag, res.Meta["diagnosis"])
+			}
+
+			uc.AssertExpectations(t)
+			ledger.AssertExpectations(t)
+			breaker.AssertExpectations(t)
+			diagnoser.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExecutor_ExecuteCommands_ErrorCodes(t *testing.T) {
+	a := command.Command{ID: "a", Target: command.Target{IP: "10.0.0.2"}}
+	b := command.Command{ID: "b", Target: command.Target{IP: "10.0.0.3"}}
+
+	var tests = []struct {
+		results []entity.Result
+		fatal   bool
+		code    entity.ErrorCode
+	}{
+		{
+			results: []entity.Result{entity.NewErrorResult("test").WithCode(entity.CodeInvalidPayload),
+				entity.NewSuccessResult()},
+			fatal: true,
+			code:  entity.CodeInvalidPayload,
+		},
+		{
+			results: []entity.Result{entity.NewErrorResult("test").WithCode(entity.CodeNotFound),
+				entity.NewErrorResult("test").WithCode(entity.CodeNotFound)},
+			code: entity.CodeNotFound,
+		},
+		{
+			results: []entity.Result{entity.NewErrorResult("test").WithCode(entity.CodeNotFound),
+				entity.NewErrorResult("test").WithCode(entity.CodeConflict)},
+			code: entity.CodeUnknown,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			uc := new(usecaseMocks.DockerUseCase)
+			uc.On("Run", mock.Anything, a).Return(tt.results[0]).Once()
+			uc.On("Run", mock.Anything, b).Return(tt.results[1]).Once()
+
+			ledger := new(serviceMocks.LedgerService)
+			ledger.On("Completed", mock.Anything).Return(entity.Result{}, false)
+			ledger.On("Record", mock.Anything, mock.Anything).Return()
+
+			diagnoser := new(serviceMocks.HostDiagnoser)
+			breaker := new(serviceMocks.HostBreaker)
+			breaker.On("Allow", mock.Anything).Return(true)
+			breaker.On("Success", mock.Anything).Return()
+
+			exec := NewExecutor(config.Execution{LimitPerTest: 1, ConnectionRetries: 1}, uc, ledger,
+				breaker, diagnoser, logrus.New())
+			res := exec.ExecuteCommands(context.Background(), []command.Command{a, b})
+			assert.False(t, res.IsSuccess())
+			assert.Equal(t, tt.fatal, res.IsFatal())
+			assert.Equal(t, tt.code, res.ErrorCode())
+		})
+	}
+}
+
+func TestExecutor_ExecuteCommands_Metrics(t *testing.T) {
+	cmd := command.Command{ID: "a", Order: command.Order{Type: "pullImage"}}
+	counter := metrics.Commands.WithLabelValues("pullImage", "Success")
+	before := testutil.ToFloat64(counter)
+
+	uc := new(usecaseMocks.DockerUseCase)
+	uc.On("Run", mock.Anything, cmd).Return(entity.NewSuccessResult()).Once()
+
+	ledger := new(serviceMocks.LedgerService)
+	ledger.On("Completed", cmd).Return(entity.Result{}, false).Once()
+	ledger.On("Record", cmd, mock.Anything).Return().Once()
+
+	diagnoser := new(serviceMocks.HostDiagnoser)
+	breaker := new(serviceMocks.HostBreaker)
+	breaker.On("Allow", mock.Anything).Return(true).Once()
+	breaker.On("Success", mock.Anything).Return().Once()
+
+	exec := NewExecutor(config.Execution{LimitPerTest: 1, ConnectionRetries: 1}, uc, ledger,
+		breaker, diagnoser, logrus.New())
+	assert.True(t, exec.ExecuteCommands(context.Background(), []command.Command{cmd}).IsSuccess())
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+	assert.Equal(t, float64(0), testutil.ToFloat64(
+		metrics.InFlight.WithLabelValues(metrics.ExecutorComponent)))
+}
+
+func TestExecutor_ExecuteCommands_Tracing(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	cmd := command.Command{ID: "a", Order: command.Order{Type: "pullImage"},
+		Target: command.Target{IP: "10.1.2.3"}}
+
+	var cmdSpan trace.SpanContext
+	uc := new(usecaseMocks.DockerUseCase)
+	uc.On("Run", mock.Anything, cmd).Return(entity.NewErrorResult("err")).Run(
+		func(args mock.Arguments) {
+			cmdSpan = trace.SpanContextFromContext(args.Get(0).(context.Context))
+		}).Once()
+
+	ledger := new(serviceMocks.LedgerService)
+	ledger.On("Completed", cmd).Return(entity.Result{}, false).Once()
+	ledger.On("Record", cmd, mock.Anything).Return().Once()
+
+	diagnoser := new(serviceMocks.HostDiagnoser)
+	breaker := new(serviceMocks.HostBreaker)
+	breaker.On("Allow", mock.Anything).Return(true).Once()
+	breaker.On("Success", mock.Anything).Return().Once()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "instructions")
+	exec := NewExecutor(config.Execution{LimitPerTest: 1, ConnectionRetries: 1}, uc, ledger,
+		breaker, diagnoser, logrus.New())
+	assert.False(t, exec.ExecuteCommands(ctx, []command.Command{cmd}).IsSuccess())
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "command", spans[0].Name())
+	assert.Equal(t, cmdSpan.SpanID(), spans[0].SpanContext().SpanID())
+	assert.Equal(t, "step", spans[1].Name())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Equal(t, "Error", spans[1].Status().Code.String())
+	for _, span := range spans {
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+	uc.AssertExpectations(t)
+}
+
+func TestExecutor_ExecuteCommands_Cancelled(t *testing.T) {
+	cmd := command.Command{ID: "a", Target: command.Target{IP: "10.0.0.2"}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	uc := new(usecaseMocks.DockerUseCase)
+	uc.On("Run", mock.Anything, cmd).Return(
+		entity.NewErrorResult(client.ErrorConnectionFailed(cmd.Target.IP))).Run(
+		func(_ mock.Arguments) { cancel() }).Once()
+
+	ledger := new(serviceMocks.LedgerService)
+	ledger.On("Completed", cmd).Return(entity.Result{}, false).Once()
+
+	breaker := new(serviceMocks.HostBreaker)
+	breaker.On("Allow", cmd.Target.IP).Return(true).Once()
+
+	exec := NewExecutor(config.Execution{LimitPerTest: 1, ConnectionRetries: 3}, uc, ledger,
+		breaker, new(serviceMocks.HostDiagnoser), logrus.New())
+	res := exec.ExecuteCommands(ctx, []command.Command{cmd})
+	assert.False(t, res.IsSuccess())
+	assert.Contains(t, res.Error.Error(), context.Canceled.Error())
+
+	uc.AssertExpectations(t)
+	breaker.AssertExpectations(t)
+	breaker.AssertNotCalled(t, "Failure", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,163 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package auxillary
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/bus"
+	"github.com/whiteblock/genesis/pkg/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	queue "github.com/whiteblock/amqp"
+)
+
+// Retrier handles the delayed redelivery of failed messages. Each level of backoff has its own
+// TTL queue, which dead-letters expired messages back onto the command queue, so that no
+// consumer is occupied while waiting.
+type Retrier interface {
+	// Retry places the message onto the delay queue matching its retry count
+	Retry(pub amqp.Publishing) error
+}
+
+type backoff struct {
+	delays []time.Duration
+	jitter float64
+}
+
+type retrier struct {
+	backoff
+	queues []queue.AMQPService
+	log    logrus.Ext1FieldLogger
+}
+
+// NewRetrier creates a new Retrier, given a delay queue for each of the delays in the config
+func NewRetrier(
+	conf config.Retry,
+	queues []queue.AMQPService,
+	log logrus.Ext1FieldLogger) (Retrier, error) {
+
+	delays := conf.Delays()
+	if len(delays) == 0 {
+		return nil, fmt.Errorf("no retry delays are configured")
+	}
+	if len(delays) != len(queues) {
+		return nil, fmt.Errorf("expected %d delay queues, got %d", len(delays), len(queues))
+	}
+	queue.TryCreateQueues(log, queues...)
+	return &retrier{backoff: backoff{delays: delays, jitter: conf.Jitter}, queues: queues, log: log}, nil
+}
+
+func (r backoff) level(pub amqp.Publishing) int {
+	cnt, ok := pub.Headers[queue.RetryCountHeader].(int64)
+	if !ok || cnt < 1 {
+		return 0
+	}
+	if cnt > int64(len(r.delays)) {
+		return len(r.delays) - 1
+	}
+	return int(cnt) - 1
+}
+
+// wait gets the jittered delay for the given level, which is never longer than the TTL
+// of that level's queue
+func (r backoff) wait(level int) time.Duration {
+	delay := r.delays[level]
+	spread := int64(float64(delay) * r.jitter)
+	if spread <= 0 {
+		return delay
+	}
+	return delay - time.Duration(rand.Int63n(spread))
+}
+
+// Retry places the message onto the delay queue matching its retry count
+func (r retrier) Retry(pub amqp.Publishing) error {
+	level := r.level(pub)
+	wait := r.wait(level)
+	pub.Expiration = strconv.FormatInt(wait.Milliseconds(), 10)
+
+	r.log.WithFields(logrus.Fields{
+		"level": level,
+		"wait":  wait,
+	}).Info("scheduling a delayed retry")
+	return r.queues[level].Send(pub)
+}
+
+type timerRetrier struct {
+	backoff
+	cmds bus.Queue
+	log  logrus.Ext1FieldLogger
+}
+
+// NewTimerRetrier creates a new Retrier for message buses without TTL queues. The message is
+// held in process until its delay has passed and then sent back to cmds, so a pending retry
+// is lost if Genesis exits before then.
+func NewTimerRetrier(conf config.Retry, cmds bus.Queue, log logrus.Ext1FieldLogger) (Retrier, error) {
+	delays := conf.Delays()
+	if len(delays) == 0 {
+		return nil, fmt.Errorf("no retry delays are configured")
+	}
+	return &timerRetrier{backoff: backoff{delays: delays, jitter: conf.Jitter}, cmds: cmds, log: log}, nil
+}
+
+// Retry sends the message back to the command queue once the delay for its retry count has passed
+func (r timerRetrier) Retry(pub amqp.Publishing) error {
+	level := r.level(pub)
+	wait := r.wait(level)
+
+	r.log.WithFields(logrus.Fields{
+		"level": level,
+		"wait":  wait,
+	}).Info("scheduling a delayed retry")
+	time.AfterFunc(wait, func() {
+		err := r.cmds.Send(pub)
+		if err != nil {
+			r.log.WithField("err", err).Error("failed to send a delayed retry")
+		}
+	})
+	return nil
+}
+
+type delayRetrier struct {
+	backoff
+	cmds bus.Queue
+	log  logrus.Ext1FieldLogger
+}
+
+// NewDelayRetrier creates a new Retrier for message buses which hold back messages until the time
+// in their bus.NotBeforeHeader, such as NATS. The message is sent straight back to cmds, so the
+// bus keeps it while it waits and a pending retry survives Genesis exiting.
+func NewDelayRetrier(conf config.Retry, cmds bus.Queue, log logrus.Ext1FieldLogger) (Retrier, error) {
+	delays := conf.Delays()
+	if len(delays) == 0 {
+		return nil, fmt.Errorf("no retry delays are configured")
+	}
+	return &delayRetrier{backoff: backoff{delays: delays, jitter: conf.Jitter}, cmds: cmds, log: log}, nil
+}
+
+// Retry sends the message back to the command queue, marked to not be delivered until the delay
+// for its retry count has passed
+func (r delayRetrier) Retry(pub amqp.Publishing) error {
+	level := r.level(pub)
+	wait := r.wait(level)
+
+	r.log.WithFields(logrus.Fields{
+		"level": level,
+		"wait":  wait,
+	}).Info("scheduling a delayed retry")
+	headers := amqp.Table{}
+	for key, val := range pub.Headers {
+		headers[key] = val
+	}
+	headers[bus.NotBeforeHeader] = time.Now().Add(wait).UnixNano() / int64(time.Millisecond)
+	pub.Headers = headers
+	return r.cmds.Send(pub)
+}
@@ -0,0 +1,137 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package auxillary
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	queueMocks "github.com/whiteblock/genesis/mocks/amqp"
+	"github.com/whiteblock/genesis/pkg/bus"
+	"github.com/whiteblock/genesis/pkg/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	queue "github.com/whiteblock/amqp"
+)
+
+var testRetryConf = config.Retry{
+	BaseDelay: time.Second,
+	MaxDelay:  3 * time.Second,
+	Jitter:    0.5,
+}
+
+func TestNewRetrier_Failure(t *testing.T) {
+	_, err := NewRetrier(testRetryConf, nil, logrus.New())
+	assert.Error(t, err)
+
+	_, err = NewRetrier(config.Retry{}, nil, logrus.New())
+	assert.Error(t, err)
+}
+
+func TestRetrier_Retry(t *testing.T) {
+	var tests = []struct {
+		retryCount    interface{}
+		expectedQueue int
+	}{
+		{retryCount: nil, expectedQueue: 0},
+		{retryCount: int64(1), expectedQueue: 0},
+		{retryCount: int64(2), expectedQueue: 1},
+		{retryCount: int64(3), expectedQueue: 2},
+		{retryCount: int64(20), expectedQueue: 2},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			delays := testRetryConf.Delays()
+			require.Len(t, delays, 3)
+
+			queues := []queue.AMQPService{}
+			mocks := []*queueMocks.AMQPService{}
+			for range delays {
+				serv := new(queueMocks.AMQPService)
+				serv.On("CreateQueue").Return(nil).Once()
+				queues = append(queues, serv)
+				mocks = append(mocks, serv)
+			}
+			mocks[tt.expectedQueue].On("Send", mock.Anything).Return(nil).Run(
+				func(args mock.Arguments) {
+					pub, ok := args.Get(0).(amqp.Publishing)
+					require.True(t, ok)
+					expiration, err := strconv.ParseInt(pub.Expiration, 10, 64)
+					require.NoError(t, err)
+
+					delay := delays[tt.expectedQueue]
+					assert.True(t, expiration <= delay.Milliseconds())
+					assert.True(t, expiration >= delay.Milliseconds()/2)
+				}).Once()
+
+			retry, err := NewRetrier(testRetryConf, queues, logrus.New())
+			require.NoError(t, err)
+
+			pub := amqp.Publishing{Headers: amqp.Table{}}
+			if tt.retryCount != nil {
+				pub.Headers[queue.RetryCountHeader] = tt.retryCount
+			}
+			assert.NoError(t, retry.Retry(pub))
+
+			for _, serv := range mocks {
+				serv.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestTimerRetrier_Retry(t *testing.T) {
+	conf := config.Retry{BaseDelay: 10 * time.Millisecond, MaxDelay: 20 * time.Millisecond}
+	cmds := bus.NewMemoryBroker(0, logrus.New()).Queue("test")
+
+	retry, err := NewTimerRetrier(conf, cmds, logrus.New())
+	require.NoError(t, err)
+
+	msgs, err := cmds.Consume()
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.NoError(t, retry.Retry(amqp.Publishing{
+		Headers: amqp.Table{queue.RetryCountHeader: int64(2)},
+		Body:    []byte("test"),
+	}))
+
+	select {
+	case msg := <-msgs:
+		assert.True(t, time.Since(start) >= 20*time.Millisecond)
+		assert.Equal(t, []byte("test"), msg.Body)
+		assert.Equal(t, int64(2), msg.Headers[queue.RetryCountHeader])
+	case <-time.After(time.Second):
+		t.Fatal("the retry was never sent")
+	}
+}
+
+func TestDelayRetrier_Retry(t *testing.T) {
+	conf := config.Retry{BaseDelay: time.Minute, MaxDelay: 2 * time.Minute}
+	broker := bus.NewMemoryBroker(0, logrus.New())
+
+	retry, err := NewDelayRetrier(conf, broker.Queue("test"), logrus.New())
+	require.NoError(t, err)
+
+	headers := amqp.Table{queue.RetryCountHeader: int64(2)}
+	start := time.Now()
+	require.NoError(t, retry.Retry(amqp.Publishing{Headers: headers, Body: []byte("test")}))
+	assert.NotContains(t, headers, bus.NotBeforeHeader)
+
+	msg, ok := broker.Get("test")
+	require.True(t, ok)
+	assert.Equal(t, []byte("test"), msg.Body)
+	assert.Equal(t, int64(2), msg.Headers[queue.RetryCountHeader])
+	notBefore := time.Unix(0, msg.Headers[bus.NotBeforeHeader].(int64)*int64(time.Millisecond))
+	assert.WithinDuration(t, start.Add(2*time.Minute), notBefore, time.Second)
+}
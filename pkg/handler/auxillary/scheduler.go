@@ -0,0 +1,175 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package auxillary
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// Scheduler decides the order in which received instructions are executed. Organizations
+// take turns in round robin order, each starting as many instructions as its weight in its
+// turn, so that one large test cannot starve everyone else.
+type Scheduler interface {
+	// Submit queues the message for execution
+	Submit(msg amqp.Delivery)
+	// Next blocks until a message may be executed. The returned function must be called once
+	// the message has been executed.
+	Next() (amqp.Delivery, func())
+	// Load gets the amount of queued and executing work of each organization
+	Load() []entity.OrgLoad
+}
+
+type orgQueue struct {
+	pending []amqp.Delivery
+	running int64
+}
+
+type scheduler struct {
+	conf    config.Scheduler
+	orgs    map[string]*orgQueue
+	ring    []string
+	current int
+	served  int
+	running int64
+	log     logrus.Ext1FieldLogger
+	mux     sync.Mutex
+	cond    *sync.Cond
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler(conf config.Scheduler, log logrus.Ext1FieldLogger) (Scheduler, error) {
+	if conf.MaxConcurrency < 1 {
+		return nil, fmt.Errorf("max concurrency must be at least 1")
+	}
+	out := &scheduler{conf: conf, orgs: map[string]*orgQueue{}, log: log}
+	out.cond = sync.NewCond(&out.mux)
+	return out, nil
+}
+
+// orgKey gets the organization the instructions belong to, falling back to the test id
+func orgKey(msg amqp.Delivery) string {
+	var inst struct {
+		ID    string `json:"id"`
+		OrgID string `json:"orgID"`
+	}
+	json.Unmarshal(msg.Body, &inst)
+	if inst.OrgID != "" {
+		return inst.OrgID
+	}
+	return inst.ID
+}
+
+// Submit queues the message for execution
+func (s *scheduler) Submit(msg amqp.Delivery) {
+	org := orgKey(msg)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	q, ok := s.orgs[org]
+	if !ok {
+		q = &orgQueue{}
+		s.orgs[org] = q
+	}
+	if len(q.pending) == 0 {
+		s.ring = append(s.ring, org)
+	}
+	q.pending = append(q.pending, msg)
+	s.log.WithFields(logrus.Fields{
+		"org":    org,
+		"queued": len(q.pending),
+	}).Trace("queued a message")
+	s.cond.Broadcast()
+}
+
+func (s *scheduler) eligible(org string) bool {
+	return s.conf.OrgMaxConcurrency == 0 || s.orgs[org].running < s.conf.OrgMaxConcurrency
+}
+
+// pick finds the organization whose turn it is, skipping over those at their concurrency cap
+func (s *scheduler) pick() (string, bool) {
+	for i := 0; i <= len(s.ring); i++ {
+		if len(s.ring) == 0 {
+			return "", false
+		}
+		s.current %= len(s.ring)
+		org := s.ring[s.current]
+		if s.eligible(org) && s.served < s.conf.Weight(org) {
+			return org, true
+		}
+		s.current++
+		s.served = 0
+	}
+	return "", false
+}
+
+// Next blocks until a message may be executed. The returned function must be called once
+// the message has been executed.
+func (s *scheduler) Next() (amqp.Delivery, func()) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for {
+		if s.running < s.conf.MaxConcurrency {
+			if org, ok := s.pick(); ok {
+				return s.start(org), s.doneFunc(org)
+			}
+		}
+		s.cond.Wait()
+	}
+}
+
+func (s *scheduler) start(org string) amqp.Delivery {
+	q := s.orgs[org]
+	msg := q.pending[0]
+	q.pending = q.pending[1:]
+	q.running++
+	s.running++
+	s.served++
+
+	if len(q.pending) == 0 {
+		s.ring = append(s.ring[:s.current], s.ring[s.current+1:]...)
+		s.served = 0
+	}
+	return msg
+}
+
+func (s *scheduler) doneFunc(org string) func() {
+	once := &sync.Once{}
+	return func() {
+		once.Do(func() {
+			s.mux.Lock()
+			defer s.mux.Unlock()
+			q := s.orgs[org]
+			q.running--
+			s.running--
+			if q.running == 0 && len(q.pending) == 0 {
+				delete(s.orgs, org)
+			}
+			s.cond.Broadcast()
+		})
+	}
+}
+
+// Load gets the amount of queued and executing work of each organization
+func (s *scheduler) Load() []entity.OrgLoad {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	out := []entity.OrgLoad{}
+	for org, q := range s.orgs {
+		out = append(out, entity.OrgLoad{OrgID: org, Queued: len(q.pending), Running: q.running})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].OrgID < out[j].OrgID })
+	return out
+}
@@ -0,0 +1,121 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package auxillary
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func orgMessage(org string, id int) amqp.Delivery {
+	return amqp.Delivery{Body: []byte(fmt.Sprintf(`{"id":"%d","orgID":"%s"}`, id, org))}
+}
+
+func TestNewScheduler_Failure(t *testing.T) {
+	_, err := NewScheduler(config.Scheduler{}, logrus.New())
+	assert.Error(t, err)
+}
+
+func TestOrgKey(t *testing.T) {
+	assert.Equal(t, "org", orgKey(amqp.Delivery{Body: []byte(`{"id":"test","orgID":"org"}`)}))
+	assert.Equal(t, "test", orgKey(amqp.Delivery{Body: []byte(`{"id":"test"}`)}))
+	assert.Equal(t, "", orgKey(amqp.Delivery{Body: []byte(`invalid`)}))
+}
+
+func TestScheduler_Next(t *testing.T) {
+	var tests = []struct {
+		conf     config.Scheduler
+		submit   []string
+		expected []string
+	}{
+		{
+			conf:     config.Scheduler{MaxConcurrency: 1, DefaultOrgWeight: 1},
+			submit:   []string{"a", "a", "a", "a", "b", "b", "c"},
+			expected: []string{"a", "b", "c", "a", "b", "a", "a"},
+		},
+		{
+			conf: config.Scheduler{
+				MaxConcurrency:   1,
+				DefaultOrgWeight: 1,
+				OrgWeights:       map[string]int{"b": 2},
+			},
+			submit:   []string{"a", "a", "a", "b", "b", "b", "b"},
+			expected: []string{"a", "b", "b", "a", "b", "b", "a"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			sched, err := NewScheduler(tt.conf, logrus.New())
+			require.NoError(t, err)
+
+			for j, org := range tt.submit {
+				sched.Submit(orgMessage(org, j))
+			}
+			for _, org := range tt.expected {
+				msg, done := sched.Next()
+				assert.Equal(t, org, orgKey(msg))
+				done()
+			}
+			assert.Len(t, sched.Load(), 0)
+		})
+	}
+}
+
+func TestScheduler_OrgMaxConcurrency(t *testing.T) {
+	sched, err := NewScheduler(config.Scheduler{
+		MaxConcurrency:    3,
+		OrgMaxConcurrency: 1,
+		DefaultOrgWeight:  1,
+	}, logrus.New())
+	require.NoError(t, err)
+
+	sched.Submit(orgMessage("a", 0))
+	sched.Submit(orgMessage("a", 1))
+	sched.Submit(orgMessage("b", 2))
+
+	msg, doneA := sched.Next()
+	assert.Equal(t, "a", orgKey(msg))
+	msg, _ = sched.Next()
+	assert.Equal(t, "b", orgKey(msg))
+
+	assert.Equal(t, []entity.OrgLoad{
+		{OrgID: "a", Queued: 1, Running: 1},
+		{OrgID: "b", Queued: 0, Running: 1},
+	}, sched.Load())
+
+	next := make(chan amqp.Delivery)
+	go func() {
+		msg, _ := sched.Next()
+		next <- msg
+	}()
+
+	select {
+	case <-next:
+		t.Fatal("org a exceeded its max concurrency")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	doneA()
+	doneA()
+	select {
+	case msg := <-next:
+		assert.Equal(t, "a", orgKey(msg))
+	case <-time.After(time.Second):
+		t.Fatal("org a was never scheduled")
+	}
+}
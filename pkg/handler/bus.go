@@ -0,0 +1,128 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/whiteblock/definition/command"
+	"github.com/whiteblock/genesis/pkg/bus"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+const defaultBusReceiveMax = 100
+
+//BusHandler handles the REST api calls which produce to and consume from the in memory message bus,
+//so that a single Genesis binary can be used without a separate broker
+type BusHandler interface {
+	//Publish handles placing instructions onto the command queue
+	Publish(w http.ResponseWriter, r *http.Request)
+	//Receive handles taking the next messages from one of the queues which Genesis sends to
+	Receive(w http.ResponseWriter, r *http.Request)
+}
+
+type busHandler struct {
+	broker  bus.MemoryBroker
+	cmds    string
+	outputs map[string]bool
+	log     logrus.Ext1FieldLogger
+}
+
+//NewBusHandler creates a new bus handler, which publishes to the cmds queue of the broker, and
+//lets the outputs queues be received from
+func NewBusHandler(
+	broker bus.MemoryBroker,
+	cmds string,
+	outputs []string,
+	log logrus.Ext1FieldLogger) BusHandler {
+
+	out := &busHandler{broker: broker, cmds: cmds, outputs: map[string]bool{}, log: log}
+	for _, name := range outputs {
+		out.outputs[name] = true
+	}
+	return out
+}
+
+//Publish handles placing instructions onto the command queue
+func (bh busHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	if mux.Vars(r)["queue"] != bh.cmds {
+		http.Error(w, "only the command queue can be published to", http.StatusNotFound)
+		return
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	var inst command.Instructions
+	err = json.Unmarshal(data, &inst)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = bh.broker.Queue(bh.cmds).Send(amqp.Publishing{
+		ContentType: "application/json",
+		Body:        data,
+	})
+	if errors.Is(err, bus.ErrQueueFull) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		bh.log.WithField("error", err).Error("failed to publish to the command queue")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+//Receive handles taking the next messages from one of the queues which Genesis sends to. Up to the
+//max query parameter of them are removed from the queue and responded with, oldest first.
+//Messages which are not JSON are responded with as strings.
+func (bh busHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["queue"]
+	if !bh.outputs[name] {
+		http.Error(w, "no such queue can be received from", http.StatusNotFound)
+		return
+	}
+	max := defaultBusReceiveMax
+	if raw := r.URL.Query().Get("max"); raw != "" {
+		var err error
+		max, err = strconv.Atoi(raw)
+		if err != nil || max < 1 {
+			http.Error(w, "max must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	out := []json.RawMessage{}
+	for len(out) < max {
+		msg, ok := bh.broker.Get(name)
+		if !ok {
+			break
+		}
+		body := json.RawMessage(msg.Body)
+		if !json.Valid(body) {
+			body, _ = json.Marshal(string(msg.Body))
+		}
+		out = append(out, body)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(out)
+	if err != nil {
+		bh.log.Error(err)
+	}
+}
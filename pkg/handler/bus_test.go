@@ -0,0 +1,78 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/whiteblock/genesis/pkg/bus"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusHandler_Publish(t *testing.T) {
+	var tests = []struct {
+		queue string
+		body  string
+		code  int
+	}{
+		{queue: "commands", body: `{"id":"test"}`, code: http.StatusAccepted},
+		{queue: "commands", body: `{"id":"other"}`, code: http.StatusServiceUnavailable},
+		{queue: "commands", body: `{`, code: http.StatusBadRequest},
+		{queue: "completion", body: `{"id":"test"}`, code: http.StatusNotFound},
+	}
+
+	broker := bus.NewMemoryBroker(1, logrus.New())
+	bh := NewBusHandler(broker, "commands", []string{"completion"}, logrus.New())
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/bus/"+tt.queue, strings.NewReader(tt.body))
+		req = mux.SetURLVars(req, map[string]string{"queue": tt.queue})
+		rr := httptest.NewRecorder()
+		bh.Publish(rr, req)
+		assert.Equal(t, tt.code, rr.Code, tt.body)
+	}
+
+	msg, ok := broker.Get("commands")
+	require.True(t, ok)
+	assert.Equal(t, []byte(`{"id":"test"}`), msg.Body)
+}
+
+func TestBusHandler_Receive(t *testing.T) {
+	broker := bus.NewMemoryBroker(0, logrus.New())
+	for _, body := range []string{`{"id":"1"}`, `not json`, `{"id":"3"}`} {
+		require.NoError(t, broker.Queue("completion").Send(amqp.Publishing{Body: []byte(body)}))
+	}
+	bh := NewBusHandler(broker, "commands", []string{"completion"}, logrus.New())
+
+	receive := func(queue string, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/bus/"+queue+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"queue": queue})
+		rr := httptest.NewRecorder()
+		bh.Receive(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusNotFound, receive("commands", "").Code)
+	assert.Equal(t, http.StatusBadRequest, receive("completion", "?max=0").Code)
+
+	rr := receive("completion", "?max=2")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"id":"1"},"not json"]`, rr.Body.String())
+
+	rr = receive("completion", "")
+	assert.JSONEq(t, `[{"id":"3"}]`, rr.Body.String())
+
+	rr = receive("completion", "")
+	assert.JSONEq(t, `[]`, rr.Body.String())
+}
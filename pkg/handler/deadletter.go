@@ -0,0 +1,74 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/whiteblock/genesis/pkg/repository"
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+//DeadLetterHandler handles the REST api calls for the dead letter queue
+type DeadLetterHandler interface {
+	//List handles the listing of all of the dead letters
+	List(w http.ResponseWriter, r *http.Request)
+	//Get handles the inspection of a single dead letter
+	Get(w http.ResponseWriter, r *http.Request)
+	//Replay handles placing a dead letter back onto the command queue
+	Replay(w http.ResponseWriter, r *http.Request)
+}
+
+type deadLetterHandler struct {
+	serv service.DeadLetterService
+	log  logrus.Ext1FieldLogger
+}
+
+//NewDeadLetterHandler creates a new dead letter handler
+func NewDeadLetterHandler(serv service.DeadLetterService, log logrus.Ext1FieldLogger) DeadLetterHandler {
+	return &deadLetterHandler{serv: serv, log: log}
+}
+
+func (dlh deadLetterHandler) respond(w http.ResponseWriter, out interface{}, err error) {
+	if errors.Is(err, repository.ErrDeadLetterNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		dlh.log.WithField("error", err).Error("failed to access the dead letter queue")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(out)
+	if err != nil {
+		dlh.log.Error(err)
+	}
+}
+
+//List handles the listing of all of the dead letters
+func (dlh deadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
+	letters, err := dlh.serv.List()
+	dlh.respond(w, letters, err)
+}
+
+//Get handles the inspection of a single dead letter
+func (dlh deadLetterHandler) Get(w http.ResponseWriter, r *http.Request) {
+	letter, err := dlh.serv.Get(mux.Vars(r)["id"])
+	dlh.respond(w, letter, err)
+}
+
+//Replay handles placing a dead letter back onto the command queue
+func (dlh deadLetterHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	letter, err := dlh.serv.Replay(mux.Vars(r)["id"])
+	dlh.respond(w, letter, err)
+}
@@ -0,0 +1,69 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serviceMocks "github.com/whiteblock/genesis/mocks/pkg/service"
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/repository"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterHandler_List(t *testing.T) {
+	serv := new(serviceMocks.DeadLetterService)
+	serv.On("List").Return([]entity.DeadLetter{{ID: "1"}}, nil).Once()
+
+	req, err := http.NewRequest("GET", "/deadletter", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	NewDeadLetterHandler(serv, logrus.New()).List(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var letters []entity.DeadLetter
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &letters))
+	require.Len(t, letters, 1)
+	assert.Equal(t, "1", letters[0].ID)
+	serv.AssertExpectations(t)
+}
+
+func TestDeadLetterHandler_Get_NotFound(t *testing.T) {
+	serv := new(serviceMocks.DeadLetterService)
+	serv.On("Get", "2").Return(entity.DeadLetter{}, repository.ErrDeadLetterNotFound).Once()
+
+	req, err := http.NewRequest("GET", "/deadletter/2", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+	rr := httptest.NewRecorder()
+	NewDeadLetterHandler(serv, logrus.New()).Get(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	serv.AssertExpectations(t)
+}
+
+func TestDeadLetterHandler_Replay_Failure(t *testing.T) {
+	serv := new(serviceMocks.DeadLetterService)
+	serv.On("Replay", "1").Return(entity.DeadLetter{}, fmt.Errorf("err")).Once()
+
+	req, err := http.NewRequest("POST", "/deadletter/1/replay", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+	NewDeadLetterHandler(serv, logrus.New()).Replay(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	serv.AssertExpectations(t)
+}
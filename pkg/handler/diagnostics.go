@@ -0,0 +1,45 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+//DiagnosticsHandler handles the REST api calls for diagnosing the docker daemons of hosts
+type DiagnosticsHandler interface {
+	//Diagnose handles diagnosing the connection to the docker daemon of a single host
+	Diagnose(w http.ResponseWriter, r *http.Request)
+}
+
+type diagnosticsHandler struct {
+	diagnoser service.HostDiagnoser
+	log       logrus.Ext1FieldLogger
+}
+
+//NewDiagnosticsHandler creates a new diagnostics handler
+func NewDiagnosticsHandler(diagnoser service.HostDiagnoser,
+	log logrus.Ext1FieldLogger) DiagnosticsHandler {
+	return &diagnosticsHandler{diagnoser: diagnoser, log: log}
+}
+
+//Diagnose handles diagnosing the connection to the docker daemon of a single host, responding
+//with the outcome of every step, whether or not the host is healthy
+func (dh diagnosticsHandler) Diagnose(w http.ResponseWriter, r *http.Request) {
+	diag := dh.diagnoser.Diagnose(r.Context(), mux.Vars(r)["host"])
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(diag)
+	if err != nil {
+		dh.log.Error(err)
+	}
+}
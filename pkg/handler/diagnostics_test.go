@@ -0,0 +1,44 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serviceMocks "github.com/whiteblock/genesis/mocks/pkg/service"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsHandler_Diagnose(t *testing.T) {
+	diag := entity.Diagnosis{Host: "10.0.0.2", Steps: []entity.DiagnosticStep{
+		{Name: "ping", Error: "connection refused"},
+		{Name: "dial", OK: true, Detail: "10.0.0.2:2376"},
+	}}
+	diagnoser := new(serviceMocks.HostDiagnoser)
+	diagnoser.On("Diagnose", mock.Anything, "10.0.0.2").Return(diag).Once()
+
+	rr := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest("POST", "/diagnose/10.0.0.2", nil),
+		map[string]string{"host": "10.0.0.2"})
+	NewDiagnosticsHandler(diagnoser, logrus.New()).Diagnose(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var res entity.Diagnosis
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, diag, res)
+	diagnoser.AssertExpectations(t)
+}
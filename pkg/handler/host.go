@@ -0,0 +1,58 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+//HostHandler handles the REST api calls for the health of the docker hosts
+type HostHandler interface {
+	//List handles the reporting of the health of every docker host
+	List(w http.ResponseWriter, r *http.Request)
+	//Get handles the reporting of the health of a single docker host
+	Get(w http.ResponseWriter, r *http.Request)
+}
+
+type hostHandler struct {
+	breaker service.HostBreaker
+	log     logrus.Ext1FieldLogger
+}
+
+//NewHostHandler creates a new host handler
+func NewHostHandler(breaker service.HostBreaker, log logrus.Ext1FieldLogger) HostHandler {
+	return &hostHandler{breaker: breaker, log: log}
+}
+
+func (hh hostHandler) respond(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		hh.log.Error(err)
+	}
+}
+
+//List handles the reporting of the health of every docker host
+func (hh hostHandler) List(w http.ResponseWriter, r *http.Request) {
+	hh.respond(w, hh.breaker.Health())
+}
+
+//Get handles the reporting of the health of a single docker host
+func (hh hostHandler) Get(w http.ResponseWriter, r *http.Request) {
+	health, ok := hh.breaker.Host(mux.Vars(r)["host"])
+	if !ok {
+		http.Error(w, "no commands have been sent to this host", http.StatusNotFound)
+		return
+	}
+	hh.respond(w, health)
+}
@@ -0,0 +1,58 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serviceMocks "github.com/whiteblock/genesis/mocks/pkg/service"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostHandler_List(t *testing.T) {
+	expected := []entity.HostHealth{{Host: "a", State: entity.BreakerOpen, ConsecutiveFailures: 3}}
+	breaker := new(serviceMocks.HostBreaker)
+	breaker.On("Health").Return(expected).Once()
+
+	rr := httptest.NewRecorder()
+	NewHostHandler(breaker, logrus.New()).List(rr, httptest.NewRequest("GET", "/hosts", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res []entity.HostHealth
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, expected, res)
+	breaker.AssertExpectations(t)
+}
+
+func TestHostHandler_Get(t *testing.T) {
+	expected := entity.HostHealth{Host: "a", State: entity.BreakerClosed}
+	breaker := new(serviceMocks.HostBreaker)
+	breaker.On("Host", "a").Return(expected, true).Once()
+	breaker.On("Host", "b").Return(entity.HostHealth{}, false).Once()
+
+	hand := NewHostHandler(breaker, logrus.New())
+
+	rr := httptest.NewRecorder()
+	hand.Get(rr, mux.SetURLVars(httptest.NewRequest("GET", "/hosts/a", nil), map[string]string{"host": "a"}))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res entity.HostHealth
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, expected, res)
+
+	rr = httptest.NewRecorder()
+	hand.Get(rr, mux.SetURLVars(httptest.NewRequest("GET", "/hosts/b", nil), map[string]string{"host": "b"}))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	breaker.AssertExpectations(t)
+}
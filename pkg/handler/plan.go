@@ -0,0 +1,64 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/whiteblock/genesis/pkg/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/whiteblock/definition/command"
+	util "github.com/whiteblock/utility/utils"
+)
+
+//PlanHandler handles the REST api calls for previewing what instructions would do
+type PlanHandler interface {
+	//Plan handles the reporting of the docker calls the given instructions would make
+	Plan(w http.ResponseWriter, r *http.Request)
+}
+
+type planHandler struct {
+	plan usecase.PlanUseCase
+	log  logrus.Ext1FieldLogger
+}
+
+//NewPlanHandler creates a new plan handler
+func NewPlanHandler(plan usecase.PlanUseCase, log logrus.Ext1FieldLogger) PlanHandler {
+	return &planHandler{plan: plan, log: log}
+}
+
+//Plan handles the reporting of the docker calls the given instructions would make. The plan
+//is given as JSON, unless the format query parameter is text
+func (ph planHandler) Plan(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, util.LogError(err).Error(), 400)
+		return
+	}
+	var inst command.Instructions
+	err = json.Unmarshal(data, &inst)
+	if err != nil {
+		http.Error(w, util.LogError(err).Error(), 400)
+		return
+	}
+
+	plan := ph.plan.Plan(inst)
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain")
+		_, err = w.Write([]byte(plan.String()))
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(plan)
+	}
+	if err != nil {
+		ph.log.Error(err)
+	}
+}
@@ -0,0 +1,66 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	usecaseMocks "github.com/whiteblock/genesis/mocks/pkg/usecase"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanHandler_Plan(t *testing.T) {
+	expected := entity.Plan{Steps: []entity.PlanStep{{Step: 0, Commands: []entity.PlanCommand{{
+		CommandID: "TEST",
+		Type:      "createContainer",
+		Target:    "0.0.0.0",
+		Calls:     []entity.PlanCall{{Host: "0.0.0.0", Method: "ContainerStart", Summary: "start"}},
+	}}}}}
+
+	var tests = []struct {
+		query       string
+		contentType string
+		body        string
+	}{
+		{query: "", contentType: "application/json"},
+		{query: "?format=text", contentType: "text/plain", body: expected.String()},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			plan := new(usecaseMocks.PlanUseCase)
+			plan.On("Plan", mock.Anything).Return(expected).Once()
+
+			data, err := json.Marshal(testCommands)
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", "/command/plan"+tt.query, bytes.NewReader(data))
+			rr := httptest.NewRecorder()
+			NewPlanHandler(plan, logrus.New()).Plan(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.contentType, rr.Header().Get("Content-Type"))
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rr.Body.String())
+			} else {
+				var res entity.Plan
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				assert.Equal(t, expected, res)
+			}
+			plan.AssertExpectations(t)
+		})
+	}
+}
@@ -7,19 +7,23 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"time"
 
 	"github.com/whiteblock/genesis/pkg/config"
 	"github.com/whiteblock/genesis/pkg/entity"
 	"github.com/whiteblock/genesis/pkg/handler/auxillary"
+	"github.com/whiteblock/genesis/pkg/service"
+	"github.com/whiteblock/genesis/pkg/tracing"
 
 	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
 	queue "github.com/whiteblock/amqp"
 	"github.com/whiteblock/definition/command"
 	"github.com/whiteblock/definition/command/biome"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DeliveryHandler handles the initial processing of a amqp delivery
@@ -31,34 +35,20 @@ type DeliveryHandler interface {
 type deliveryHandler struct {
 	maxRetries int64
 	aux        auxillary.Executor
+	ledger     service.LedgerService
 	log        logrus.Ext1FieldLogger
 	conf       config.Config
 }
 
 // NewDeliveryHandler creates a new DeliveryHandler which uses the given usecase for
-// executing the extracted command
+// executing the extracted command. The ledger forgets the instructions once they finish.
 func NewDeliveryHandler(
 	aux auxillary.Executor,
+	ledger service.LedgerService,
 	conf config.Config,
 	maxRetries int64,
 	log logrus.Ext1FieldLogger) DeliveryHandler {
-	return &deliveryHandler{aux: aux, conf: conf, log: log, maxRetries: maxRetries}
-}
-
-func (dh deliveryHandler) sleepy(msg amqp.Delivery) {
-	if msg.Headers != nil {
-		return
-	}
-	if _, ok := msg.Headers["retryCount"]; !ok {
-		return
-	}
-
-	if _, ok := msg.Headers["retryCount"].(int64); !ok {
-		return
-	}
-	if msg.Headers["retryCount"].(int64) > 0 {
-		time.Sleep(5 * time.Second)
-	}
+	return &deliveryHandler{aux: aux, ledger: ledger, conf: conf, log: log, maxRetries: maxRetries}
 }
 
 func checkPartialFailure(cmds []command.Command, result entity.Result) ([]string, bool) {
@@ -82,7 +72,7 @@ func (dh deliveryHandler) destructMsg(inst *command.Instructions) amqp.Publishin
 	return out
 }
 
-func (dh deliveryHandler) process(msg amqp.Delivery,
+func (dh deliveryHandler) process(ctx context.Context, msg amqp.Delivery,
 	inst *command.Instructions) (out amqp.Publishing, result entity.Result) {
 
 	cmds, err := inst.Peek()
@@ -102,7 +92,11 @@ func (dh deliveryHandler) process(msg amqp.Delivery,
 		isLastOne = true
 	}
 
-	result = dh.aux.ExecuteCommands(cmds)
+	result = dh.aux.ExecuteCommands(ctx, cmds)
+	if !result.IsSuccess() && !result.IsTrap() && !result.IsFatal() && !result.IsRetryable() {
+		dh.log.WithField("code", result.ErrorCode()).Debug("the error cannot be fixed by retrying")
+		result = result.Fatal()
+	}
 
 	if result.IsFatal() {
 		dh.log.WithFields(logrus.Fields{"result": result, "error": result.Error.Error(),
@@ -131,6 +125,7 @@ func (dh deliveryHandler) process(msg amqp.Delivery,
 		dh.log.WithField("remaining", len(inst.Commands)).Debug("creating message for next round")
 		inst.Next()
 		out, err = queue.GetNextMessage(msg, inst)
+		delete(out.Headers, entity.AttemptsHeader)
 	} else if failed, ok := checkPartialFailure(cmds, result); ok {
 		dh.log.WithFields(logrus.Fields{
 			"failed": failed, "succeeded": len(cmds) - len(failed),
@@ -138,9 +133,11 @@ func (dh deliveryHandler) process(msg amqp.Delivery,
 		}).Warn("something went partially wrong, requeuing only the commands which failed")
 		inst.PartialCompletion(failed)
 		out, err = queue.GetNextMessage(msg, inst)
+		entity.AddAttempt(out.Headers, result)
 	} else {
 		dh.log.WithField("result", result).Debug("something went wrong, getting kickback message")
 		out, err = queue.GetKickbackMessage(dh.maxRetries, msg)
+		entity.AddAttempt(out.Headers, result)
 	}
 
 	if err != nil {
@@ -155,10 +152,20 @@ func (dh deliveryHandler) process(msg amqp.Delivery,
 	return
 }
 
-//Process attempts to extract the command and execute it
+//Process attempts to extract the command and execute it. The trace context in the headers of
+//msg is continued, and passed on to the messages created.
 func (dh deliveryHandler) Process(msg amqp.Delivery) (out amqp.Publishing,
 	status amqp.Publishing, result entity.Result) {
-	dh.sleepy(msg)
+
+	parent := tracing.Extract(context.Background(), msg.Headers)
+	ctx, span := tracing.Start(parent, "instructions")
+	defer func() {
+		tracing.End(span, result)
+	}()
+	if !trace.SpanContextFromContext(parent).IsValid() {
+		//the following rounds are traced as siblings of this one
+		parent = ctx
+	}
 
 	var inst command.Instructions
 	err := json.Unmarshal(msg.Body, &inst)
@@ -169,7 +176,18 @@ func (dh deliveryHandler) Process(msg amqp.Delivery) (out amqp.Publishing,
 				"data": msg.Body,
 			})
 	}
-	out, result = dh.process(msg, &inst)
+	span.SetAttributes(
+		attribute.String("genesis.test.id", inst.ID),
+		attribute.String("genesis.org.id", inst.OrgID),
+		attribute.String("genesis.definition.id", inst.DefinitionID),
+		attribute.Int("genesis.round", inst.Round))
+	out, result = dh.process(ctx, msg, &inst)
+	if out.Body != nil {
+		out.Headers = tracing.Inject(parent, out.Headers)
+	}
+	if result.IsAllDone() || result.IsFatal() {
+		dh.ledger.Forget(inst.ID)
+	}
 
 	stat := inst.Status()
 	if dh.conf.Execution.DebugMode && result.IsFatal() {
@@ -189,5 +207,6 @@ func (dh deliveryHandler) Process(msg amqp.Delivery) (out amqp.Publishing,
 	if err != nil {
 		dh.log.WithField("error", err).Error("malformed status generated")
 	}
+	status.Headers = tracing.Inject(ctx, status.Headers)
 	return
 }
@@ -7,12 +7,17 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"strconv"
 	"testing"
 
 	auxMocks "github.com/whiteblock/genesis/mocks/pkg/handler/auxillary"
+	serviceMocks "github.com/whiteblock/genesis/mocks/pkg/service"
 	"github.com/whiteblock/genesis/pkg/config"
 	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/service"
+	"github.com/whiteblock/genesis/pkg/tracing"
 
 	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
@@ -20,17 +25,26 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/whiteblock/definition/command"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var testLedger = service.NewLedgerService(nil, logrus.New())
+
+var testJobs = service.NewJobService(nil, logrus.New())
+
 func TestNewDeliveryHandler(t *testing.T) {
-	assert.NotNil(t, NewDeliveryHandler(nil, config.Config{}, 1, nil))
+	assert.NotNil(t, NewDeliveryHandler(nil, nil, config.Config{}, 1, nil))
 }
 
 func TestDeliveryHandler_Process_Successful(t *testing.T) {
 	aux := new(auxMocks.Executor)
-	aux.On("ExecuteCommands", mock.Anything).Return(entity.NewSuccessResult()).Once()
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewSuccessResult()).Once()
 
-	dh := NewDeliveryHandler(aux, config.Config{}, 1, logrus.New())
+	dh := NewDeliveryHandler(aux, testLedger, config.Config{}, 1, logrus.New())
 
 	cmd := command.Instructions{Commands: [][]command.Command{{command.Command{
 		Order: command.Order{
@@ -55,7 +69,7 @@ func TestDeliveryHandler_Process_Successful(t *testing.T) {
 func TestDeliveryHandler_Process_Unsuccessful(t *testing.T) {
 	aux := new(auxMocks.Executor)
 
-	dh := NewDeliveryHandler(aux, config.Config{}, 1, logrus.New())
+	dh := NewDeliveryHandler(aux, testLedger, config.Config{}, 1, logrus.New())
 
 	body := []byte("should be a failure")
 
@@ -66,7 +80,7 @@ func TestDeliveryHandler_Process_Unsuccessful(t *testing.T) {
 }
 
 func TestDeliveryHandler_Process_NoCmds_Failures(t *testing.T) {
-	dh := NewDeliveryHandler(nil, config.Config{}, 1, logrus.New())
+	dh := NewDeliveryHandler(nil, testLedger, config.Config{}, 1, logrus.New())
 
 	cmd := command.Instructions{}
 
@@ -79,9 +93,9 @@ func TestDeliveryHandler_Process_NoCmds_Failures(t *testing.T) {
 
 func TestDeliveryHandler_Process_Multiple_Commands_Successful(t *testing.T) {
 	aux := new(auxMocks.Executor)
-	aux.On("ExecuteCommands", mock.Anything).Return(entity.NewSuccessResult()).Once()
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewSuccessResult()).Once()
 
-	dh := NewDeliveryHandler(aux, config.Config{}, 1, logrus.New())
+	dh := NewDeliveryHandler(aux, testLedger, config.Config{}, 1, logrus.New())
 
 	cmd := command.Instructions{Commands: [][]command.Command{
 		[]command.Command{
@@ -120,8 +134,8 @@ func TestDeliveryHandler_Process_Multiple_Commands_Successful(t *testing.T) {
 
 func TestDeliveryHandler_Process_Execute_Nonfatal_Failure(t *testing.T) {
 	aux := new(auxMocks.Executor)
-	aux.On("ExecuteCommands", mock.Anything).Return(entity.NewErrorResult("err")).Once()
-	dh := NewDeliveryHandler(aux, config.Config{}, 1, logrus.New())
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewErrorResult("err")).Once()
+	dh := NewDeliveryHandler(aux, testLedger, config.Config{}, 1, logrus.New())
 
 	cmd := command.Instructions{Commands: [][]command.Command{
 		[]command.Command{
@@ -149,8 +163,8 @@ func TestDeliveryHandler_Process_Execute_Nonfatal_Failure(t *testing.T) {
 
 func TestDeliveryHandler_Process_Execute_Fatal_Failure(t *testing.T) {
 	aux := new(auxMocks.Executor)
-	aux.On("ExecuteCommands", mock.Anything).Return(entity.NewFatalResult("err")).Once()
-	dh := NewDeliveryHandler(aux, config.Config{}, 1, logrus.New())
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewFatalResult("err")).Once()
+	dh := NewDeliveryHandler(aux, testLedger, config.Config{}, 1, logrus.New())
 
 	cmd := command.Instructions{Commands: [][]command.Command{
 		[]command.Command{
@@ -175,3 +189,108 @@ func TestDeliveryHandler_Process_Execute_Fatal_Failure(t *testing.T) {
 	aux.AssertExpectations(t)
 
 }
+
+func TestDeliveryHandler_Process_Execute_Nonretryable_Failure(t *testing.T) {
+	aux := new(auxMocks.Executor)
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(
+		entity.NewErrorResult("err").WithCode(entity.CodeInvalidPayload)).Once()
+	dh := NewDeliveryHandler(aux, testLedger, config.Config{}, 1, logrus.New())
+
+	cmd := command.Instructions{Commands: [][]command.Command{
+		[]command.Command{
+			command.Command{
+				Order: command.Order{
+					Type:    "createContainer",
+					Payload: map[string]interface{}{},
+				},
+				Target: command.Target{
+					IP: "127.0.0.1",
+				},
+			},
+		},
+	}}
+
+	body, err := json.Marshal(cmd)
+	assert.NoError(t, err)
+
+	_, _, res := dh.Process(amqp.Delivery{Body: body})
+	assert.True(t, res.IsFatal())
+	assert.Equal(t, entity.CodeInvalidPayload, res.ErrorCode())
+
+	aux.AssertExpectations(t)
+}
+
+func TestDeliveryHandler_Process_Forget(t *testing.T) {
+	var tests = []struct {
+		res    entity.Result
+		steps  int
+		forget bool
+	}{
+		{res: entity.NewSuccessResult(), steps: 1, forget: true},
+		{res: entity.NewSuccessResult(), steps: 2, forget: false},
+		{res: entity.NewFatalResult("err"), steps: 2, forget: true},
+		{res: entity.NewErrorResult("err"), steps: 1, forget: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			aux := new(auxMocks.Executor)
+			aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(tt.res).Once()
+
+			ledger := new(serviceMocks.LedgerService)
+			if tt.forget {
+				ledger.On("Forget", "test").Return().Once()
+			}
+			dh := NewDeliveryHandler(aux, ledger, config.Config{}, 1, logrus.New())
+
+			inst := command.Instructions{ID: "test"}
+			for j := 0; j < tt.steps; j++ {
+				inst.Commands = append(inst.Commands, []command.Command{{ID: strconv.Itoa(j)}})
+			}
+			body, err := json.Marshal(inst)
+			require.NoError(t, err)
+
+			dh.Process(amqp.Delivery{Body: body})
+			aux.AssertExpectations(t)
+			ledger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeliveryHandler_Process_Tracing(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	var executed trace.SpanContext
+	aux := new(auxMocks.Executor)
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewSuccessResult()).Run(
+		func(args mock.Arguments) {
+			executed = trace.SpanContextFromContext(args.Get(0).(context.Context))
+		}).Once()
+
+	dh := NewDeliveryHandler(aux, testLedger, config.Config{}, 1, logrus.New())
+
+	inst := command.Instructions{ID: "test", Commands: [][]command.Command{{{ID: "a"}}, {{ID: "b"}}}}
+	body, err := json.Marshal(inst)
+	require.NoError(t, err)
+
+	ctx, producer := provider.Tracer("test").Start(context.Background(), "producer")
+	producer.End()
+	out, status, res := dh.Process(amqp.Delivery{Body: body, Headers: tracing.Inject(ctx, nil)})
+	require.True(t, res.IsRequeue())
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "instructions", spans[1].Name())
+	assert.Equal(t, producer.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), executed.SpanID())
+
+	next := trace.SpanContextFromContext(tracing.Extract(context.Background(), out.Headers))
+	assert.Equal(t, producer.SpanContext().SpanID(), next.SpanID())
+	stat := trace.SpanContextFromContext(tracing.Extract(context.Background(), status.Headers))
+	assert.Equal(t, spans[1].SpanContext().SpanID(), stat.SpanID())
+	aux.AssertExpectations(t)
+}
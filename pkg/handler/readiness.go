@@ -0,0 +1,51 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/sirupsen/logrus"
+)
+
+//ReadinessHandler handles the REST api calls for whether Genesis is ready to execute commands
+type ReadinessHandler interface {
+	//Ready handles the reporting of whether each dependency of Genesis can be reached
+	Ready(w http.ResponseWriter, r *http.Request)
+}
+
+type readinessHandler struct {
+	serv service.ReadinessService
+	log  logrus.Ext1FieldLogger
+}
+
+//NewReadinessHandler creates a new readiness handler
+func NewReadinessHandler(serv service.ReadinessService, log logrus.Ext1FieldLogger) ReadinessHandler {
+	return &readinessHandler{serv: serv, log: log}
+}
+
+//Ready handles the reporting of whether each dependency of Genesis can be reached. It responds
+//with 503 if any of the critical dependencies cannot be. When auth is enabled, callers without
+//access to every org are only told whether Genesis is ready.
+func (rh readinessHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ready := rh.serv.Check(r.Context())
+	if !allowsDetails(r) {
+		ready = entity.Readiness{Ready: ready.Ready}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !ready.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	err := json.NewEncoder(w).Encode(ready)
+	if err != nil {
+		rh.log.Error(err)
+	}
+}
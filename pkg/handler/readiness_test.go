@@ -0,0 +1,96 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	serviceMocks "github.com/whiteblock/genesis/mocks/pkg/service"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessHandler_Ready(t *testing.T) {
+	var tests = []struct {
+		ready    entity.Readiness
+		expected int
+	}{
+		{
+			ready: entity.Readiness{Ready: true, Dependencies: []entity.DependencyStatus{
+				{Name: "fileAPI", OK: false, Error: "err"},
+			}},
+			expected: http.StatusOK,
+		},
+		{
+			ready: entity.Readiness{Ready: false, Dependencies: []entity.DependencyStatus{
+				{Name: "docker:10.0.0.2", Critical: true, OK: false, Error: "err"},
+			}},
+			expected: http.StatusServiceUnavailable,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			serv := new(serviceMocks.ReadinessService)
+			serv.On("Check", mock.Anything).Return(tt.ready).Once()
+
+			rr := httptest.NewRecorder()
+			NewReadinessHandler(serv, logrus.New()).Ready(rr, httptest.NewRequest("GET", "/ready", nil))
+
+			assert.Equal(t, tt.expected, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			var res entity.Readiness
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			assert.Equal(t, tt.ready.Ready, res.Ready)
+			assert.Equal(t, tt.ready.Dependencies[0].Name, res.Dependencies[0].Name)
+			serv.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReadinessHandler_Ready_Summary(t *testing.T) {
+	ready := entity.Readiness{Ready: false, Dependencies: []entity.DependencyStatus{
+		{Name: "amqp:commands", Critical: true, OK: false, Error: "err",
+			Details: map[string]int{"messages": 3}},
+	}}
+
+	var tests = []struct {
+		cred    entity.Credential
+		details bool
+	}{
+		{cred: entity.Credential{}, details: false},
+		{cred: entity.Credential{Orgs: []string{"a"}}, details: false},
+		{cred: entity.Credential{Orgs: []string{entity.AllOrgs}}, details: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			serv := new(serviceMocks.ReadinessService)
+			serv.On("Check", mock.Anything).Return(ready).Once()
+
+			req := httptest.NewRequest("GET", "/ready", nil)
+			req = req.WithContext(withCredential(req.Context(), tt.cred))
+			rr := httptest.NewRecorder()
+			NewReadinessHandler(serv, logrus.New()).Ready(rr, req)
+
+			assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+			if tt.details {
+				assert.Contains(t, rr.Body.String(), "amqp:commands")
+			} else {
+				assert.JSONEq(t, `{"ready":false}`, rr.Body.String())
+			}
+		})
+	}
+}
@@ -7,17 +7,26 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/whiteblock/definition/command"
 	"github.com/whiteblock/genesis/pkg/entity"
 	"github.com/whiteblock/genesis/pkg/handler/auxillary"
+	"github.com/whiteblock/genesis/pkg/repository"
+	"github.com/whiteblock/genesis/pkg/service"
+	"github.com/whiteblock/genesis/pkg/tracing"
 	util "github.com/whiteblock/utility/utils"
 
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const maxRetries = 5
@@ -28,24 +37,39 @@ type RestHandler interface {
 	AddCommands(w http.ResponseWriter, r *http.Request)
 	//HealthCheck handles the reporting of the current health of this service
 	HealthCheck(w http.ResponseWriter, r *http.Request)
+	//GetJob handles the reporting of the progress of the instructions with the given id
+	GetJob(w http.ResponseWriter, r *http.Request)
+	//Recover resumes the jobs which were executing when Genesis last stopped, or if resume
+	//is false, marks them as interrupted
+	Recover(resume bool) error
 }
 
 type restHandler struct {
-	aux auxillary.Executor
-	log logrus.Ext1FieldLogger
+	aux    auxillary.Executor
+	ledger service.LedgerService
+	jobs   service.JobService
+	log    logrus.Ext1FieldLogger
 }
 
 //NewRestHandler creates a new rest handler
-func NewRestHandler(aux auxillary.Executor, log logrus.Ext1FieldLogger) RestHandler {
+func NewRestHandler(
+	aux auxillary.Executor,
+	ledger service.LedgerService,
+	jobs service.JobService,
+	log logrus.Ext1FieldLogger) RestHandler {
 	log.Debug("creating a new rest handler")
 	out := &restHandler{
-		aux: aux,
-		log: log,
+		aux:    aux,
+		ledger: ledger,
+		jobs:   jobs,
+		log:    log,
 	}
 	return out
 }
 
-//AddCommands handles the addition of new commands
+//AddCommands handles the addition of new commands. If the wait query parameter is true, it
+//responds only once the instructions finish, with their final result. An optional timeout query
+//parameter limits how long it waits for.
 func (rh *restHandler) AddCommands(w http.ResponseWriter, r *http.Request) {
 	var cmds command.Instructions
 	data, err := ioutil.ReadAll(r.Body)
@@ -60,11 +84,140 @@ func (rh *restHandler) AddCommands(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, util.LogError(err).Error(), 400)
 		return
 	}
-	go rh.run(&cmds)
-	w.Write([]byte("Success"))
+	wait, timeout, err := parseWait(r)
+	if err != nil {
+		http.Error(w, util.LogError(err).Error(), 400)
+		return
+	}
+	if cmds.ID == "" {
+		cmds.ID = util.GetUUIDString()
+	}
+	err = rh.jobs.Start(cmds)
+	if err != nil {
+		http.Error(w, util.LogError(err).Error(), 500)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+cmds.ID)
+	if !wait {
+		go rh.run(tracing.Detach(r.Context()), &cmds)
+		w.Write([]byte("Success"))
+		return
+	}
+
+	ctx := r.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	res := rh.run(ctx, &cmds).InjectMeta(map[string]interface{}{command.TestIDKey: cmds.ID})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resultStatusCode(ctx, res))
+	err = json.NewEncoder(w).Encode(res)
+	if err != nil {
+		rh.log.WithField("error", err).Error("failed to write the result")
+	}
+}
+
+func parseWait(r *http.Request) (wait bool, timeout time.Duration, err error) {
+	query := r.URL.Query()
+	if raw := query.Get("wait"); raw != "" {
+		wait, err = strconv.ParseBool(raw)
+		if err != nil {
+			return false, 0, fmt.Errorf("invalid wait parameter: %w", err)
+		}
+	}
+	if raw := query.Get("timeout"); raw != "" {
+		timeout, err = time.ParseDuration(raw)
+		if err != nil {
+			return false, 0, fmt.Errorf("invalid timeout parameter: %w", err)
+		}
+		if timeout < 0 {
+			return false, 0, fmt.Errorf("timeout must not be negative")
+		}
+	}
+	return
+}
+
+func resultStatusCode(ctx context.Context, res entity.Result) int {
+	switch {
+	case res.IsAllDone() || res.IsTrap():
+		return http.StatusOK
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case res.IsIgnore():
+		return http.StatusBadRequest
+	}
+	switch res.ErrorCode() {
+	case entity.CodeInvalidPayload:
+		return http.StatusBadRequest
+	case entity.CodeUnauthorized:
+		return http.StatusForbidden
+	case entity.CodeDaemonUnreachable, entity.CodeHostUnavailable:
+		return http.StatusServiceUnavailable
+	case entity.CodeTimeout:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+//GetJob handles the reporting of the progress of the instructions with the given id. When auth
+//is enabled, the caller must be allowed to act on every org of the instructions.
+func (rh *restHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := rh.jobs.Get(mux.Vars(r)["id"])
+	if errors.Is(err, repository.ErrJobNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, util.LogError(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	if !allowsJob(r, job) {
+		http.Error(w, "not authorized for this org", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(job)
+	if err != nil {
+		rh.log.WithField("error", err).Error("failed to write the job")
+	}
+}
+
+//Recover resumes the jobs which were executing when Genesis last stopped, or if resume
+//is false, marks them as interrupted
+func (rh *restHandler) Recover(resume bool) error {
+	jobs, err := rh.jobs.Unfinished()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		var inst command.Instructions
+		err = json.Unmarshal(job.Instructions, &inst)
+		if err != nil || !resume {
+			rh.log.WithFields(logrus.Fields{
+				"job": job.ID,
+				"err": err}).Warn("marking a job as interrupted")
+			rh.finish(job.ID, entity.JobInterrupted,
+				entity.NewErrorResult("interrupted by a restart of genesis"))
+			continue
+		}
+		rh.log.WithField("job", job.ID).Info("resuming a job")
+		go rh.run(context.Background(), &inst)
+	}
+	return nil
 }
 
-func (rh *restHandler) process(inst *command.Instructions) (result entity.Result) {
+func (rh *restHandler) finish(id string, status entity.JobStatus, res entity.Result) {
+	err := rh.jobs.Finish(id, status, res)
+	if err != nil {
+		rh.log.WithFields(logrus.Fields{
+			"job": id,
+			"err": err}).Error("failed to record the end of a job")
+	}
+}
+
+func (rh *restHandler) process(ctx context.Context, inst *command.Instructions) (result entity.Result) {
 	cmds, err := inst.Peek()
 
 	isLastOne := false
@@ -82,7 +235,7 @@ func (rh *restHandler) process(inst *command.Instructions) (result entity.Result
 		isLastOne = true
 	}
 
-	result = rh.aux.ExecuteCommands(cmds)
+	result = rh.aux.ExecuteCommands(ctx, cmds)
 
 	if result.IsFatal() {
 		rh.log.WithFields(logrus.Fields{"result": result, "error": result.Error.Error(),
@@ -125,40 +278,88 @@ func (rh *restHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (rh *restHandler) run(inst *command.Instructions) {
+//run executes the instructions until they finish, returning the final result. If ctx is
+//cancelled, the docker calls of the current step are cancelled and no further steps are started.
+//The instructions are traced as a child of the span of ctx.
+func (rh *restHandler) run(ctx context.Context, inst *command.Instructions) entity.Result {
+	ctx, span := tracing.Start(ctx, "instructions",
+		attribute.String("genesis.test.id", inst.ID),
+		attribute.String("genesis.org.id", inst.OrgID),
+		attribute.String("genesis.definition.id", inst.DefinitionID))
+	res := rh.runSteps(ctx, inst)
+	tracing.End(span, res)
+	return res
+}
+
+func (rh *restHandler) interrupt(ctx context.Context, inst *command.Instructions) entity.Result {
+	rh.log.WithFields(logrus.Fields{
+		"job": inst.ID,
+		"err": ctx.Err()}).Warn("stopping a job early")
+	res := entity.NewErrorResult(ctx.Err())
+	rh.finish(inst.ID, entity.JobInterrupted, res)
+	return res
+}
+
+func (rh *restHandler) runSteps(ctx context.Context, inst *command.Instructions) entity.Result {
+	defer rh.ledger.Forget(inst.ID)
 	retries := 0
 	for {
-		res := rh.process(inst)
+		if ctx.Err() != nil {
+			return rh.interrupt(ctx, inst)
+		}
+		round := inst.Round
+		cmds, _ := inst.Peek()
+		res := rh.process(ctx, inst)
+		err := rh.jobs.Step(*inst, round, cmds, res)
+		if err != nil {
+			rh.log.WithFields(logrus.Fields{
+				"job": inst.ID,
+				"err": err}).Error("failed to record the progress of a job")
+		}
+		if ctx.Err() != nil && !res.IsSuccess() {
+			//the step was cut short
+			return rh.interrupt(ctx, inst)
+		}
 
 		if res.IsAllDone() {
 			rh.log.Info("successfully completed")
-			return
+			rh.finish(inst.ID, entity.JobCompleted, res)
+			return res
 		}
 		if res.IsFatal() {
 			rh.log.Error("a command could not execute")
-			return
+			rh.finish(inst.ID, entity.JobFailed, res)
+			return res
 		}
 
 		if res.IsIgnore() {
 			rh.log.Error("ignoring a message")
-			return
+			rh.finish(inst.ID, entity.JobFailed, res)
+			return res
 		}
 		if res.IsTrap() {
 			rh.log.Info("a trap was activated")
-			return
+			rh.finish(inst.ID, entity.JobTrapped, res)
+			return res
+		}
+
+		if !res.IsSuccess() && !res.IsRetryable() {
+			rh.log.WithField("code", res.ErrorCode()).Error("a command failed in a way which retrying cannot fix")
+			rh.finish(inst.ID, entity.JobFailed, res)
+			return res
 		}
 
-		if res.IsRequeue() || !res.IsSuccess() {
+		if !res.IsSuccess() {
 			retries++
 			if retries > maxRetries {
 				rh.log.Error("too many retries for command")
-				return
+				res = res.Fatal(fmt.Errorf("retries exhausted: %w", res.Error)).WithCode(res.ErrorCode())
+				rh.finish(inst.ID, entity.JobFailed, res)
+				return res
 			}
 			rh.log.Info("retrying command")
 			continue
-		} else {
-			retries = 0
 		}
-		continue
+		retries = 0
 	}
 }
@@ -8,20 +8,29 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/whiteblock/definition/command"
 	auxMocks "github.com/whiteblock/genesis/mocks/pkg/handler/auxillary"
+	serviceMocks "github.com/whiteblock/genesis/mocks/pkg/service"
 	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/repository"
+	"github.com/whiteblock/genesis/pkg/service"
 
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
 )
 
 var testCommands = command.Instructions{Commands: [][]command.Command{{
@@ -54,13 +63,13 @@ func TestRestHandler(t *testing.T) {
 	runChan := make(chan []command.Command)
 
 	aux := new(auxMocks.Executor)
-	aux.On("ExecuteCommands", mock.Anything).Return(entity.NewSuccessResult()).Run(func(args mock.Arguments) {
-		cmds, ok := args.Get(0).([]command.Command)
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewSuccessResult()).Run(func(args mock.Arguments) {
+		cmds, ok := args.Get(1).([]command.Command)
 		assert.True(t, ok)
 		runChan <- cmds
 	}).Times(len(testCommands.Commands))
 
-	rh := NewRestHandler(aux, logrus.New())
+	rh := NewRestHandler(aux, testLedger, testJobs, logrus.New())
 
 	recorder := httptest.NewRecorder()
 	go rh.AddCommands(recorder, req)
@@ -87,14 +96,14 @@ func TestRestHandler_Requeue(t *testing.T) {
 	runChan := make(chan []command.Command)
 
 	aux := new(auxMocks.Executor)
-	aux.On("ExecuteCommands", mock.Anything).Return(entity.NewErrorResult("err")).Run(func(args mock.Arguments) {
-		cmds, ok := args.Get(0).([]command.Command)
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewErrorResult("err")).Run(func(args mock.Arguments) {
+		cmds, ok := args.Get(1).([]command.Command)
 		assert.True(t, ok)
 		runChan <- cmds
 
 	}).Times(len(testCommands.Commands) * (maxRetries + 1))
 
-	rh := NewRestHandler(aux, logrus.New())
+	rh := NewRestHandler(aux, testLedger, testJobs, logrus.New())
 
 	recorder := httptest.NewRecorder()
 	go rh.AddCommands(recorder, req)
@@ -120,15 +129,15 @@ func TestRestHandler_Fatal(t *testing.T) { //testCommands
 	runChan := make(chan []command.Command)
 
 	aux := new(auxMocks.Executor)
-	aux.On("ExecuteCommands", mock.Anything).Return(entity.NewFatalResult("err")).Run(func(args mock.Arguments) {
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewFatalResult("err")).Run(func(args mock.Arguments) {
 		t.Log("called run")
-		cmds, ok := args.Get(0).([]command.Command)
+		cmds, ok := args.Get(1).([]command.Command)
 		assert.True(t, ok)
 		runChan <- cmds
 
 	}).Times(len(testCommands.Commands))
 
-	rh := NewRestHandler(aux, logrus.New())
+	rh := NewRestHandler(aux, testLedger, testJobs, logrus.New())
 
 	recorder := httptest.NewRecorder()
 	go rh.AddCommands(recorder, req)
@@ -147,9 +156,323 @@ func TestRestHandler_HealthCheck(t *testing.T) {
 	req, err := http.NewRequest("GET", "/health", bytes.NewReader([]byte{}))
 	assert.NoError(t, err)
 
-	rh := NewRestHandler(nil, logrus.New())
+	rh := NewRestHandler(nil, testLedger, testJobs, logrus.New())
 	recorder := httptest.NewRecorder()
 	rh.HealthCheck(recorder, req)
 
 	assert.Equal(t, "OK", recorder.Body.String())
 }
+
+func TestRestHandler_Recover(t *testing.T) {
+	data, err := json.Marshal(testCommands)
+	require.NoError(t, err)
+	job := entity.Job{ID: "test", Status: entity.JobRunning, Instructions: data}
+
+	jobs := new(serviceMocks.JobService)
+	jobs.On("Unfinished").Return([]entity.Job{job}, nil).Once()
+	jobs.On("Finish", "test", entity.JobInterrupted, mock.Anything).Return(nil).Once()
+
+	rh := NewRestHandler(nil, testLedger, jobs, logrus.New())
+	assert.NoError(t, rh.Recover(false))
+	jobs.AssertExpectations(t)
+}
+
+func TestRestHandler_Recover_Resume(t *testing.T) {
+	data, err := json.Marshal(testCommands)
+	require.NoError(t, err)
+	job := entity.Job{ID: "test", Status: entity.JobRunning, Instructions: data}
+
+	done := make(chan bool)
+	aux := new(auxMocks.Executor)
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(entity.NewSuccessResult()).Once()
+
+	jobs := new(serviceMocks.JobService)
+	jobs.On("Unfinished").Return([]entity.Job{job}, nil).Once()
+	jobs.On("Step", mock.Anything, 0, mock.Anything, mock.Anything).Return(nil).Once()
+	jobs.On("Finish", mock.Anything, entity.JobCompleted, mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			done <- true
+		}).Once()
+
+	rh := NewRestHandler(aux, testLedger, jobs, logrus.New())
+	assert.NoError(t, rh.Recover(true))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the job was not resumed")
+	}
+	aux.AssertExpectations(t)
+	jobs.AssertExpectations(t)
+}
+
+func TestRestHandler_Run_JobStore(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	require.NoError(t, err)
+	defer db.Close()
+	repo, err := repository.NewJobRepository(db, logrus.New())
+	require.NoError(t, err)
+	jobs := service.NewJobService(repo, logrus.New())
+
+	steps := make([][]command.Command, maxRetries+3)
+	for i := range steps {
+		steps[i] = []command.Command{{ID: strconv.Itoa(i)}}
+	}
+	inst := command.Instructions{ID: "test", Commands: steps}
+	require.NoError(t, jobs.Start(inst))
+
+	aux := new(auxMocks.Executor)
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(
+		entity.NewSuccessResult()).Times(len(steps))
+
+	rh := NewRestHandler(aux, testLedger, jobs, logrus.New()).(*restHandler)
+	res := rh.run(context.Background(), &inst)
+	assert.True(t, res.IsAllDone())
+
+	job, err := jobs.Get("test")
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobCompleted, job.Status)
+	assert.Empty(t, job.Error)
+	assert.Len(t, job.Steps, len(steps))
+	aux.AssertExpectations(t)
+}
+
+func TestRestHandler_GetJob(t *testing.T) {
+	job := entity.Job{ID: "test", Status: entity.JobCompleted, Steps: []entity.JobStep{}}
+
+	var tests = []struct {
+		id       string
+		job      entity.Job
+		err      error
+		expected int
+	}{
+		{id: "test", job: job, expected: http.StatusOK},
+		{id: "missing", err: repository.ErrJobNotFound, expected: http.StatusNotFound},
+		{id: "broken", err: fmt.Errorf("err"), expected: http.StatusInternalServerError},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			jobs := new(serviceMocks.JobService)
+			jobs.On("Get", tt.id).Return(tt.job, tt.err).Once()
+
+			rh := NewRestHandler(nil, testLedger, jobs, logrus.New())
+			req := mux.SetURLVars(httptest.NewRequest("GET", "/jobs/"+tt.id, nil),
+				map[string]string{"id": tt.id})
+			recorder := httptest.NewRecorder()
+			rh.GetJob(recorder, req)
+
+			assert.Equal(t, tt.expected, recorder.Code)
+			if tt.err == nil {
+				var out entity.Job
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &out))
+				assert.Equal(t, tt.job.ID, out.ID)
+				assert.Equal(t, tt.job.Status, out.Status)
+			}
+			jobs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestHandler_GetJob_ScopedCredential(t *testing.T) {
+	job := entity.Job{ID: "test", Status: entity.JobRunning,
+		Instructions: json.RawMessage(`{"id":"test","orgID":"a"}`)}
+
+	var tests = []struct {
+		orgs     []string
+		expected int
+	}{
+		{orgs: []string{"a"}, expected: http.StatusOK},
+		{orgs: []string{"b"}, expected: http.StatusForbidden},
+		{orgs: []string{entity.AllOrgs}, expected: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			jobs := new(serviceMocks.JobService)
+			jobs.On("Get", "test").Return(job, nil).Once()
+			auth := new(serviceMocks.Authenticator)
+			auth.On("Authenticate", mock.Anything, mock.Anything).Return(
+				entity.Credential{Subject: "ci", Orgs: tt.orgs}, true, nil).Once()
+
+			rh := NewRestHandler(nil, testLedger, jobs, logrus.New())
+			hand := NewAuthHandler([]service.Authenticator{auth}, logrus.New()).Middleware(
+				http.HandlerFunc(rh.GetJob))
+			req := mux.SetURLVars(httptest.NewRequest("GET", "/jobs/test", nil),
+				map[string]string{"id": "test"})
+			recorder := httptest.NewRecorder()
+			hand.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expected, recorder.Code)
+			jobs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestHandler_AddCommands_Wait(t *testing.T) {
+	var tests = []struct {
+		res      entity.Result
+		delay    time.Duration
+		query    string
+		expected int
+		resType  string
+	}{
+		{res: entity.NewSuccessResult(), query: "?wait=true", expected: http.StatusOK,
+			resType: "AllDone"},
+		{res: entity.NewFatalResult("err"), query: "?wait=true",
+			expected: http.StatusInternalServerError, resType: "Fatal"},
+		{res: entity.NewSuccessResult(), delay: time.Second, query: "?wait=true&timeout=10ms",
+			expected: http.StatusGatewayTimeout, resType: "Error"},
+		{query: "?wait=maybe", expected: http.StatusBadRequest},
+		{query: "?wait=true&timeout=soon", expected: http.StatusBadRequest},
+		{query: "?wait=true&timeout=-1s", expected: http.StatusBadRequest},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			inst := command.Instructions{ID: "test", Commands: [][]command.Command{
+				{{ID: "TEST"}}, {{ID: "TEST2"}}}}
+			data, err := json.Marshal(inst)
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", "/command"+tt.query, bytes.NewReader(data))
+
+			aux := new(auxMocks.Executor)
+			aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(tt.res).Run(func(args mock.Arguments) {
+				time.Sleep(tt.delay)
+			}).Maybe()
+
+			rh := NewRestHandler(aux, testLedger, testJobs, logrus.New())
+			recorder := httptest.NewRecorder()
+			rh.AddCommands(recorder, req)
+
+			assert.Equal(t, tt.expected, recorder.Code)
+			if tt.expected == http.StatusBadRequest {
+				aux.AssertNotCalled(t, "ExecuteCommands", mock.Anything)
+				return
+			}
+			var res map[string]interface{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			assert.Equal(t, tt.resType, res["type"])
+			assert.Equal(t, "/jobs/test", recorder.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRestHandler_AddCommands_Wait_Steps(t *testing.T) {
+	steps := make([][]command.Command, maxRetries+3)
+	for i := range steps {
+		steps[i] = []command.Command{{ID: strconv.Itoa(i)}}
+	}
+	var tests = []struct {
+		res      entity.Result
+		calls    int
+		expected int
+		resType  string
+		err      string
+	}{
+		{res: entity.NewSuccessResult(), calls: len(steps), expected: http.StatusOK,
+			resType: "AllDone"},
+		{res: entity.NewErrorResult("err"), calls: maxRetries + 1,
+			expected: http.StatusInternalServerError, resType: "Fatal", err: "retries exhausted: err"},
+		{res: entity.NewErrorResult("down").WithCode(entity.CodeDaemonUnreachable),
+			calls: maxRetries + 1, expected: http.StatusServiceUnavailable, resType: "Fatal",
+			err: "retries exhausted: down"},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			data, err := json.Marshal(command.Instructions{ID: "test", Commands: steps})
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", "/command?wait=true", bytes.NewReader(data))
+
+			aux := new(auxMocks.Executor)
+			aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(tt.res).Times(tt.calls)
+
+			rh := NewRestHandler(aux, testLedger, testJobs, logrus.New())
+			recorder := httptest.NewRecorder()
+			rh.AddCommands(recorder, req)
+
+			assert.Equal(t, tt.expected, recorder.Code)
+			var res map[string]interface{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+			assert.Equal(t, tt.resType, res["type"])
+			if tt.err == "" {
+				assert.Nil(t, res["error"])
+			} else {
+				assert.Contains(t, res["error"], tt.err)
+			}
+			aux.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestHandler_AddCommands_Wait_CancelsStep(t *testing.T) {
+	data, err := json.Marshal(command.Instructions{ID: "test", Commands: [][]command.Command{
+		{{ID: "TEST"}}}})
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/command?wait=true&timeout=10ms", bytes.NewReader(data))
+
+	cancelled := make(chan bool, 1)
+	aux := new(auxMocks.Executor)
+	aux.On("ExecuteCommands", mock.Anything, mock.Anything).Return(
+		entity.NewErrorResult("cancelled")).Run(func(args mock.Arguments) {
+		select {
+		case <-args.Get(0).(context.Context).Done():
+			cancelled <- true
+		case <-time.After(5 * time.Second):
+			cancelled <- false
+		}
+	}).Once()
+
+	rh := NewRestHandler(aux, testLedger, testJobs, logrus.New())
+	recorder := httptest.NewRecorder()
+	start := time.Now()
+	rh.AddCommands(recorder, req)
+
+	assert.True(t, <-cancelled)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+	aux.AssertExpectations(t)
+}
+
+func TestResultStatusCode(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	<-expired.Done()
+
+	var tests = []struct {
+		ctx      context.Context
+		res      entity.Result
+		expected int
+	}{
+		{ctx: context.Background(), res: entity.NewAllDoneResult(), expected: http.StatusOK},
+		{ctx: expired, res: entity.NewErrorResult("test"), expected: http.StatusGatewayTimeout},
+		{
+			ctx:      context.Background(),
+			res:      entity.NewFatalResult("test").WithCode(entity.CodeInvalidPayload),
+			expected: http.StatusBadRequest,
+		},
+		{
+			ctx:      context.Background(),
+			res:      entity.NewErrorResult("test").WithCode(entity.CodeHostUnavailable),
+			expected: http.StatusServiceUnavailable,
+		},
+		{
+			ctx:      context.Background(),
+			res:      entity.NewErrorResult(context.DeadlineExceeded),
+			expected: http.StatusGatewayTimeout,
+		},
+		{
+			ctx:      context.Background(),
+			res:      entity.NewErrorResult("test"),
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Equal(t, tt.expected, resultStatusCode(tt.ctx, tt.res))
+		})
+	}
+}
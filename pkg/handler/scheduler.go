@@ -0,0 +1,41 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/whiteblock/genesis/pkg/handler/auxillary"
+
+	"github.com/sirupsen/logrus"
+)
+
+//SchedulerHandler handles the REST api calls for the scheduler of queued instructions
+type SchedulerHandler interface {
+	//Load handles the reporting of the queued and executing work of each organization
+	Load(w http.ResponseWriter, r *http.Request)
+}
+
+type schedulerHandler struct {
+	sched auxillary.Scheduler
+	log   logrus.Ext1FieldLogger
+}
+
+//NewSchedulerHandler creates a new scheduler handler
+func NewSchedulerHandler(sched auxillary.Scheduler, log logrus.Ext1FieldLogger) SchedulerHandler {
+	return &schedulerHandler{sched: sched, log: log}
+}
+
+//Load handles the reporting of the queued and executing work of each organization
+func (sh schedulerHandler) Load(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(sh.sched.Load())
+	if err != nil {
+		sh.log.Error(err)
+	}
+}
@@ -0,0 +1,38 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	auxMocks "github.com/whiteblock/genesis/mocks/pkg/handler/auxillary"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerHandler_Load(t *testing.T) {
+	expected := []entity.OrgLoad{{OrgID: "a", Queued: 3, Running: 2}}
+	sched := new(auxMocks.Scheduler)
+	sched.On("Load").Return(expected).Once()
+
+	req, err := http.NewRequest("GET", "/queue", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	NewSchedulerHandler(sched, logrus.New()).Load(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var load []entity.OrgLoad
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &load))
+	assert.Equal(t, expected, load)
+	sched.AssertExpectations(t)
+}
@@ -0,0 +1,59 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/whiteblock/genesis/pkg/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/whiteblock/definition/command"
+	util "github.com/whiteblock/utility/utils"
+)
+
+//ValidationHandler handles the REST api calls for checking instructions without executing them
+type ValidationHandler interface {
+	//Validate handles the reporting of every problem with the given instructions
+	Validate(w http.ResponseWriter, r *http.Request)
+}
+
+type validationHandler struct {
+	validation usecase.ValidationUseCase
+	log        logrus.Ext1FieldLogger
+}
+
+//NewValidationHandler creates a new validation handler
+func NewValidationHandler(validation usecase.ValidationUseCase,
+	log logrus.Ext1FieldLogger) ValidationHandler {
+	return &validationHandler{validation: validation, log: log}
+}
+
+//Validate handles the reporting of every problem with the given instructions. Instructions
+//which cannot be parsed are rejected, otherwise the problems are reported with a 200 status
+func (vh validationHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, util.LogError(err).Error(), 400)
+		return
+	}
+	var inst command.Instructions
+	err = json.Unmarshal(data, &inst)
+	if err != nil {
+		http.Error(w, util.LogError(err).Error(), 400)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(vh.validation.Validate(inst))
+	if err != nil {
+		vh.log.Error(err)
+	}
+}
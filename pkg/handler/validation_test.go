@@ -0,0 +1,56 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	usecaseMocks "github.com/whiteblock/genesis/mocks/pkg/usecase"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationHandler_Validate(t *testing.T) {
+	expected := entity.NewValidation([]entity.Problem{{Step: 1, CommandID: "a", Message: "bad"}})
+	validation := new(usecaseMocks.ValidationUseCase)
+	validation.On("Validate", mock.Anything).Return(expected).Once()
+
+	data, err := json.Marshal(testCommands)
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/command/validate", bytes.NewReader(data))
+	rr := httptest.NewRecorder()
+	NewValidationHandler(validation, logrus.New()).Validate(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res entity.Validation
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, expected, res)
+	validation.AssertExpectations(t)
+}
+
+func TestValidationHandler_Validate_Malformed(t *testing.T) {
+	for _, body := range []string{"nope", `{"orgID":"other"} x`} {
+		t.Run(body, func(t *testing.T) {
+			validation := new(usecaseMocks.ValidationUseCase)
+
+			req := httptest.NewRequest("POST", "/command/validate", bytes.NewReader([]byte(body)))
+			rr := httptest.NewRecorder()
+			NewValidationHandler(validation, logrus.New()).Validate(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			validation.AssertExpectations(t)
+		})
+	}
+}
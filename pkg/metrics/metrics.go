@@ -0,0 +1,116 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "genesis"
+
+const (
+	// ExecutorComponent is the component label of the commands being executed
+	ExecutorComponent = "executor"
+	// ConsumerComponent is the component label of the messages being handled from the command queue
+	ConsumerComponent = "consumer"
+)
+
+const (
+	// QueueConsumed is the action label of a message received from the command queue
+	QueueConsumed = "consumed"
+	// QueueRequeued is the action label of a message put straight back onto the command queue
+	QueueRequeued = "requeued"
+	// QueueRetried is the action label of a message scheduled to be retried after a delay
+	QueueRetried = "retried"
+	// QueueDeadLettered is the action label of a message sent to the dead letter queue
+	QueueDeadLettered = "dead_lettered"
+	// QueueIgnored is the action label of a message which was dropped
+	QueueIgnored = "ignored"
+	// QueueCompleted is the action label of a message whose instructions finished
+	QueueCompleted = "completed"
+)
+
+var (
+	// Commands counts the commands executed, by their order type and the type of their result
+	Commands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_total",
+		Help:      "The number of commands executed, by order type and result type.",
+	}, []string{"order", "result"})
+
+	// CommandDuration is how long commands take to execute, by their order type
+	CommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "How long commands take to execute, by order type.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+	}, []string{"order"})
+
+	// DockerRequestDuration is how long calls to the docker api take, by host, operation and
+	// whether they succeeded
+	DockerRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "docker_request_duration_seconds",
+		Help:      "How long calls to the docker api take, by host, operation and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"host", "operation", "outcome"})
+
+	// ImagePullDuration is how long image pulls take, by whether they succeeded
+	ImagePullDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_pull_duration_seconds",
+		Help:      "How long image pulls take, by outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 10),
+	}, []string{"outcome"})
+
+	// ImagePullBytes is the size of the layers downloaded by each image pull
+	ImagePullBytes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_pull_bytes",
+		Help:      "The size of the layers downloaded by each image pull.",
+		Buckets:   prometheus.ExponentialBuckets(1<<20, 4, 8),
+	})
+
+	// QueueMessages counts the messages from the command queue, by what was done with them
+	QueueMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_messages_total",
+		Help:      "The number of messages from the command queue, by action.",
+	}, []string{"action"})
+
+	// InFlight is the number of slots of the semaphores held in each component
+	InFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "in_flight",
+		Help:      "The number of semaphore slots currently held, by component.",
+	}, []string{"component"})
+
+	// FileTransferBytes is the size of each file fetched from the remote file service
+	FileTransferBytes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "file_transfer_bytes",
+		Help:      "The size of each file fetched from the remote file service.",
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+	})
+)
+
+// Outcome gets the outcome label of a call which returned err
+func Outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// ObserveDockerRequest records a call to the docker api of the given host which started at start
+func ObserveDockerRequest(host, operation string, start time.Time, err error) {
+	DockerRequestDuration.WithLabelValues(host, operation, Outcome(err)).Observe(
+		time.Since(start).Seconds())
+}
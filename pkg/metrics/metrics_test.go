@@ -0,0 +1,42 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleCount(t *testing.T, obs prometheus.Observer) uint64 {
+	metric, ok := obs.(prometheus.Metric)
+	require.True(t, ok)
+	var out dto.Metric
+	require.NoError(t, metric.Write(&out))
+	return out.GetHistogram().GetSampleCount()
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, "success", Outcome(nil))
+	assert.Equal(t, "failure", Outcome(errors.New("err")))
+}
+
+func TestObserveDockerRequest(t *testing.T) {
+	ObserveDockerRequest("10.0.0.2", "Ping", time.Now(), nil)
+	ObserveDockerRequest("10.0.0.2", "Ping", time.Now(), nil)
+	ObserveDockerRequest("10.0.0.2", "Ping", time.Now(), errors.New("err"))
+
+	assert.Equal(t, uint64(2), sampleCount(t,
+		DockerRequestDuration.WithLabelValues("10.0.0.2", "Ping", "success")))
+	assert.Equal(t, uint64(1), sampleCount(t,
+		DockerRequestDuration.WithLabelValues("10.0.0.2", "Ping", "failure")))
+}
@@ -0,0 +1,130 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/externals"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	queue "github.com/whiteblock/amqp"
+)
+
+// ErrDeadLetterNotFound is returned when there is no dead letter with the given id
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+//DeadLetterRepository provides access to the instructions which are in the dead letter queue
+type DeadLetterRepository interface {
+	//List gets all of the dead letters, leaving them in the queue
+	List() ([]entity.DeadLetter, error)
+
+	//Remove removes the dead letter with the given id from the queue, but only if
+	//fn succeeds on it
+	Remove(id string, fn func(entity.DeadLetter) error) (entity.DeadLetter, error)
+}
+
+type deadLetterRepository struct {
+	queueName string
+	repo      queue.AMQPRepository
+	log       logrus.Ext1FieldLogger
+}
+
+//NewDeadLetterRepository creates a new DeadLetterRepository for the given queue
+func NewDeadLetterRepository(
+	queueName string,
+	repo queue.AMQPRepository,
+	log logrus.Ext1FieldLogger) DeadLetterRepository {
+	return &deadLetterRepository{queueName: queueName, repo: repo, log: log}
+}
+
+func (dlr deadLetterRepository) getChannel() (externals.AMQPChannel, error) {
+	ch, err := dlr.repo.GetChannel()
+	if err != nil {
+		return nil, err
+	}
+	out, ok := ch.(externals.AMQPChannel)
+	if !ok {
+		ch.Close()
+		return nil, errors.Errorf("channel of type %T cannot fetch messages", ch)
+	}
+	return out, nil
+}
+
+// browse calls fn on each message in the queue until it returns true. The messages are not
+// acknowledged, so they return to the queue once the channel is closed.
+func (dlr deadLetterRepository) browse(ch externals.AMQPChannel,
+	fn func(amqp.Delivery, entity.DeadLetter) (bool, error)) error {
+
+	for {
+		msg, ok, err := ch.Get(dlr.queueName, false)
+		if err != nil || !ok {
+			return err
+		}
+		var letter entity.DeadLetter
+		err = json.Unmarshal(msg.Body, &letter)
+		if err != nil {
+			dlr.log.WithFields(logrus.Fields{
+				"error": err,
+				"body":  string(msg.Body)}).Warn("skipping a malformed dead letter")
+			continue
+		}
+		done, err := fn(msg, letter)
+		if done || err != nil {
+			return err
+		}
+	}
+}
+
+//List gets all of the dead letters, leaving them in the queue
+func (dlr deadLetterRepository) List() ([]entity.DeadLetter, error) {
+	ch, err := dlr.getChannel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	out := []entity.DeadLetter{}
+	err = dlr.browse(ch, func(_ amqp.Delivery, letter entity.DeadLetter) (bool, error) {
+		out = append(out, letter)
+		return false, nil
+	})
+	return out, err
+}
+
+//Remove removes the dead letter with the given id from the queue, but only if
+//fn succeeds on it
+func (dlr deadLetterRepository) Remove(id string,
+	fn func(entity.DeadLetter) error) (out entity.DeadLetter, err error) {
+
+	ch, err := dlr.getChannel()
+	if err != nil {
+		return
+	}
+	defer ch.Close()
+
+	found := false
+	err = dlr.browse(ch, func(msg amqp.Delivery, letter entity.DeadLetter) (bool, error) {
+		if letter.ID != id {
+			return false, nil
+		}
+		found = true
+		out = letter
+		err := fn(letter)
+		if err != nil {
+			return true, err
+		}
+		return true, msg.Ack(false)
+	})
+	if err == nil && !found {
+		err = ErrDeadLetterNotFound
+	}
+	return
+}
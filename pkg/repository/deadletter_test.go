@@ -0,0 +1,126 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	queueMocks "github.com/whiteblock/genesis/mocks/amqp"
+	externalsMock "github.com/whiteblock/genesis/mocks/pkg/externals"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAcknowledger struct {
+	acked []uint64
+}
+
+func (ta *testAcknowledger) Ack(tag uint64, multiple bool) error {
+	ta.acked = append(ta.acked, tag)
+	return nil
+}
+
+func (ta *testAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	return nil
+}
+
+func (ta *testAcknowledger) Reject(tag uint64, requeue bool) error {
+	return nil
+}
+
+func mintDeadLetters(t *testing.T, ack amqp.Acknowledger, ids ...string) []amqp.Delivery {
+	out := []amqp.Delivery{}
+	for i, id := range ids {
+		body, err := json.Marshal(entity.DeadLetter{ID: id})
+		require.NoError(t, err)
+		out = append(out, amqp.Delivery{Body: body, DeliveryTag: uint64(i), Acknowledger: ack})
+	}
+	return out
+}
+
+func mockQueue(msgs []amqp.Delivery) (*queueMocks.AMQPRepository, *externalsMock.AMQPChannel) {
+	ch := new(externalsMock.AMQPChannel)
+	for _, msg := range msgs {
+		ch.On("Get", "dlq", false).Return(msg, true, nil).Once()
+	}
+	ch.On("Get", "dlq", false).Return(amqp.Delivery{}, false, nil).Maybe()
+	ch.On("Close").Return(nil).Once()
+
+	repo := new(queueMocks.AMQPRepository)
+	repo.On("GetChannel").Return(ch, nil).Once()
+	return repo, ch
+}
+
+func TestDeadLetterRepository_List(t *testing.T) {
+	msgs := mintDeadLetters(t, nil, "1", "2")
+	msgs = append(msgs, amqp.Delivery{Body: []byte("malformed")})
+	repo, ch := mockQueue(msgs)
+
+	letters, err := NewDeadLetterRepository("dlq", repo, logrus.New()).List()
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.Equal(t, "1", letters[0].ID)
+	assert.Equal(t, "2", letters[1].ID)
+
+	repo.AssertExpectations(t)
+	ch.AssertExpectations(t)
+}
+
+func TestDeadLetterRepository_List_Failure(t *testing.T) {
+	repo := new(queueMocks.AMQPRepository)
+	repo.On("GetChannel").Return(nil, fmt.Errorf("err")).Once()
+
+	_, err := NewDeadLetterRepository("dlq", repo, logrus.New()).List()
+	assert.Error(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestDeadLetterRepository_Remove(t *testing.T) {
+	ack := &testAcknowledger{}
+	repo, ch := mockQueue(mintDeadLetters(t, ack, "1", "2", "3"))
+
+	called := false
+	letter, err := NewDeadLetterRepository("dlq", repo, logrus.New()).Remove("2",
+		func(letter entity.DeadLetter) error {
+			called = true
+			assert.Equal(t, "2", letter.ID)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.True(t, called)
+	assert.Equal(t, "2", letter.ID)
+	assert.Equal(t, []uint64{1}, ack.acked)
+
+	repo.AssertExpectations(t)
+	ch.AssertCalled(t, "Close")
+}
+
+func TestDeadLetterRepository_Remove_Failure(t *testing.T) {
+	ack := &testAcknowledger{}
+	repo, _ := mockQueue(mintDeadLetters(t, ack, "1"))
+
+	_, err := NewDeadLetterRepository("dlq", repo, logrus.New()).Remove("1",
+		func(letter entity.DeadLetter) error {
+			return fmt.Errorf("err")
+		})
+	assert.Error(t, err)
+	assert.Len(t, ack.acked, 0)
+
+	repo, _ = mockQueue(mintDeadLetters(t, ack, "1"))
+	_, err = NewDeadLetterRepository("dlq", repo, logrus.New()).Remove("2",
+		func(letter entity.DeadLetter) error {
+			t.Fatal("should not be called")
+			return nil
+		})
+	assert.Equal(t, ErrDeadLetterNotFound, err)
+}
@@ -8,13 +8,18 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/metrics"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
@@ -26,8 +31,13 @@ import (
 //DockerRepository provides extra functions for docker service, which could be placed inside of docker
 //service, but would make the testing more difficult
 type DockerRepository interface {
-	//WithTLSClientConfig provides the opt for TLS auth
-	WithTLSClientConfig(cacertPath, certPath, keyPath string) client.Opt
+	//WithTLSClientConfig provides the opt for TLS auth, verifying the daemon's certificate
+	//against the CA and server name of the host unless it is configured not to
+	WithTLSClientConfig(host entity.DockerHost) client.Opt
+
+	//WithSSHDialer provides the opt for connecting to a daemon over ssh, given an endpoint
+	//such as ssh://user@host:22
+	WithSSHDialer(endpoint string) client.Opt
 
 	//EnsureImagePulled checks if the docker host contains an image and pulls it if it does not
 	EnsureImagePulled(ctx context.Context, cli entity.Client,
@@ -55,19 +65,20 @@ func NewDockerRepository(log logrus.Ext1FieldLogger) DockerRepository {
 	return &dockerRepository{log: log}
 }
 
-func (da dockerRepository) WithTLSClientConfig(cacertPath, certPath, keyPath string) client.Opt {
+func (da dockerRepository) WithTLSClientConfig(host entity.DockerHost) client.Opt {
 	return func(c *client.Client) error {
 		opts := tlsconfig.Options{
-			CAFile:             cacertPath,
-			CertFile:           certPath,
-			KeyFile:            keyPath,
+			CAFile:             host.CACertPath,
+			CertFile:           host.CertPath,
+			KeyFile:            host.KeyPath,
 			ExclusiveRootPools: true,
-			InsecureSkipVerify: true,
+			InsecureSkipVerify: host.GetInsecureSkipVerify(),
 		}
 		config, err := tlsconfig.Client(opts)
 		if err != nil {
 			return errors.Wrap(err, "failed to create tls config")
 		}
+		config.ServerName = host.ServerName
 		if transport, ok := c.HTTPClient().Transport.(*http.Transport); ok {
 			transport.TLSClientConfig = config
 			return nil
@@ -76,6 +87,23 @@ func (da dockerRepository) WithTLSClientConfig(cacertPath, certPath, keyPath str
 	}
 }
 
+func (da dockerRepository) WithSSHDialer(endpoint string) client.Opt {
+	return func(c *client.Client) error {
+		args, err := sshArgs(endpoint)
+		if err != nil {
+			return err
+		}
+		err = client.WithHost(sshDaemonHost)(c)
+		if err != nil {
+			return err
+		}
+		return client.WithDialContext(func(context.Context, string, string) (net.Conn, error) {
+			da.log.WithField("endpoint", endpoint).Trace("dialing a docker daemon over ssh")
+			return newCommandConn(exec.Command(sshCommand, args...))
+		})(c)
+	}
+}
+
 //HostHasImage returns true if the docker host has an image matching what was given
 func (da dockerRepository) HostHasImage(ctx context.Context, cli entity.Client, image string) (bool, error) {
 	imgs, err := cli.ImageList(ctx, types.ImageListOptions{All: false})
@@ -105,18 +133,64 @@ func (da dockerRepository) EnsureImagePulled(ctx context.Context, cli entity.Cli
 		return err
 	}
 
+	start := time.Now()
 	rd, err := cli.ImagePull(ctx, imageName, types.ImagePullOptions{
 		Platform:     "Linux",
 		RegistryAuth: auth,
 	})
 	if err != nil {
+		metrics.ImagePullDuration.WithLabelValues(metrics.Outcome(err)).Observe(time.Since(start).Seconds())
 		return err
 	}
 	defer rd.Close()
-	_, err = ioutil.ReadAll(rd)
+	size, err := pulledBytes(rd)
+	metrics.ImagePullDuration.WithLabelValues(metrics.Outcome(err)).Observe(time.Since(start).Seconds())
+	if err == nil {
+		metrics.ImagePullBytes.Observe(float64(size))
+	}
 	return err
 }
 
+//pullProgress is the part of a progress message of an image pull which holds its size
+type pullProgress struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Total int64 `json:"total"`
+	} `json:"progressDetail"`
+}
+
+//pulledBytes reads the progress of an image pull until it finishes, adding up the sizes of the
+//layers which were downloaded. If the progress cannot be parsed, the rest of it is still read.
+func pulledBytes(rd io.Reader) (int64, error) {
+	sizes := map[string]int64{}
+	dec := json.NewDecoder(rd)
+	for {
+		var msg pullProgress
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			break
+		}
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+			_, err = io.Copy(ioutil.Discard, io.MultiReader(dec.Buffered(), rd))
+			return 0, err
+		}
+		if err != nil {
+			return 0, err
+		}
+		if msg.Status == "Downloading" && msg.ProgressDetail.Total > sizes[msg.ID] {
+			sizes[msg.ID] = msg.ProgressDetail.Total
+		}
+	}
+	var total int64
+	for _, size := range sizes {
+		total += size
+	}
+	return total, nil
+}
+
 //GetNetworkByName attempts to find a network with the given name and return information on it.
 func (da dockerRepository) GetNetworkByName(ctx context.Context, cli entity.Client,
 	networkName string) (types.NetworkResource, error) {
@@ -199,7 +273,7 @@ func (da dockerRepository) Exec(ctx context.Context, cli entity.Client,
 	if err == nil {
 		return nil
 	}
-	if strings.Contains(err.Error(), "connect to the Docker daemon") { //bypass to help get out the dead things
+	if entity.ClassifyError(err) == entity.CodeDaemonUnreachable { //bypass to help get out the dead things
 		return err
 	}
 
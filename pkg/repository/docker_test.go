@@ -9,12 +9,16 @@ package repository
 import (
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 
 	entityMock "github.com/whiteblock/genesis/mocks/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/entity"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -205,3 +209,54 @@ func TestDockerRepository_GetContainerByName_Failure(t *testing.T) {
 
 	cli.AssertExpectations(t)
 }
+
+func TestDockerRepository_WithTLSClientConfig(t *testing.T) {
+	for _, insecure := range []bool{true, false} {
+		repo := NewDockerRepository(logrus.New())
+		cli, err := client.NewClientWithOpts(client.WithHost("tcp://10.0.0.2:2376"),
+			repo.WithTLSClientConfig(entity.DockerHost{ServerName: "10.0.0.2", InsecureSkipVerify: &insecure}))
+		require.NoError(t, err)
+		conf := cli.HTTPClient().Transport.(*http.Transport).TLSClientConfig
+		assert.Equal(t, "10.0.0.2", conf.ServerName)
+		assert.Equal(t, insecure, conf.InsecureSkipVerify)
+	}
+}
+
+func TestDockerRepository_WithSSHDialer(t *testing.T) {
+	repo := NewDockerRepository(logrus.New())
+	cli, err := client.NewClientWithOpts(repo.WithSSHDialer("ssh://genesis@10.0.0.2"))
+	require.NoError(t, err)
+	assert.Equal(t, sshDaemonHost, cli.DaemonHost())
+
+	_, err = client.NewClientWithOpts(repo.WithSSHDialer("tcp://10.0.0.2"))
+	assert.Error(t, err)
+}
+
+func TestPulledBytes(t *testing.T) {
+	var tests = []struct {
+		progress string
+		expected int64
+	}{
+		{progress: "", expected: 0},
+		{progress: "TTTTTTTT", expected: 0},
+		{
+			progress: `{"status":"Pulling fs layer","id":"a"}
+{"status":"Downloading","progressDetail":{"current":10,"total":100},"id":"a"}
+{"status":"Downloading","progressDetail":{"current":100,"total":100},"id":"a"}
+{"status":"Downloading","progressDetail":{"current":5,"total":50},"id":"b"}
+{"status":"Extracting","progressDetail":{"current":5,"total":100},"id":"a"}
+{"status":"Pull complete","id":"a"}`,
+			expected: 150,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			rd := strings.NewReader(tt.progress)
+			size, err := pulledBytes(rd)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+			assert.Equal(t, 0, rd.Len())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrJobNotFound is returned when there is no job with the given id
+var ErrJobNotFound = errors.New("job not found")
+
+var jobBucket = []byte("jobs")
+
+//JobRepository provides durable storage of the jobs submitted over the REST api
+type JobRepository interface {
+	//Get gets the job with the given id
+	Get(id string) (entity.Job, error)
+
+	//Put creates or replaces the given job
+	Put(job entity.Job) error
+
+	//List gets all of the jobs
+	List() ([]entity.Job, error)
+
+	//Prune removes all of the finished jobs which were last updated before the given time,
+	//returning how many were removed
+	Prune(before time.Time) (int, error)
+}
+
+type jobRepository struct {
+	db  *bolt.DB
+	log logrus.Ext1FieldLogger
+}
+
+//NewJobRepository creates a new JobRepository, which is stored in the given database
+func NewJobRepository(db *bolt.DB, log logrus.Ext1FieldLogger) (JobRepository, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(jobBucket)
+		return err
+	})
+	return &jobRepository{db: db, log: log}, err
+}
+
+//Get gets the job with the given id
+func (jr jobRepository) Get(id string) (out entity.Job, err error) {
+	err = jr.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(jobBucket).Get([]byte(id))
+		if data == nil {
+			return ErrJobNotFound
+		}
+		return json.Unmarshal(data, &out)
+	})
+	return
+}
+
+//Put creates or replaces the given job
+func (jr jobRepository) Put(job entity.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return jr.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobBucket).Put([]byte(job.ID), data)
+	})
+}
+
+//List gets all of the jobs
+func (jr jobRepository) List() (out []entity.Job, err error) {
+	out = []entity.Job{}
+	err = jr.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobBucket).ForEach(func(k, v []byte) error {
+			var job entity.Job
+			err := json.Unmarshal(v, &job)
+			if err != nil {
+				jr.log.WithFields(logrus.Fields{
+					"id":  string(k),
+					"err": err}).Error("skipping a malformed job")
+				return nil
+			}
+			out = append(out, job)
+			return nil
+		})
+	})
+	return
+}
+
+//Prune removes all of the finished jobs which were last updated before the given time,
+//returning how many were removed
+func (jr jobRepository) Prune(before time.Time) (removed int, err error) {
+	jobs, err := jr.List()
+	if err != nil {
+		return 0, err
+	}
+	err = jr.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(jobBucket)
+		for _, job := range jobs {
+			if !job.Status.IsFinished() || !job.Updated.Before(before) {
+				continue
+			}
+			err := bucket.Delete([]byte(job.ID))
+			if err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return
+}
@@ -0,0 +1,78 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func testJobs(t *testing.T) JobRepository {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := NewJobRepository(db, logrus.New())
+	require.NoError(t, err)
+	return repo
+}
+
+func TestJobRepository_PutGet(t *testing.T) {
+	repo := testJobs(t)
+
+	_, err := repo.Get("test")
+	assert.Equal(t, ErrJobNotFound, err)
+
+	job := entity.Job{
+		ID:           "test",
+		Status:       entity.JobRunning,
+		Instructions: []byte(`{"id":"test"}`),
+	}
+	require.NoError(t, repo.Put(job))
+
+	res, err := repo.Get("test")
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, res.ID)
+	assert.Equal(t, job.Status, res.Status)
+	assert.JSONEq(t, string(job.Instructions), string(res.Instructions))
+
+	jobs, err := repo.List()
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestJobRepository_Prune(t *testing.T) {
+	repo := testJobs(t)
+	old := time.Now().Add(-time.Hour)
+	jobs := []entity.Job{
+		{ID: "1", Status: entity.JobCompleted, Updated: old},
+		{ID: "2", Status: entity.JobRunning, Updated: old},
+		{ID: "3", Status: entity.JobFailed, Updated: time.Now()},
+	}
+	for _, job := range jobs {
+		require.NoError(t, repo.Put(job))
+	}
+
+	removed, err := repo.Prune(time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	_, err = repo.Get("1")
+	assert.Equal(t, ErrJobNotFound, err)
+
+	left, err := repo.List()
+	require.NoError(t, err)
+	assert.Len(t, left, 2)
+}
@@ -0,0 +1,117 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+var ledgerBucket = []byte("ledger")
+
+//LedgerRepository provides durable storage of the commands which have completed
+type LedgerRepository interface {
+	//Get gets the entry for the given command, returning false if it has not completed
+	Get(key entity.LedgerKey) (entity.LedgerEntry, bool, error)
+
+	//Put records the given entry
+	Put(entry entity.LedgerEntry) error
+
+	//Forget removes all of the entries of the given instructions
+	Forget(testID string) error
+
+	//Prune removes all of the entries which completed before the given time, returning
+	//how many were removed
+	Prune(before time.Time) (int, error)
+}
+
+type ledgerRepository struct {
+	db  *bolt.DB
+	log logrus.Ext1FieldLogger
+}
+
+//NewLedgerRepository creates a new LedgerRepository, which is stored in the given database
+func NewLedgerRepository(db *bolt.DB, log logrus.Ext1FieldLogger) (LedgerRepository, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(ledgerBucket)
+		return err
+	})
+	return &ledgerRepository{db: db, log: log}, err
+}
+
+//Get gets the entry for the given command, returning false if it has not completed
+func (lr ledgerRepository) Get(key entity.LedgerKey) (out entity.LedgerEntry, found bool, err error) {
+	err = lr.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(ledgerBucket).Get([]byte(key.String()))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &out)
+	})
+	return
+}
+
+//Put records the given entry
+func (lr ledgerRepository) Put(entry entity.LedgerEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return lr.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(ledgerBucket).Put([]byte(entry.Key.String()), data)
+	})
+}
+
+//Forget removes all of the entries of the given instructions
+func (lr ledgerRepository) Forget(testID string) error {
+	prefix := []byte(testID + "/")
+	return lr.db.Update(func(tx *bolt.Tx) error {
+		cur := tx.Bucket(ledgerBucket).Cursor()
+		for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Seek(prefix) {
+			err := cur.Delete()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//Prune removes all of the entries which completed before the given time, returning
+//how many were removed
+func (lr ledgerRepository) Prune(before time.Time) (removed int, err error) {
+	err = lr.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(ledgerBucket)
+		stale := [][]byte{}
+		err := bucket.ForEach(func(k, v []byte) error {
+			var entry entity.LedgerEntry
+			if json.Unmarshal(v, &entry) != nil || entry.Timestamp.Before(before) {
+				stale = append(stale, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range stale {
+			err = bucket.Delete(k)
+			if err != nil {
+				return err
+			}
+		}
+		removed = len(stale)
+		return nil
+	})
+	return
+}
@@ -0,0 +1,96 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func testLedger(t *testing.T) LedgerRepository {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := NewLedgerRepository(db, logrus.New())
+	require.NoError(t, err)
+	return repo
+}
+
+func TestLedgerRepository_PutGet(t *testing.T) {
+	repo := testLedger(t)
+	key := entity.LedgerKey{TestID: "test", Round: 2, CommandID: "cmd"}
+
+	_, found, err := repo.Get(key)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	entry := entity.LedgerEntry{Key: key, Timestamp: time.Now().UTC(),
+		Result: entity.NewSuccessResult().InjectMeta(map[string]interface{}{"id": "abc"})}
+	require.NoError(t, repo.Put(entry))
+
+	res, found, err := repo.Get(key)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, entry.Key, res.Key)
+	assert.Equal(t, entry.Result.Type, res.Result.Type)
+	assert.Equal(t, "abc", res.Result.Meta["id"])
+	assert.True(t, entry.Timestamp.Equal(res.Timestamp))
+
+	key.Round = 3
+	_, found, err = repo.Get(key)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestLedgerRepository_Forget(t *testing.T) {
+	repo := testLedger(t)
+	keys := []entity.LedgerKey{
+		{TestID: "test", Round: 0, CommandID: "1"},
+		{TestID: "test", Round: 1, CommandID: "2"},
+		{TestID: "test2", Round: 0, CommandID: "3"},
+	}
+	for _, key := range keys {
+		require.NoError(t, repo.Put(entity.LedgerEntry{Key: key, Timestamp: time.Now()}))
+	}
+
+	require.NoError(t, repo.Forget("test"))
+
+	for i, key := range keys {
+		_, found, err := repo.Get(key)
+		require.NoError(t, err)
+		assert.Equal(t, i == 2, found)
+	}
+}
+
+func TestLedgerRepository_Prune(t *testing.T) {
+	repo := testLedger(t)
+	old := entity.LedgerKey{TestID: "test", CommandID: "old"}
+	recent := entity.LedgerKey{TestID: "test", CommandID: "recent"}
+	require.NoError(t, repo.Put(entity.LedgerEntry{Key: old, Timestamp: time.Now().Add(-time.Hour)}))
+	require.NoError(t, repo.Put(entity.LedgerEntry{Key: recent, Timestamp: time.Now()}))
+
+	removed, err := repo.Prune(time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	_, found, err := repo.Get(old)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, found, err = repo.Get(recent)
+	require.NoError(t, err)
+	assert.True(t, found)
+}
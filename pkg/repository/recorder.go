@@ -0,0 +1,367 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/api/types/volume"
+)
+
+//Recorder creates docker clients which record the calls that change a docker host rather
+//than making them. Calls which only read from a docker host are answered as if the host were
+//empty, apart from the networks created through the recorder.
+type Recorder interface {
+	//Client creates a recording client for the given host
+	Client(host string) entity.Client
+
+	//Calls removes and returns every call recorded so far, in the order they were made
+	Calls() []entity.PlanCall
+}
+
+type recorder struct {
+	mu       sync.Mutex
+	calls    []entity.PlanCall
+	networks map[string]types.NetworkResource
+	execs    int
+}
+
+//NewRecorder creates a new Recorder
+func NewRecorder() Recorder {
+	return &recorder{networks: map[string]types.NetworkResource{}}
+}
+
+//Client creates a recording client for the given host
+func (rec *recorder) Client(host string) entity.Client {
+	return &recordingClient{host: host, rec: rec}
+}
+
+//Calls removes and returns every call recorded so far, in the order they were made
+func (rec *recorder) Calls() []entity.PlanCall {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	out := rec.calls
+	rec.calls = nil
+	return out
+}
+
+func (rec *recorder) record(call entity.PlanCall) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.calls = append(rec.calls, call)
+}
+
+type recordingClient struct {
+	host string
+	rec  *recorder
+}
+
+func (rc recordingClient) record(method string, args map[string]interface{},
+	format string, a ...interface{}) {
+	rc.rec.record(entity.PlanCall{
+		Host:    rc.host,
+		Method:  method,
+		Summary: fmt.Sprintf(format, a...),
+		Args:    args,
+	})
+}
+
+func (rc recordingClient) Close() error {
+	return nil
+}
+
+func (rc recordingClient) ClientVersion() string {
+	return ""
+}
+
+func (rc recordingClient) ContainerAttach(ctx context.Context, container string,
+	options types.ContainerAttachOptions) (types.HijackedResponse, error) {
+	rc.record("ContainerAttach", nil, "attach to container %q", container)
+	return types.HijackedResponse{}, nil
+}
+
+func (rc recordingClient) ContainerCreate(ctx context.Context, config *container.Config,
+	hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig,
+	containerName string) (container.ContainerCreateCreatedBody, error) {
+
+	summary := fmt.Sprintf("create container %q from image %q", containerName, config.Image)
+	if networkingConfig != nil {
+		for name, endpoint := range networkingConfig.EndpointsConfig {
+			summary += fmt.Sprintf(" on network %q", name)
+			if endpoint != nil && endpoint.IPAddress != "" {
+				summary += fmt.Sprintf(" with ip %q", endpoint.IPAddress)
+			}
+			if endpoint != nil && endpoint.IPAMConfig != nil && endpoint.IPAMConfig.IPv6Address != "" {
+				summary += fmt.Sprintf(" with ipv6 %q", endpoint.IPAMConfig.IPv6Address)
+			}
+		}
+	}
+	if len(config.Entrypoint) > 0 {
+		summary += fmt.Sprintf(" running %q", strings.Join(config.Entrypoint, " "))
+	}
+	rc.record("ContainerCreate", map[string]interface{}{
+		"name":             containerName,
+		"config":           config,
+		"hostConfig":       hostConfig,
+		"networkingConfig": networkingConfig,
+	}, "%s", summary)
+	return container.ContainerCreateCreatedBody{ID: containerName}, nil
+}
+
+func (rc recordingClient) ContainerExecAttach(ctx context.Context, execID string,
+	config types.ExecStartCheck) (types.HijackedResponse, error) {
+	return types.HijackedResponse{}, nil
+}
+
+func (rc recordingClient) ContainerExecCreate(ctx context.Context, container string,
+	config types.ExecConfig) (types.IDResponse, error) {
+
+	rc.rec.mu.Lock()
+	rc.rec.execs++
+	id := fmt.Sprintf("exec-%d", rc.rec.execs)
+	rc.rec.mu.Unlock()
+
+	rc.record("ContainerExecCreate", map[string]interface{}{
+		"container": container,
+		"config":    config,
+	}, "run %q in container %q", strings.Join(config.Cmd, " "), container)
+	return types.IDResponse{ID: id}, nil
+}
+
+func (rc recordingClient) ContainerExecInspect(ctx context.Context,
+	execID string) (types.ContainerExecInspect, error) {
+	return types.ContainerExecInspect{ExecID: execID}, nil
+}
+
+func (rc recordingClient) ContainerExecStart(ctx context.Context, execID string,
+	config types.ExecStartCheck) error {
+	return nil
+}
+
+func (rc recordingClient) ContainerInspect(ctx context.Context,
+	containerID string) (types.ContainerJSON, error) {
+	return types.ContainerJSON{}, fmt.Errorf("No such container: %s", containerID)
+}
+
+func (rc recordingClient) ContainerList(ctx context.Context,
+	options types.ContainerListOptions) ([]types.Container, error) {
+	return []types.Container{}, nil
+}
+
+func (rc recordingClient) ContainerRemove(ctx context.Context, containerID string,
+	options types.ContainerRemoveOptions) error {
+	rc.record("ContainerRemove", map[string]interface{}{
+		"container": containerID,
+		"options":   options,
+	}, "remove container %q", containerID)
+	return nil
+}
+
+func (rc recordingClient) ContainerStart(ctx context.Context, containerID string,
+	options types.ContainerStartOptions) error {
+	rc.record("ContainerStart", nil, "start container %q", containerID)
+	return nil
+}
+
+func (rc recordingClient) ContainerStatPath(ctx context.Context,
+	containerID, path string) (types.ContainerPathStat, error) {
+	return types.ContainerPathStat{}, fmt.Errorf("No such container:path: %s:%s", containerID, path)
+}
+
+func (rc recordingClient) CopyToContainer(ctx context.Context, containerID, dstPath string,
+	content io.Reader, options types.CopyToContainerOptions) error {
+	rc.record("CopyToContainer", map[string]interface{}{
+		"container":   containerID,
+		"destination": dstPath,
+	}, "copy a file into %q in container %q", dstPath, containerID)
+	return nil
+}
+
+func (rc recordingClient) DaemonHost() string {
+	return rc.host
+}
+
+func (rc recordingClient) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	return types.DiskUsage{}, nil
+}
+
+func (rc recordingClient) HTTPClient() *http.Client {
+	return &http.Client{}
+}
+
+func (rc recordingClient) ImageList(ctx context.Context,
+	options types.ImageListOptions) ([]types.ImageSummary, error) {
+	return []types.ImageSummary{}, nil
+}
+
+func (rc recordingClient) ImageLoad(ctx context.Context, input io.Reader,
+	quiet bool) (types.ImageLoadResponse, error) {
+	rc.record("ImageLoad", nil, "load an image")
+	return types.ImageLoadResponse{Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+}
+
+func (rc recordingClient) ImagePull(ctx context.Context, refStr string,
+	options types.ImagePullOptions) (io.ReadCloser, error) {
+	rc.record("ImagePull", map[string]interface{}{
+		"image":     refStr,
+		"platform":  options.Platform,
+		"usingAuth": options.RegistryAuth != "",
+	}, "pull image %q if it is not present", refStr)
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
+
+func (rc recordingClient) NegotiateAPIVersion(ctx context.Context) {}
+
+func (rc recordingClient) NetworkCreate(ctx context.Context, name string,
+	options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+
+	summary := fmt.Sprintf("create %s network %q", options.Driver, name)
+	if options.Internal {
+		summary = fmt.Sprintf("create internal %s network %q", options.Driver, name)
+	}
+	if parent := options.Options["parent"]; parent != "" {
+		summary += fmt.Sprintf(" on %q", parent)
+	}
+	if options.IPAM != nil {
+		for _, conf := range options.IPAM.Config {
+			summary += fmt.Sprintf(" with subnet %q and gateway %q", conf.Subnet, conf.Gateway)
+		}
+	}
+	rc.record("NetworkCreate", map[string]interface{}{
+		"name":    name,
+		"options": options,
+	}, "%s", summary)
+
+	net := types.NetworkResource{
+		Name:    name,
+		ID:      name,
+		Driver:  options.Driver,
+		Scope:   options.Scope,
+		Options: options.Options,
+		Labels:  options.Labels,
+	}
+	if options.IPAM != nil {
+		net.IPAM = *options.IPAM
+	}
+	rc.rec.mu.Lock()
+	rc.rec.networks[name] = net
+	rc.rec.mu.Unlock()
+	return types.NetworkCreateResponse{ID: name}, nil
+}
+
+func (rc recordingClient) NetworkConnect(ctx context.Context, networkID, containerID string,
+	config *network.EndpointSettings) error {
+
+	summary := fmt.Sprintf("connect container %q to network %q", containerID, networkID)
+	if config != nil && config.IPAMConfig != nil && config.IPAMConfig.IPv4Address != "" {
+		summary += fmt.Sprintf(" with ip %q", config.IPAMConfig.IPv4Address)
+	}
+	if config != nil && config.IPAMConfig != nil && config.IPAMConfig.IPv6Address != "" {
+		summary += fmt.Sprintf(" with ipv6 %q", config.IPAMConfig.IPv6Address)
+	}
+	rc.record("NetworkConnect", map[string]interface{}{
+		"network":   networkID,
+		"container": containerID,
+		"config":    config,
+	}, "%s", summary)
+	return nil
+}
+
+func (rc recordingClient) NetworkDisconnect(ctx context.Context, networkID, containerID string,
+	force bool) error {
+	rc.record("NetworkDisconnect", nil, "disconnect container %q from network %q",
+		containerID, networkID)
+	return nil
+}
+
+func (rc recordingClient) NetworkInspect(ctx context.Context, networkID string,
+	options types.NetworkInspectOptions) (types.NetworkResource, error) {
+	rc.rec.mu.Lock()
+	defer rc.rec.mu.Unlock()
+	net, ok := rc.rec.networks[networkID]
+	if !ok {
+		return types.NetworkResource{}, fmt.Errorf("network %s not found", networkID)
+	}
+	return net, nil
+}
+
+func (rc recordingClient) NetworkRemove(ctx context.Context, networkID string) error {
+	rc.record("NetworkRemove", nil, "remove network %q", networkID)
+	rc.rec.mu.Lock()
+	delete(rc.rec.networks, networkID)
+	rc.rec.mu.Unlock()
+	return nil
+}
+
+func (rc recordingClient) NetworkList(ctx context.Context,
+	options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	rc.rec.mu.Lock()
+	defer rc.rec.mu.Unlock()
+	out := []types.NetworkResource{}
+	for _, net := range rc.rec.networks {
+		out = append(out, net)
+	}
+	return out, nil
+}
+
+func (rc recordingClient) Ping(ctx context.Context) (types.Ping, error) {
+	return types.Ping{}, nil
+}
+
+func (rc recordingClient) ServerVersion(ctx context.Context) (types.Version, error) {
+	return types.Version{}, nil
+}
+
+func (rc recordingClient) SwarmInit(ctx context.Context, req swarm.InitRequest) (string, error) {
+	rc.record("SwarmInit", map[string]interface{}{"request": req},
+		"initialize a swarm advertised at %s", req.AdvertiseAddr)
+	return "", nil
+}
+
+func (rc recordingClient) SwarmJoin(ctx context.Context, req swarm.JoinRequest) error {
+	rc.record("SwarmJoin", map[string]interface{}{"request": req},
+		"join the swarm at %s", strings.Join(req.RemoteAddrs, ", "))
+	return nil
+}
+
+func (rc recordingClient) SwarmInspect(ctx context.Context) (swarm.Swarm, error) {
+	return swarm.Swarm{JoinTokens: swarm.JoinTokens{Worker: "<worker token>"}}, nil
+}
+
+func (rc recordingClient) VolumeCreate(ctx context.Context,
+	options volume.VolumeCreateBody) (types.Volume, error) {
+
+	summary := fmt.Sprintf("create volume %q", options.Name)
+	if options.Driver != "" {
+		summary += fmt.Sprintf(" with the %s driver", options.Driver)
+	}
+	rc.record("VolumeCreate", map[string]interface{}{"options": options}, "%s", summary)
+	return types.Volume{Name: options.Name, Driver: options.Driver}, nil
+}
+
+func (rc recordingClient) VolumeList(ctx context.Context,
+	filter filters.Args) (volume.VolumeListOKBody, error) {
+	return volume.VolumeListOKBody{}, nil
+}
+
+func (rc recordingClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	rc.record("VolumeRemove", nil, "remove volume %q", volumeID)
+	return nil
+}
@@ -0,0 +1,61 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	cli := rec.Client("10.0.0.1")
+	ctx := context.Background()
+
+	_, err := cli.NetworkCreate(ctx, "net", types.NetworkCreate{
+		Driver: "bridge",
+		IPAM:   &network.IPAM{Config: []network.IPAMConfig{{Subnet: "10.1.0.0/16"}}},
+	})
+	require.NoError(t, err)
+	_, err = cli.ContainerCreate(ctx, &container.Config{Image: "img"}, &container.HostConfig{},
+		&network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{
+			"net": {IPAddress: "10.1.0.2"}}}, "cntr")
+	require.NoError(t, err)
+	_, err = cli.ContainerExecCreate(ctx, "cntr", types.ExecConfig{Cmd: []string{"echo", "hi"}})
+	require.NoError(t, err)
+
+	_, err = cli.ContainerInspect(ctx, "cntr")
+	assert.Error(t, err, "containers should appear to have exited")
+	imgs, err := cli.ImageList(ctx, types.ImageListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, imgs)
+
+	nets, err := rec.Client("10.0.0.2").NetworkList(ctx, types.NetworkListOptions{})
+	require.NoError(t, err)
+	require.Len(t, nets, 1)
+	assert.Equal(t, "10.1.0.0/16", nets[0].IPAM.Config[0].Subnet)
+
+	calls := rec.Calls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, "NetworkCreate", calls[0].Method)
+	assert.Equal(t, "10.0.0.1", calls[0].Host)
+	assert.Equal(t, `create container "cntr" from image "img" on network "net" with ip "10.1.0.2"`,
+		calls[1].Summary)
+	assert.Equal(t, `run "echo hi" in container "cntr"`, calls[2].Summary)
+	assert.Empty(t, rec.Calls())
+
+	require.NoError(t, cli.NetworkRemove(ctx, "net"))
+	nets, err = cli.NetworkList(ctx, types.NetworkListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, nets)
+}
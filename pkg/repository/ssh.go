@@ -0,0 +1,120 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sshCommand = "ssh"
+	//sshDaemonHost is a placeholder for the daemon host, as the connection is made by the dialer
+	sshDaemonHost = "http://docker.example.com"
+)
+
+//sshArgs gets the arguments to ssh which connect to the docker daemon of the given endpoint, using
+//the daemon's own dial-stdio command, as the docker cli does
+func sshArgs(endpoint string) ([]string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ssh" {
+		return nil, fmt.Errorf(`expected an ssh endpoint, got "%s"`, endpoint)
+	}
+	if u.Hostname() == "" || strings.HasPrefix(u.Hostname(), "-") {
+		return nil, fmt.Errorf(`invalid ssh host in "%s"`, endpoint)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return nil, fmt.Errorf(`unexpected path or query in "%s"`, endpoint)
+	}
+	out := []string{}
+	if u.User != nil {
+		out = append(out, "-l", u.User.Username())
+	}
+	if u.Port() != "" {
+		out = append(out, "-p", u.Port())
+	}
+	return append(out, "--", u.Hostname(), "docker", "system", "dial-stdio"), nil
+}
+
+type commandAddr struct{}
+
+func (commandAddr) Network() string { return "command" }
+func (commandAddr) String() string  { return "command" }
+
+//commandConn is a connection over the stdin and stdout of a command
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+func newCommandConn(cmd *exec.Cmd) (net.Conn, error) {
+	out := &commandConn{cmd: cmd}
+	var err error
+	out.stdin, err = cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out.stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = &out.stderr
+	return out, cmd.Start()
+}
+
+//wait waits for the command to exit, after which its stderr may be read
+func (cc *commandConn) wait() error {
+	cc.waitOnce.Do(func() {
+		cc.waitErr = cc.cmd.Wait()
+	})
+	return cc.waitErr
+}
+
+//Read reads from the stdout of the command. Once the command exits, the error it exited with is
+//returned along with its stderr.
+func (cc *commandConn) Read(p []byte) (int, error) {
+	n, err := cc.stdout.Read(p)
+	if err != io.EOF {
+		return n, err
+	}
+	if waitErr := cc.wait(); waitErr != nil {
+		return n, fmt.Errorf("%s: %w: %s", cc.cmd.Path, waitErr, strings.TrimSpace(cc.stderr.String()))
+	}
+	return n, err
+}
+
+func (cc *commandConn) Write(p []byte) (int, error) {
+	return cc.stdin.Write(p)
+}
+
+func (cc *commandConn) Close() error {
+	cc.stdin.Close()
+	cc.cmd.Process.Kill()
+	cc.wait()
+	return nil
+}
+
+func (cc *commandConn) LocalAddr() net.Addr              { return commandAddr{} }
+func (cc *commandConn) RemoteAddr() net.Addr             { return commandAddr{} }
+func (cc *commandConn) SetDeadline(time.Time) error      { return nil }
+func (cc *commandConn) SetReadDeadline(time.Time) error  { return nil }
+func (cc *commandConn) SetWriteDeadline(time.Time) error { return nil }
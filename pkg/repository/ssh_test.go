@@ -0,0 +1,69 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package repository
+
+import (
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSHArgs(t *testing.T) {
+	var tests = []struct {
+		endpoint string
+		expected []string
+		err      bool
+	}{
+		{endpoint: "ssh://10.0.0.2", expected: []string{"--", "10.0.0.2", "docker", "system", "dial-stdio"}},
+		{endpoint: "ssh://genesis@10.0.0.2:2222", expected: []string{"-l", "genesis", "-p", "2222",
+			"--", "10.0.0.2", "docker", "system", "dial-stdio"}},
+		{endpoint: "tcp://10.0.0.2:2376", err: true},
+		{endpoint: "ssh://-oProxyCommand=x", err: true},
+		{endpoint: "ssh://10.0.0.2/var/run/docker.sock", err: true},
+		{endpoint: "ssh://", err: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res, err := sshArgs(tt.endpoint)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestCommandConn(t *testing.T) {
+	conn, err := newCommandConn(exec.Command("cat"))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+}
+
+func TestCommandConn_Failure(t *testing.T) {
+	conn, err := newCommandConn(exec.Command("sh", "-c", "echo denied >&2; exit 255"))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = ioutil.ReadAll(conn)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "denied")
+}
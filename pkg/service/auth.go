@@ -0,0 +1,233 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	// HMACKeyIDHeader is the header which holds the id of the key a request is signed with
+	HMACKeyIDHeader = "X-Genesis-Key-Id"
+	// HMACTimestampHeader is the header which holds the unix time a request was signed at
+	HMACTimestampHeader = "X-Genesis-Timestamp"
+	// HMACSignatureHeader is the header which holds the hex encoded signature of a request
+	HMACSignatureHeader = "X-Genesis-Signature"
+)
+
+var (
+	// ErrInvalidCredentials is returned when a request uses a method of authentication, but
+	// its credentials are not valid
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+//Authenticator identifies the caller of a REST request, using a single method of authentication
+type Authenticator interface {
+	//Authenticate identifies the caller of the request. It returns false if the request does not
+	//use this method of authentication, or an error if it does but is not valid
+	Authenticate(r *http.Request, body []byte) (entity.Credential, bool, error)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")), true
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type bearerAuthenticator struct {
+	tokens map[string]entity.Credential
+}
+
+//NewBearerAuthenticator creates an Authenticator for static bearer tokens, given a map of
+//each token to its credential
+func NewBearerAuthenticator(tokens map[string]entity.Credential) Authenticator {
+	out := &bearerAuthenticator{tokens: map[string]entity.Credential{}}
+	for token, cred := range tokens {
+		out.tokens[hashToken(token)] = cred
+	}
+	return out
+}
+
+//Authenticate identifies the caller by its bearer token. Tokens which are not known are left to
+//the other methods, as they may be JWTs
+func (ba bearerAuthenticator) Authenticate(r *http.Request,
+	_ []byte) (entity.Credential, bool, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return entity.Credential{}, false, nil
+	}
+	cred, ok := ba.tokens[hashToken(token)]
+	return cred, ok, nil
+}
+
+type hmacAuthenticator struct {
+	keys    map[string]entity.Credential
+	maxSkew time.Duration
+	now     func() time.Time
+}
+
+//NewHMACAuthenticator creates an Authenticator for HMAC signed requests, given a map of each key
+//id to its credential
+func NewHMACAuthenticator(keys map[string]entity.Credential, maxSkew time.Duration) Authenticator {
+	return &hmacAuthenticator{keys: keys, maxSkew: maxSkew, now: time.Now}
+}
+
+//SignRequest computes the HMAC-SHA256 signature of a request, which covers its method, request
+//uri, timestamp and body, each separated by a newline
+func SignRequest(secret, method, uri, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n", method, uri, timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//Authenticate identifies the caller by the key which signed the request
+func (ha hmacAuthenticator) Authenticate(r *http.Request,
+	body []byte) (entity.Credential, bool, error) {
+	signature := r.Header.Get(HMACSignatureHeader)
+	if signature == "" {
+		return entity.Credential{}, false, nil
+	}
+	cred, ok := ha.keys[r.Header.Get(HMACKeyIDHeader)]
+	if !ok {
+		return entity.Credential{}, true, ErrInvalidCredentials
+	}
+	timestamp := r.Header.Get(HMACTimestampHeader)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return entity.Credential{}, true, fmt.Errorf("%w: malformed timestamp", ErrInvalidCredentials)
+	}
+	skew := ha.now().Sub(time.Unix(unix, 0))
+	if skew > ha.maxSkew || -skew > ha.maxSkew {
+		return entity.Credential{}, true, fmt.Errorf("%w: timestamp is too far from the current time",
+			ErrInvalidCredentials)
+	}
+	expected := SignRequest(cred.Secret, r.Method, r.URL.RequestURI(), timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return entity.Credential{}, true, ErrInvalidCredentials
+	}
+	return cred, true, nil
+}
+
+type jwtAuthenticator struct {
+	keys jose.JSONWebKeySet
+	conf config.Auth
+	now  func() time.Time
+}
+
+//NewJWTAuthenticator creates an Authenticator for JWT bearer tokens, which are verified against
+//the given JSON web key set
+func NewJWTAuthenticator(jwks []byte, conf config.Auth) (Authenticator, error) {
+	out := &jwtAuthenticator{conf: conf, now: time.Now}
+	err := json.Unmarshal(jwks, &out.keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.keys.Keys) == 0 {
+		return nil, errors.New("the JSON web key set has no keys")
+	}
+	return out, nil
+}
+
+func (ja jwtAuthenticator) key(tok *jwt.JSONWebToken) (interface{}, error) {
+	kid := ""
+	if len(tok.Headers) > 0 {
+		kid = tok.Headers[0].KeyID
+	}
+	if kid == "" && len(ja.keys.Keys) == 1 {
+		return ja.keys.Keys[0].Public().Key, nil
+	}
+	keys := ja.keys.Key(kid)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidCredentials, kid)
+	}
+	return keys[0].Public().Key, nil
+}
+
+func orgsFromClaim(claim interface{}) ([]string, error) {
+	switch orgs := claim.(type) {
+	case string:
+		return []string{orgs}, nil
+	case []interface{}:
+		out := make([]string, len(orgs))
+		for i, org := range orgs {
+			str, ok := org.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: org ids must be strings", ErrInvalidCredentials)
+			}
+			out[i] = str
+		}
+		return out, nil
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%w: org ids must be a string or a list of strings", ErrInvalidCredentials)
+}
+
+//Authenticate identifies the caller by the claims of its JWT, which must be signed by one of the
+//keys and must not have expired
+func (ja jwtAuthenticator) Authenticate(r *http.Request,
+	_ []byte) (entity.Credential, bool, error) {
+	token, ok := bearerToken(r)
+	if !ok || strings.Count(token, ".") != 2 {
+		return entity.Credential{}, false, nil
+	}
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return entity.Credential{}, true, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+	}
+	key, err := ja.key(tok)
+	if err != nil {
+		return entity.Credential{}, true, err
+	}
+
+	var claims jwt.Claims
+	custom := map[string]interface{}{}
+	err = tok.Claims(key, &claims, &custom)
+	if err != nil {
+		return entity.Credential{}, true, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+	}
+	if claims.Expiry == nil {
+		return entity.Credential{}, true, fmt.Errorf("%w: missing expiry", ErrInvalidCredentials)
+	}
+	expected := jwt.Expected{Issuer: ja.conf.JWTIssuer, Time: ja.now()}
+	if ja.conf.JWTAudience != "" {
+		expected.Audience = jwt.Audience{ja.conf.JWTAudience}
+	}
+	err = claims.ValidateWithLeeway(expected, time.Minute)
+	if err != nil {
+		return entity.Credential{}, true, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+	}
+
+	orgs, err := orgsFromClaim(custom[ja.conf.JWTOrgClaim])
+	if err != nil {
+		return entity.Credential{}, true, err
+	}
+	return entity.Credential{Subject: claims.Subject, Orgs: orgs}, true, nil
+}
@@ -0,0 +1,173 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func TestBearerAuthenticator(t *testing.T) {
+	cred := entity.Credential{Subject: "ci", Orgs: []string{"a"}}
+	auth := NewBearerAuthenticator(map[string]entity.Credential{"secret": cred})
+
+	var tests = []struct {
+		header string
+		ok     bool
+	}{
+		{header: "Bearer secret", ok: true},
+		{header: "Bearer other", ok: false},
+		{header: "Basic secret", ok: false},
+		{header: "", ok: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/command", nil)
+			req.Header.Set("Authorization", tt.header)
+			res, ok, err := auth.Authenticate(req, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, cred, res)
+			}
+		})
+	}
+}
+
+func TestHMACAuthenticator(t *testing.T) {
+	cred := entity.Credential{Subject: "ci", Orgs: []string{"a"}, Secret: "shh"}
+	now := time.Unix(1000, 0)
+	body := []byte(`{"id":"test"}`)
+
+	var tests = []struct {
+		keyID     string
+		timestamp string
+		signature string
+		ok        bool
+		err       bool
+	}{
+		{keyID: "k", timestamp: "1000", signature: SignRequest("shh", "POST", "/command?wait=true",
+			"1000", body), ok: true},
+		{keyID: "k", timestamp: "1000", signature: SignRequest("shh", "POST", "/command",
+			"1000", body), ok: true, err: true},
+		{keyID: "k", timestamp: "100", signature: SignRequest("shh", "POST", "/command?wait=true",
+			"100", body), ok: true, err: true},
+		{keyID: "other", timestamp: "1000", signature: "00", ok: true, err: true},
+		{keyID: "k", timestamp: "soon", signature: "00", ok: true, err: true},
+		{keyID: "k", timestamp: "1000", signature: "", ok: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			auth := NewHMACAuthenticator(map[string]entity.Credential{"k": cred}, time.Minute)
+			auth.(*hmacAuthenticator).now = func() time.Time { return now }
+
+			req := httptest.NewRequest("POST", "/command?wait=true", nil)
+			req.Header.Set(HMACKeyIDHeader, tt.keyID)
+			req.Header.Set(HMACTimestampHeader, tt.timestamp)
+			req.Header.Set(HMACSignatureHeader, tt.signature)
+			res, ok, err := auth.Authenticate(req, body)
+			assert.Equal(t, tt.ok, ok)
+			if tt.err {
+				assert.True(t, errors.Is(err, ErrInvalidCredentials))
+				return
+			}
+			assert.NoError(t, err)
+			if tt.ok {
+				assert.Equal(t, cred, res)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key: key.Public(), KeyID: "k1", Algorithm: "RS256", Use: "sig"}}})
+	require.NoError(t, err)
+
+	now := time.Now()
+	sign := func(signingKey *rsa.PrivateKey, claims jwt.Claims, orgs interface{}) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: signingKey},
+			(&jose.SignerOptions{}).WithHeader("kid", "k1"))
+		require.NoError(t, err)
+		out, err := jwt.Signed(signer).Claims(claims).Claims(map[string]interface{}{
+			"orgs": orgs}).CompactSerialize()
+		require.NoError(t, err)
+		return out
+	}
+	valid := jwt.Claims{Subject: "user", Issuer: "issuer", Expiry: jwt.NewNumericDate(now.Add(time.Hour))}
+	expired := valid
+	expired.Expiry = jwt.NewNumericDate(now.Add(-time.Hour))
+	noExpiry := valid
+	noExpiry.Expiry = nil
+	wrongIssuer := valid
+	wrongIssuer.Issuer = "other"
+
+	var tests = []struct {
+		token    string
+		expected []string
+		ok       bool
+		err      bool
+	}{
+		{token: sign(key, valid, []string{"a", "b"}), expected: []string{"a", "b"}, ok: true},
+		{token: sign(key, valid, "a"), expected: []string{"a"}, ok: true},
+		{token: sign(key, valid, 5), ok: true, err: true},
+		{token: sign(key, expired, "a"), ok: true, err: true},
+		{token: sign(key, noExpiry, "a"), ok: true, err: true},
+		{token: sign(key, wrongIssuer, "a"), ok: true, err: true},
+		{token: sign(otherKey, valid, "a"), ok: true, err: true},
+		{token: "not-a-jwt", ok: false},
+	}
+
+	auth, err := NewJWTAuthenticator(jwks, config.Auth{JWTIssuer: "issuer", JWTOrgClaim: "orgs"})
+	require.NoError(t, err)
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/command", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			res, ok, err := auth.Authenticate(req, nil)
+			assert.Equal(t, tt.ok, ok)
+			if tt.err {
+				assert.True(t, errors.Is(err, ErrInvalidCredentials), err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.ok {
+				assert.Equal(t, "user", res.Subject)
+				assert.Equal(t, tt.expected, res.Orgs)
+			}
+		})
+	}
+}
+
+func TestNewJWTAuthenticator_Failure(t *testing.T) {
+	_, err := NewJWTAuthenticator([]byte(`{"keys":[]}`), config.Auth{})
+	assert.Error(t, err)
+	_, err = NewJWTAuthenticator([]byte(`nope`), config.Auth{})
+	assert.Error(t, err)
+}
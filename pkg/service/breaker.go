@@ -0,0 +1,171 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+const breakerPingTimeout = 5 * time.Second
+
+//HostBreaker is a circuit breaker for each docker host. Once a host's daemon cannot be reached
+//several times in a row, commands for it fail fast until a ping shows that it is back.
+type HostBreaker interface {
+	//Allow returns true if commands may be sent to the host. If the breaker of the host is open
+	//and its cooldown has passed, the host is pinged and the breaker closes if it responds.
+	Allow(host string) bool
+	//Success records that the daemon of the host was reached
+	Success(host string)
+	//Failure records that the daemon of the host could not be reached
+	Failure(host string, err error)
+	//Health gets the health of every host which commands have been sent to
+	Health() []entity.HostHealth
+	//Host gets the health of a single host, returning false if no commands have been sent to it
+	Host(host string) (entity.HostHealth, bool)
+}
+
+type hostBreaker struct {
+	docker DockerService
+	conf   config.Execution
+
+	mu    sync.Mutex
+	hosts map[string]*entity.HostHealth
+	now   func() time.Time
+
+	log logrus.Ext1FieldLogger
+}
+
+//NewHostBreaker creates a new HostBreaker, which probes hosts using the clients of docker
+func NewHostBreaker(
+	docker DockerService,
+	conf config.Execution,
+	log logrus.Ext1FieldLogger) HostBreaker {
+
+	return &hostBreaker{
+		docker: docker,
+		conf:   conf,
+		hosts:  map[string]*entity.HostHealth{},
+		now:    time.Now,
+		log:    log,
+	}
+}
+
+//get gets the health of the host, adding it if it is new. The lock must be held.
+func (hb *hostBreaker) get(host string) *entity.HostHealth {
+	out, ok := hb.hosts[host]
+	if !ok {
+		out = &entity.HostHealth{Host: host, State: entity.BreakerClosed}
+		hb.hosts[host] = out
+	}
+	return out
+}
+
+//Allow returns true if commands may be sent to the host
+func (hb *hostBreaker) Allow(host string) bool {
+	hb.mu.Lock()
+	health, ok := hb.hosts[host]
+	switch {
+	case !ok || health.State == entity.BreakerClosed:
+		hb.mu.Unlock()
+		return true
+	case health.State == entity.BreakerHalfOpen || hb.now().Sub(health.OpenedAt) < hb.conf.BreakerCooldown:
+		hb.mu.Unlock()
+		return false
+	}
+	health.State = entity.BreakerHalfOpen
+	hb.mu.Unlock()
+
+	hb.log.WithField("host", host).Info("probing a docker host")
+	err := hb.ping(host)
+	if err != nil {
+		hb.Failure(host, err)
+		return false
+	}
+	hb.Success(host)
+	return true
+}
+
+func (hb *hostBreaker) ping(host string) error {
+	cli, err := hb.docker.CreateClient(host)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), breakerPingTimeout)
+	defer cancel()
+	_, err = cli.Ping(ctx)
+	if err != nil {
+		hb.docker.EvictClient(host, err)
+	}
+	return err
+}
+
+//Success records that the daemon of the host was reached
+func (hb *hostBreaker) Success(host string) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	health := hb.get(host)
+	if health.State != entity.BreakerClosed {
+		hb.log.WithField("host", host).Info("a docker host is reachable again")
+	}
+	health.State = entity.BreakerClosed
+	health.ConsecutiveFailures = 0
+	health.LastSuccess = hb.now()
+}
+
+//Failure records that the daemon of the host could not be reached, opening its breaker once
+//this has happened enough times in a row
+func (hb *hostBreaker) Failure(host string, err error) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	health := hb.get(host)
+	health.ConsecutiveFailures++
+	health.LastFailure = hb.now()
+	if err != nil {
+		health.LastError = err.Error()
+	}
+	if health.State == entity.BreakerHalfOpen ||
+		(health.State == entity.BreakerClosed && health.ConsecutiveFailures >= hb.conf.BreakerThreshold) {
+		hb.log.WithFields(logrus.Fields{
+			"host":     host,
+			"failures": health.ConsecutiveFailures,
+			"error":    err}).Warn("opening the circuit breaker of a docker host")
+		health.State = entity.BreakerOpen
+		health.OpenedAt = health.LastFailure
+	}
+}
+
+//Health gets the health of every host which commands have been sent to, ordered by host
+func (hb *hostBreaker) Health() []entity.HostHealth {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	out := make([]entity.HostHealth, 0, len(hb.hosts))
+	for _, health := range hb.hosts {
+		out = append(out, *health)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
+
+//Host gets the health of a single host
+func (hb *hostBreaker) Host(host string) (entity.HostHealth, bool) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	health, ok := hb.hosts[host]
+	if !ok {
+		return entity.HostHealth{}, false
+	}
+	return *health, true
+}
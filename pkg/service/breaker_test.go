@@ -0,0 +1,91 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	entityMock "github.com/whiteblock/genesis/mocks/pkg/entity"
+	serviceMock "github.com/whiteblock/genesis/mocks/pkg/service"
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/docker/docker/api/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostBreaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cli := new(entityMock.Client)
+	cli.On("Close").Return(nil)
+	docker := new(serviceMock.DockerService)
+	docker.On("CreateClient", "a").Return(cli, nil)
+	docker.On("EvictClient", "a", mock.Anything).Return()
+
+	breaker := NewHostBreaker(docker, config.Execution{BreakerThreshold: 2,
+		BreakerCooldown: time.Minute}, logrus.New())
+	breaker.(*hostBreaker).now = func() time.Time { return now }
+
+	assert.True(t, breaker.Allow("a"))
+	_, ok := breaker.Host("a")
+	assert.False(t, ok)
+
+	breaker.Failure("a", fmt.Errorf("refused"))
+	assert.True(t, breaker.Allow("a"))
+	breaker.Failure("a", fmt.Errorf("refused"))
+	assert.False(t, breaker.Allow("a"))
+
+	health, ok := breaker.Host("a")
+	require.True(t, ok)
+	assert.Equal(t, entity.HostHealth{Host: "a", State: entity.BreakerOpen, ConsecutiveFailures: 2,
+		LastError: "refused", LastFailure: now, OpenedAt: now}, health)
+	assert.False(t, health.Healthy())
+
+	//once the cooldown passes, a failed probe opens the breaker again
+	now = now.Add(time.Minute)
+	cli.On("Ping", mock.Anything).Return(types.Ping{}, fmt.Errorf("still down")).Once()
+	assert.False(t, breaker.Allow("a"))
+	health, _ = breaker.Host("a")
+	assert.Equal(t, entity.BreakerOpen, health.State)
+	assert.Equal(t, now, health.OpenedAt)
+	assert.Equal(t, 3, health.ConsecutiveFailures)
+
+	now = now.Add(30 * time.Second)
+	assert.False(t, breaker.Allow("a"))
+
+	//and a successful probe closes it
+	now = now.Add(30 * time.Second)
+	cli.On("Ping", mock.Anything).Return(types.Ping{}, nil).Once()
+	assert.True(t, breaker.Allow("a"))
+	health, _ = breaker.Host("a")
+	assert.True(t, health.Healthy())
+	assert.Equal(t, 0, health.ConsecutiveFailures)
+	assert.Equal(t, now, health.LastSuccess)
+
+	breaker.Success("b")
+	res := breaker.Health()
+	require.Len(t, res, 2)
+	assert.Equal(t, "a", res[0].Host)
+	assert.Equal(t, "b", res[1].Host)
+
+	cli.AssertExpectations(t)
+	docker.AssertNumberOfCalls(t, "EvictClient", 1)
+}
+
+func TestHostBreaker_Allow_HalfOpen(t *testing.T) {
+	breaker := NewHostBreaker(nil, config.Execution{BreakerThreshold: 1,
+		BreakerCooldown: time.Minute}, logrus.New()).(*hostBreaker)
+	breaker.Failure("a", nil)
+	breaker.hosts["a"].State = entity.BreakerHalfOpen
+
+	assert.False(t, breaker.Allow("a"))
+}
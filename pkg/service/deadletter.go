@@ -0,0 +1,82 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"github.com/whiteblock/genesis/pkg/bus"
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	queue "github.com/whiteblock/amqp"
+)
+
+//DeadLetterService provides the inspection and replay of dead-lettered instructions
+type DeadLetterService interface {
+	//List gets all of the dead letters
+	List() ([]entity.DeadLetter, error)
+
+	//Get gets the dead letter with the given id
+	Get(id string) (entity.DeadLetter, error)
+
+	//Replay removes the dead letter with the given id and places its original
+	//instructions back onto the command queue
+	Replay(id string) (entity.DeadLetter, error)
+}
+
+type deadLetterService struct {
+	repo repository.DeadLetterRepository
+	cmds bus.Queue
+	log  logrus.Ext1FieldLogger
+}
+
+//NewDeadLetterService creates a new DeadLetterService, which replays onto the given command queue
+func NewDeadLetterService(
+	repo repository.DeadLetterRepository,
+	cmds bus.Queue,
+	log logrus.Ext1FieldLogger) DeadLetterService {
+
+	return &deadLetterService{repo: repo, cmds: cmds, log: log}
+}
+
+//List gets all of the dead letters
+func (dls deadLetterService) List() ([]entity.DeadLetter, error) {
+	return dls.repo.List()
+}
+
+//Get gets the dead letter with the given id
+func (dls deadLetterService) Get(id string) (entity.DeadLetter, error) {
+	letters, err := dls.repo.List()
+	if err != nil {
+		return entity.DeadLetter{}, err
+	}
+	for _, letter := range letters {
+		if letter.ID == id {
+			return letter, nil
+		}
+	}
+	return entity.DeadLetter{}, repository.ErrDeadLetterNotFound
+}
+
+//Replay removes the dead letter with the given id and places its original
+//instructions back onto the command queue
+func (dls deadLetterService) Replay(id string) (entity.DeadLetter, error) {
+	return dls.repo.Remove(id, func(letter entity.DeadLetter) error {
+		dls.log.WithFields(logrus.Fields{
+			"id":   letter.ID,
+			"test": letter.TestID,
+		}).Info("replaying a dead letter")
+		return dls.cmds.Send(amqp.Publishing{
+			Headers: amqp.Table{
+				queue.RetryCountHeader: int64(0),
+			},
+			ContentType: "application/json",
+			Body:        letter.Body,
+		})
+	})
+}
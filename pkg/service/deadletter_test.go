@@ -0,0 +1,63 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	queueMocks "github.com/whiteblock/genesis/mocks/amqp"
+	repoMock "github.com/whiteblock/genesis/mocks/pkg/repository"
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterService_Get(t *testing.T) {
+	repo := new(repoMock.DeadLetterRepository)
+	repo.On("List").Return([]entity.DeadLetter{{ID: "1"}, {ID: "2"}}, nil).Twice()
+
+	serv := NewDeadLetterService(repo, nil, logrus.New())
+	letter, err := serv.Get("2")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", letter.ID)
+
+	_, err = serv.Get("3")
+	assert.Equal(t, repository.ErrDeadLetterNotFound, err)
+
+	repo.AssertExpectations(t)
+}
+
+func TestDeadLetterService_Replay(t *testing.T) {
+	letter := entity.DeadLetter{ID: "1", Body: json.RawMessage(`{"id":"test"}`)}
+
+	cmds := new(queueMocks.AMQPService)
+	cmds.On("Send", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		pub, ok := args.Get(0).(amqp.Publishing)
+		require.True(t, ok)
+		assert.Equal(t, []byte(letter.Body), pub.Body)
+	}).Once()
+
+	repo := new(repoMock.DeadLetterRepository)
+	repo.On("Remove", "1", mock.Anything).Return(letter, nil).Run(func(args mock.Arguments) {
+		fn, ok := args.Get(1).(func(entity.DeadLetter) error)
+		require.True(t, ok)
+		assert.NoError(t, fn(letter))
+	}).Once()
+
+	res, err := NewDeadLetterService(repo, cmds, logrus.New()).Replay("1")
+	assert.NoError(t, err)
+	assert.Equal(t, letter.ID, res.ID)
+
+	repo.AssertExpectations(t)
+	cmds.AssertExpectations(t)
+}
@@ -0,0 +1,314 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/tracing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	diagnosticDialTimeout = time.Second
+	diagnosticBodyLimit   = 4096
+)
+
+type diagnosis struct {
+	entity.Diagnosis
+}
+
+func (d *diagnosis) step(name string, fn func() (interface{}, error)) bool {
+	start := time.Now()
+	detail, err := fn()
+	step := entity.DiagnosticStep{
+		Name:          name,
+		OK:            err == nil,
+		LatencyMillis: entity.Millis(time.Since(start)),
+		Detail:        detail,
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	d.Steps = append(d.Steps, step)
+	return step.OK
+}
+
+//daemonEndpoint is where the daemon of a client is reached, without going through the client
+type daemonEndpoint struct {
+	//network is the network the daemon is dialed over, or empty if it cannot be dialed directly
+	network string
+	//address is the address the daemon is dialed at
+	address string
+	//baseURL is the url of the api, when called over the http client of the docker client
+	baseURL string
+}
+
+//newDaemonEndpoint works out how to reach the daemon from the host of its client. Daemons behind
+//tcp or a unix socket are dialed directly. Otherwise, such as over ssh, the connection is made by
+//the dialer of the client, so the daemon is only reached through its http client.
+func newDaemonEndpoint(daemonHost string) daemonEndpoint {
+	u, err := url.Parse(daemonHost)
+	if err != nil {
+		return daemonEndpoint{baseURL: daemonHost}
+	}
+	switch u.Scheme {
+	case "tcp":
+		return daemonEndpoint{network: "tcp", address: u.Host, baseURL: "https://" + u.Host}
+	case "unix":
+		//the transport of the client dials the socket, whatever the host of the url
+		return daemonEndpoint{network: "unix", address: u.Path, baseURL: "http://docker"}
+	}
+	return daemonEndpoint{baseURL: strings.TrimSuffix(daemonHost, "/")}
+}
+
+//DiagnoseClient checks whether the docker api of the host responds to cli. If it does not, it
+//works out how far the connection to the daemon gets, by dialing it directly where it can be,
+//and then calling its api without the docker client.
+func DiagnoseClient(ctx context.Context, host string, cli entity.Client) entity.Diagnosis {
+	d := newDiagnosis(host)
+	if d.ping(ctx, cli) {
+		return d.Diagnosis
+	}
+	endpoint := newDaemonEndpoint(cli.DaemonHost())
+	if !d.dial(endpoint) {
+		return d.Diagnosis //nothing more to report
+	}
+	d.raw(ctx, cli, endpoint)
+	return d.Diagnosis
+}
+
+//FullDiagnosis runs every step of diagnosing the connection to the docker daemon of the host,
+//whether or not its api responds. Once the api responds, the version negotiated with the daemon,
+//its disk usage and its images are reported as well.
+func FullDiagnosis(ctx context.Context, host string, cli entity.Client) entity.Diagnosis {
+	d := newDiagnosis(host)
+	d.ping(ctx, cli)
+	endpoint := newDaemonEndpoint(cli.DaemonHost())
+	if d.dial(endpoint) {
+		d.raw(ctx, cli, endpoint)
+	}
+	if !d.Healthy {
+		return d.Diagnosis
+	}
+
+	d.step("version", func() (interface{}, error) {
+		cli.NegotiateAPIVersion(ctx)
+		ver, err := cli.ServerVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return versionReport{
+			ClientAPIVersion: cli.ClientVersion(),
+			ServerVersion:    ver.Version,
+			ServerAPIVersion: ver.APIVersion,
+			MinAPIVersion:    ver.MinAPIVersion,
+			OS:               ver.Os,
+			Arch:             ver.Arch,
+			KernelVersion:    ver.KernelVersion,
+		}, nil
+	})
+	d.step("diskUsage", func() (interface{}, error) {
+		usage, err := cli.DiskUsage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out := diskReport{
+			LayersSize: usage.LayersSize,
+			Images:     len(usage.Images),
+			Containers: len(usage.Containers),
+			Volumes:    len(usage.Volumes),
+		}
+		for _, vol := range usage.Volumes {
+			if vol != nil && vol.UsageData != nil && vol.UsageData.Size > 0 {
+				out.VolumesSize += vol.UsageData.Size
+			}
+		}
+		for _, cache := range usage.BuildCache {
+			if cache != nil {
+				out.BuildCacheSize += cache.Size
+			}
+		}
+		return out, nil
+	})
+	d.step("images", func() (interface{}, error) {
+		images, err := cli.ImageList(ctx, types.ImageListOptions{All: true})
+		if err != nil {
+			return nil, err
+		}
+		out := imageReport{Count: len(images)}
+		for _, img := range images {
+			out.Size += img.Size
+		}
+		return out, nil
+	})
+	return d.Diagnosis
+}
+
+func newDiagnosis(host string) *diagnosis {
+	return &diagnosis{entity.Diagnosis{Host: host, Steps: []entity.DiagnosticStep{}}}
+}
+
+//ping calls the docker api through cli, marking the diagnosis as healthy if it responds
+func (d *diagnosis) ping(ctx context.Context, cli entity.Client) bool {
+	d.Healthy = d.step("ping", func() (interface{}, error) {
+		res, err := cli.Ping(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	})
+	return d.Healthy
+}
+
+//dial opens a connection to the daemon, without going through the docker client. Daemons which
+//cannot be dialed directly are skipped.
+func (d *diagnosis) dial(endpoint daemonEndpoint) bool {
+	if endpoint.network == "" {
+		return true
+	}
+	return d.step("dial", func() (interface{}, error) {
+		conn, err := net.DialTimeout(endpoint.network, endpoint.address, diagnosticDialTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return conn.RemoteAddr().String(), conn.Close()
+	})
+}
+
+//raw calls the info and ping endpoints of the docker api over the http client of cli
+func (d *diagnosis) raw(ctx context.Context, cli entity.Client, endpoint daemonEndpoint) {
+	httpClient := cli.HTTPClient()
+	d.step("info", func() (interface{}, error) {
+		return get(ctx, httpClient, endpoint.baseURL+"/info")
+	})
+	d.step("rawPing", func() (interface{}, error) {
+		return get(ctx, httpClient, endpoint.baseURL+"/_ping")
+	})
+}
+
+//versionReport is the version of the api negotiated with the daemon
+type versionReport struct {
+	ClientAPIVersion string `json:"clientApiVersion"`
+	ServerVersion    string `json:"serverVersion"`
+	ServerAPIVersion string `json:"serverApiVersion"`
+	MinAPIVersion    string `json:"minApiVersion,omitempty"`
+	OS               string `json:"os"`
+	Arch             string `json:"arch"`
+	KernelVersion    string `json:"kernelVersion,omitempty"`
+}
+
+//diskReport is how much disk space the daemon uses, in bytes
+type diskReport struct {
+	LayersSize     int64 `json:"layersSize"`
+	VolumesSize    int64 `json:"volumesSize"`
+	BuildCacheSize int64 `json:"buildCacheSize"`
+	Images         int   `json:"images"`
+	Containers     int   `json:"containers"`
+	Volumes        int   `json:"volumes"`
+}
+
+//imageReport is how many images the daemon has, and their total size in bytes
+type imageReport struct {
+	Count int   `json:"count"`
+	Size  int64 `json:"size"`
+}
+
+//rawResponse is the response to a call made to the docker api without the docker client
+type rawResponse struct {
+	StatusCode int    `json:"statusCode"`
+	Body       string `json:"body"`
+}
+
+func get(ctx context.Context, httpClient *http.Client, uri string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, diagnosticBodyLimit))
+	out := rawResponse{StatusCode: resp.StatusCode, Body: string(data)}
+	if err != nil {
+		return out, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return out, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return out, nil
+}
+
+//HostDiagnoser diagnoses the connection to the docker daemons of hosts
+type HostDiagnoser interface {
+	//Diagnose runs every step of diagnosing the connection to the docker daemon of the host
+	Diagnose(ctx context.Context, host string) entity.Diagnosis
+}
+
+type hostDiagnoser struct {
+	docker  DockerService
+	timeout time.Duration
+	log     logrus.Ext1FieldLogger
+}
+
+//NewHostDiagnoser creates a new HostDiagnoser, which connects to the hosts with the clients of
+//docker and gives up on each diagnosis after the timeout, if it is not zero
+func NewHostDiagnoser(docker DockerService, timeout time.Duration,
+	log logrus.Ext1FieldLogger) HostDiagnoser {
+	return &hostDiagnoser{docker: docker, timeout: timeout, log: log}
+}
+
+//Diagnose runs every step of diagnosing the connection to the docker daemon of the host
+func (hd hostDiagnoser) Diagnose(ctx context.Context, host string) entity.Diagnosis {
+	if hd.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hd.timeout)
+		defer cancel()
+	}
+	ctx, span := tracing.Start(ctx, "diagnose", attribute.String("genesis.docker.host", host))
+
+	var diag entity.Diagnosis
+	cli, err := hd.docker.CreateClient(host)
+	if err != nil {
+		d := newDiagnosis(host)
+		d.step("client", func() (interface{}, error) { return nil, err })
+		diag = d.Diagnosis
+	} else {
+		diag = FullDiagnosis(ctx, host, cli)
+		cli.Close()
+	}
+	span.SetAttributes(attribute.Bool("genesis.docker.healthy", diag.Healthy))
+	span.End()
+
+	for _, step := range diag.Steps {
+		entry := hd.log.WithFields(logrus.Fields{
+			"host":    host,
+			"step":    step.Name,
+			"latency": step.LatencyMillis,
+		})
+		if step.OK {
+			entry.Debug("a step of diagnosing the docker daemon succeeded")
+		} else {
+			entry.WithField("error", step.Error).Warn("a step of diagnosing the docker daemon failed")
+		}
+	}
+	return diag
+}
@@ -0,0 +1,260 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	entityMock "github.com/whiteblock/genesis/mocks/pkg/entity"
+	serviceMock "github.com/whiteblock/genesis/mocks/pkg/service"
+
+	"github.com/docker/docker/api/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnoseClient_Healthy(t *testing.T) {
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{APIVersion: "1.40"}, nil).Once()
+
+	diag := DiagnoseClient(context.Background(), "10.0.0.2", cli)
+	assert.True(t, diag.Healthy)
+	assert.Equal(t, "10.0.0.2", diag.Host)
+	require.Len(t, diag.Steps, 1)
+	assert.Equal(t, "ping", diag.Steps[0].Name)
+	assert.True(t, diag.Steps[0].OK)
+	cli.AssertExpectations(t)
+}
+
+func TestDiagnoseClient_DaemonDown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{}, errors.New("connection refused")).Once()
+	cli.On("DaemonHost").Return("tcp://" + addr).Once()
+
+	diag := DiagnoseClient(context.Background(), "127.0.0.1", cli)
+	assert.False(t, diag.Healthy)
+	require.Len(t, diag.Steps, 2)
+	assert.Equal(t, "connection refused", diag.Steps[0].Error)
+	assert.Equal(t, "dial", diag.Steps[1].Name)
+	assert.False(t, diag.Steps[1].OK)
+
+	step, failed := diag.Failure()
+	assert.True(t, failed)
+	assert.Equal(t, "ping", step.Name)
+	cli.AssertExpectations(t)
+}
+
+func TestDiagnoseClient_APIBroken(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_ping" {
+			w.Write([]byte("OK"))
+			return
+		}
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{}, errors.New("bad response")).Once()
+	cli.On("DaemonHost").Return(strings.Replace(srv.URL, "https://", "tcp://", 1)).Once()
+	cli.On("HTTPClient").Return(srv.Client()).Once()
+
+	diag := DiagnoseClient(context.Background(), "127.0.0.1", cli)
+	assert.False(t, diag.Healthy)
+	require.Len(t, diag.Steps, 4)
+	assert.True(t, diag.Steps[1].OK)
+	assert.Equal(t, "info", diag.Steps[2].Name)
+	assert.False(t, diag.Steps[2].OK)
+	assert.Equal(t, rawResponse{StatusCode: http.StatusInternalServerError, Body: "broken\n"},
+		diag.Steps[2].Detail)
+	assert.Equal(t, "rawPing", diag.Steps[3].Name)
+	assert.True(t, diag.Steps[3].OK)
+	assert.Equal(t, rawResponse{StatusCode: http.StatusOK, Body: "OK"}, diag.Steps[3].Detail)
+	cli.AssertExpectations(t)
+}
+
+func TestFullDiagnosis_Healthy(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer srv.Close()
+
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{APIVersion: "1.40"}, nil).Once()
+	cli.On("DaemonHost").Return(strings.Replace(srv.URL, "https://", "tcp://", 1)).Once()
+	cli.On("HTTPClient").Return(srv.Client()).Once()
+	cli.On("NegotiateAPIVersion", mock.Anything).Return().Once()
+	cli.On("ServerVersion", mock.Anything).Return(types.Version{Version: "19.03.5",
+		APIVersion: "1.40", MinAPIVersion: "1.12", Os: "linux", Arch: "amd64"}, nil).Once()
+	cli.On("ClientVersion").Return("1.40").Once()
+	cli.On("DiskUsage", mock.Anything).Return(types.DiskUsage{
+		LayersSize: 100,
+		Images:     []*types.ImageSummary{{}, {}},
+		Volumes:    []*types.Volume{{UsageData: &types.VolumeUsageData{Size: 10}}, {}},
+		BuildCache: []*types.BuildCache{{Size: 5}},
+	}, nil).Once()
+	cli.On("ImageList", mock.Anything, types.ImageListOptions{All: true}).Return(
+		[]types.ImageSummary{{Size: 3}, {Size: 4}}, nil).Once()
+
+	diag := FullDiagnosis(context.Background(), "127.0.0.1", cli)
+	assert.True(t, diag.Healthy)
+	_, failed := diag.Failure()
+	assert.False(t, failed)
+
+	names := []string{}
+	for _, step := range diag.Steps {
+		names = append(names, step.Name)
+	}
+	assert.Equal(t, []string{"ping", "dial", "info", "rawPing", "version", "diskUsage", "images"}, names)
+	assert.Equal(t, versionReport{ClientAPIVersion: "1.40", ServerVersion: "19.03.5",
+		ServerAPIVersion: "1.40", MinAPIVersion: "1.12", OS: "linux", Arch: "amd64"}, diag.Steps[4].Detail)
+	assert.Equal(t, diskReport{LayersSize: 100, VolumesSize: 10, BuildCacheSize: 5, Images: 2,
+		Volumes: 2}, diag.Steps[5].Detail)
+	assert.Equal(t, imageReport{Count: 2, Size: 7}, diag.Steps[6].Detail)
+	cli.AssertExpectations(t)
+}
+
+func TestFullDiagnosis_DaemonDown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{}, errors.New("connection refused")).Once()
+	cli.On("DaemonHost").Return("tcp://" + addr).Once()
+
+	diag := FullDiagnosis(context.Background(), "127.0.0.1", cli)
+	assert.False(t, diag.Healthy)
+	require.Len(t, diag.Steps, 2)
+	assert.False(t, diag.Steps[1].OK)
+	cli.AssertExpectations(t)
+}
+
+func TestHostDiagnoser_Diagnose(t *testing.T) {
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{}, errors.New("connection refused")).Once()
+	cli.On("DaemonHost").Return("tcp://127.0.0.1:1").Once()
+	cli.On("Close").Return(nil).Once()
+
+	docker := new(serviceMock.DockerService)
+	docker.On("CreateClient", "10.0.0.2").Return(cli, nil).Once()
+	docker.On("CreateClient", "10.0.0.3").Return(nil, errors.New("bad host")).Once()
+
+	diagnoser := NewHostDiagnoser(docker, time.Second, logrus.New())
+	diag := diagnoser.Diagnose(context.Background(), "10.0.0.2")
+	assert.Equal(t, "10.0.0.2", diag.Host)
+	assert.False(t, diag.Healthy)
+	require.Len(t, diag.Steps, 2)
+
+	diag = diagnoser.Diagnose(context.Background(), "10.0.0.3")
+	assert.False(t, diag.Healthy)
+	require.Len(t, diag.Steps, 1)
+	assert.Equal(t, "client", diag.Steps[0].Name)
+	assert.Equal(t, "bad host", diag.Steps[0].Error)
+
+	cli.AssertExpectations(t)
+	docker.AssertExpectations(t)
+}
+
+func TestNewDaemonEndpoint(t *testing.T) {
+	var tests = []struct {
+		daemonHost string
+		expected   daemonEndpoint
+	}{
+		{
+			daemonHost: "tcp://10.0.0.2:2376",
+			expected:   daemonEndpoint{network: "tcp", address: "10.0.0.2:2376", baseURL: "https://10.0.0.2:2376"},
+		},
+		{
+			daemonHost: "unix:///var/run/docker.sock",
+			expected:   daemonEndpoint{network: "unix", address: "/var/run/docker.sock", baseURL: "http://docker"},
+		},
+		{
+			daemonHost: "http://docker.example.com",
+			expected:   daemonEndpoint{baseURL: "http://docker.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.daemonHost, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newDaemonEndpoint(tt.daemonHost))
+		})
+	}
+}
+
+func TestDiagnoseClient_SSH(t *testing.T) {
+	//over ssh, the transport of the client dials the daemon, whatever the host of the url
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "docker.example.com", r.Host)
+		w.Write([]byte("OK"))
+	}))
+	defer srv.Close()
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "tcp", srv.Listener.Addr().String())
+		},
+	}}
+
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{}, errors.New("bad response")).Once()
+	cli.On("DaemonHost").Return("http://docker.example.com").Once()
+	cli.On("HTTPClient").Return(httpClient).Once()
+
+	diag := DiagnoseClient(context.Background(), "10.0.0.2", cli)
+	require.Len(t, diag.Steps, 3)
+	assert.Equal(t, "info", diag.Steps[1].Name)
+	assert.True(t, diag.Steps[1].OK)
+	assert.Equal(t, "rawPing", diag.Steps[2].Name)
+	assert.True(t, diag.Steps[2].OK)
+	cli.AssertExpectations(t)
+}
+
+func TestDiagnoseClient_Unix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{}, errors.New("bad response")).Once()
+	cli.On("DaemonHost").Return("unix://" + socket).Once()
+	cli.On("HTTPClient").Return(httpClient).Once()
+
+	diag := DiagnoseClient(context.Background(), "localhost", cli)
+	require.Len(t, diag.Steps, 4)
+	for _, step := range diag.Steps[1:] {
+		assert.True(t, step.OK, step.Name)
+	}
+	assert.Equal(t, "dial", diag.Steps[1].Name)
+	cli.AssertExpectations(t)
+}
@@ -10,9 +10,10 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
@@ -39,7 +40,7 @@ type DockerService interface {
 
 	//CreateContainer attempts to create a docker container
 	CreateContainer(ctx context.Context, cli entity.DockerCli,
-		container command.Container) entity.Result
+		container entity.Container) entity.Result
 
 	//StartContainer attempts to start an already created docker container
 	StartContainer(ctx context.Context, cli entity.DockerCli, sc command.StartContainer) entity.Result
@@ -48,7 +49,7 @@ type DockerService interface {
 	RemoveContainer(ctx context.Context, cli entity.DockerCli, name string) entity.Result
 
 	//CreateNetwork attempts to create a network
-	CreateNetwork(ctx context.Context, cli entity.DockerCli, net command.Network) entity.Result
+	CreateNetwork(ctx context.Context, cli entity.DockerCli, net entity.Network) entity.Result
 
 	//RemoveNetwork attempts to remove a network
 	RemoveNetwork(ctx context.Context, cli entity.DockerCli, name string) entity.Result
@@ -66,6 +67,10 @@ type DockerService interface {
 
 	//CreateClient creates a new client for connecting to the docker daemon
 	CreateClient(host string) (entity.Client, error)
+
+	//EvictClient discards the pooled client of the host if err shows that its connection
+	//failed, so that the next command for the host reconnects
+	EvictClient(host string, err error)
 }
 
 var (
@@ -79,53 +84,125 @@ const (
 )
 
 type dockerService struct {
-	repo   repository.DockerRepository
-	conf   config.Docker
-	log    logrus.Ext1FieldLogger
-	remote file.RemoteSources
+	repo     repository.DockerRepository
+	conf     config.Docker
+	log      logrus.Ext1FieldLogger
+	remote   file.RemoteSources
+	recorder repository.Recorder
+	pool     ClientPool
 }
 
-//NewDockerService creates a new DockerService
+//NewDockerService creates a new DockerService. If the config has a client idle timeout, the
+//clients it creates are pooled.
 func NewDockerService(
 	repo repository.DockerRepository,
 	conf config.Docker,
 	remote file.RemoteSources,
 	log logrus.Ext1FieldLogger) DockerService {
 
-	return dockerService{
+	out := dockerService{
 		conf:   conf,
 		repo:   repo,
 		remote: remote,
 		log:    log}
+	if conf.ClientIdleTimeout > 0 {
+		out.pool = NewClientPool(out.newClient, conf.ClientIdleTimeout,
+			conf.ClientPingInterval, log)
+	}
+	return out
 }
 
-func (ds dockerService) errorWhitelistHandler(err error, whitelist ...string) entity.Result {
+//NewDryRunDockerService creates a DockerService which records the calls it would make to the
+//docker hosts with rec, rather than making them
+func NewDryRunDockerService(
+	rec repository.Recorder,
+	conf config.Docker,
+	log logrus.Ext1FieldLogger) DockerService {
+
+	return dockerService{
+		conf:     conf,
+		repo:     repository.NewDockerRepository(log),
+		remote:   file.NewPlaceholderSources(log),
+		recorder: rec,
+		log:      log}
+}
+
+//ignoreErrors treats err as a success if docker gives it one of the codes, as the command has
+//then already been carried out
+func (ds dockerService) ignoreErrors(err error, codes ...entity.ErrorCode) entity.Result {
+	res := entity.NewResult(err, 1)
 	if err == nil {
-		return entity.NewResult(nil, 1)
+		return res
 	}
-	for _, entry := range whitelist {
-		if strings.Contains(err.Error(), entry) {
-			ds.log.WithField("error", err).Info("ignoring whitelisted error")
-			return entity.NewResult(nil, 1).InjectMeta(map[string]interface{}{
-				"error": err,
-			})
+	for _, code := range codes {
+		if res.ErrorCode() == code {
+			return ds.ignored(err)
 		}
 	}
-	return entity.NewResult(err, 1)
+	return res
+}
+
+func (ds dockerService) ignored(err error) entity.Result {
+	ds.log.WithField("error", err).Info("ignoring an error, as the command is already carried out")
+	return entity.NewResult(nil, 1).InjectMeta(map[string]interface{}{
+		"error": err,
+	})
 }
 
-// CreateClient creates a new client for connecting to the docker daemon
+// CreateClient creates a new client for connecting to the docker daemon, or reuses the pooled
+// client of the host
 func (ds dockerService) CreateClient(host string) (entity.Client, error) {
+	if ds.recorder != nil {
+		return ds.recorder.Client(host), nil
+	}
+	if ds.pool != nil {
+		return ds.pool.Get(host)
+	}
+	return ds.newClient(host)
+}
+
+// EvictClient discards the pooled client of the host if err shows that its connection failed
+func (ds dockerService) EvictClient(host string, err error) {
+	if ds.pool != nil {
+		ds.pool.Evict(host, err)
+	}
+}
+
+// newClient creates a new client for connecting to the docker daemon, which records how long
+// its calls take
+func (ds dockerService) newClient(host string) (entity.Client, error) {
+	cli, err := ds.dialClient(host)
+	if err != nil {
+		return nil, err
+	}
+	return NewInstrumentedClient(host, cli), nil
+}
+
+// dialClient creates a new docker client for the host. The daemon is connected to over TLS,
+// ssh or a unix socket, depending on the endpoint configured for the host.
+func (ds dockerService) dialClient(host string) (*client.Client, error) {
 	if ds.conf.LocalMode {
 		return client.NewClientWithOpts(
 			client.WithAPIVersionNegotiation(),
 		)
 	}
-	return client.NewClientWithOpts(
-		client.WithAPIVersionNegotiation(),
-		client.WithHost("tcp://"+host+":"+ds.conf.DaemonPort),
-		ds.repo.WithTLSClientConfig(ds.conf.CACertPath, ds.conf.CertPath, ds.conf.KeyPath),
-	)
+	dh := ds.conf.Host(host)
+	endpoint, err := url.Parse(dh.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+	switch endpoint.Scheme {
+	case "tcp":
+		opts = append(opts, client.WithHost(dh.Endpoint), ds.repo.WithTLSClientConfig(dh))
+	case "ssh":
+		opts = append(opts, ds.repo.WithSSHDialer(dh.Endpoint))
+	case "unix":
+		opts = append(opts, client.WithHost(dh.Endpoint))
+	default:
+		return nil, fmt.Errorf(`unsupported docker endpoint "%s" for host %s`, dh.Endpoint, host)
+	}
+	return client.NewClientWithOpts(opts...)
 }
 
 func (ds dockerService) withFields(cli entity.DockerCli, fields logrus.Fields) *logrus.Entry {
@@ -141,7 +218,7 @@ func (ds dockerService) withField(cli entity.DockerCli, key string, value interf
 
 //CreateContainer attempts to create a docker container
 func (ds dockerService) CreateContainer(ctx context.Context, cli entity.DockerCli,
-	dContainer command.Container) entity.Result {
+	dContainer entity.Container) entity.Result {
 
 	ds.withFields(cli, logrus.Fields{"container": dContainer}).Trace("create container")
 	errChan := make(chan error)
@@ -162,59 +239,142 @@ func (ds dockerService) CreateContainer(ctx context.Context, cli entity.DockerCl
 		Env:          dContainer.GetEnv(),
 		Image:        dContainer.Image,
 		Entrypoint:   dContainer.GetEntryPoint(),
+		Cmd:          dContainer.GetCmd(),
+		User:         dContainer.User,
+		WorkingDir:   dContainer.WorkingDir,
+		StopSignal:   dContainer.StopSignal,
+		StopTimeout:  dContainer.StopTimeout,
 		Labels:       cli.Labels,
 	}
 
-	mem, err := dContainer.GetMemory()
+	resources, err := dContainer.GetResources()
 	if err != nil {
 		return entity.NewFatalResult(err)
 	}
 
-	cpus, err := strconv.ParseFloat(dContainer.Cpus, 64)
+	shmSize, err := dContainer.GetShmSize()
 	if err != nil {
 		return entity.NewFatalResult(err).InjectMeta(map[string]interface{}{
-			"given": dContainer.Cpus,
+			"given": dContainer.ShmSize,
 		})
 	}
 
 	hostConfig := &container.HostConfig{
-		PortBindings: portMap,
-		AutoRemove:   true,
+		PortBindings:  portMap,
+		AutoRemove:    dContainer.GetAutoRemove(),
+		RestartPolicy: dContainer.GetRestartPolicy(),
 		LogConfig: container.LogConfig{
 			Type: ds.conf.LogDriver,
 			Config: map[string]string{
 				"labels": ds.conf.LogLabels,
 			},
 		},
-		Mounts: dContainer.GetMounts(),
+		Mounts:     dContainer.GetMounts(),
+		Resources:  resources,
+		Sysctls:    dContainer.Sysctls,
+		ShmSize:    shmSize,
+		Tmpfs:      dContainer.Tmpfs,
+		CapAdd:     dContainer.GetCapAdd(),
+		CapDrop:    dContainer.GetCapDrop(),
+		DNS:        dContainer.DNS,
+		DNSSearch:  dContainer.DNSSearch,
+		DNSOptions: dContainer.DNSOptions,
+		ExtraHosts: dContainer.ExtraHosts,
+	}
+
+	//docker only attaches one network on creation, the others are connected before returning
+	attachments := dContainer.GetNetworks()
+	networkConfig := &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{}}
+	if len(attachments) > 0 {
+		networkConfig.EndpointsConfig[attachments[0].Name] = attachments[0].EndpointSettings()
+		config.MacAddress = attachments[0].MacAddress
+	}
+	meta := map[string]interface{}{
+		"image":   dContainer.Image,
+		"name":    dContainer.Name,
+		"network": dContainer.Network,
+		"type":    "CreateContainer",
 	}
-	hostConfig.NanoCPUs = int64(1000000000 * cpus)
-	hostConfig.Memory = mem
 
-	networkConfig := &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{}}
-	if len(dContainer.Network) > 0 {
-		networkConfig.EndpointsConfig[dContainer.Network] = &network.EndpointSettings{
-			NetworkID: dContainer.Network,
-			IPAddress: dContainer.IP,
-		}
+	err = ds.checkAddresses(ctx, cli, attachments)
+	if err != nil {
+		<-errChan
+		return entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload).InjectMeta(meta)
 	}
 
 	err = <-errChan
 	if err != nil {
-		return entity.NewErrorResult(err)
+		return pullFailed(err)
 	}
 
 	_, err = cli.ContainerCreate(ctx, config, hostConfig, networkConfig, dContainer.Name)
-	res := ds.errorWhitelistHandler(err, "already in use by container")
+	res := ds.ignoreErrors(err, entity.CodeConflict)
+	for i := 1; i < len(attachments) && res.IsSuccess(); i++ {
+		err = cli.NetworkConnect(ctx, attachments[i].Name, dContainer.Name,
+			attachments[i].EndpointSettings())
+		res = ds.ignoreErrors(err, entity.CodeConflict)
+		if !res.IsSuccess() {
+			meta["failedNetwork"] = attachments[i].Name
+		}
+	}
 	if !res.IsSuccess() {
 		res = res.Fatal()
 	}
-	return res.InjectMeta(map[string]interface{}{
-		"image":   dContainer.Image,
-		"name":    dContainer.Name,
-		"network": dContainer.Network,
-		"type":    "CreateContainer",
-	})
+	return res.InjectMeta(meta)
+}
+
+//checkAddresses makes sure the static addresses of the attachments are within the subnets of
+//their networks. Networks which cannot be found are left for docker to report, as the overlay
+//networks of a swarm are not listed on a host until a container there uses them.
+func (ds dockerService) checkAddresses(ctx context.Context, cli entity.DockerCli,
+	attachments []entity.NetworkAttachment) error {
+
+	for _, attachment := range attachments {
+		if attachment.IPv4Address == "" && attachment.IPv6Address == "" {
+			continue
+		}
+		found, err := ds.repo.GetNetworkByName(ctx, cli, attachment.Name)
+		if err != nil {
+			ds.withFields(cli, logrus.Fields{"network": attachment.Name, "error": err}).Debug(
+				"not checking the addresses of a network which could not be found")
+			continue
+		}
+		for _, addr := range []string{attachment.IPv4Address, attachment.IPv6Address} {
+			err = checkSubnet(found, addr)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//checkSubnet makes sure that addr is within one of the subnets of the network of the same ip
+//version, if the network has any
+func checkSubnet(nw types.NetworkResource, addr string) error {
+	if addr == "" {
+		return nil
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return fmt.Errorf("invalid ip address %q", addr)
+	}
+	subnets := []string{}
+	for _, conf := range nw.IPAM.Config {
+		_, subnet, err := net.ParseCIDR(conf.Subnet)
+		if err != nil || (subnet.IP.To4() == nil) != (ip.To4() == nil) {
+			continue
+		}
+		if subnet.Contains(ip) {
+			return nil
+		}
+		subnets = append(subnets, subnet.String())
+	}
+	if len(subnets) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s is outside of the subnets %s of the network %q", addr,
+		strings.Join(subnets, ", "), nw.Name)
 }
 
 //StartContainer attempts to start an already created docker container
@@ -264,7 +424,7 @@ func (ds dockerService) StartContainer(ctx context.Context, cli entity.DockerCli
 
 	select {
 	case err := <-resChan:
-		return ds.errorWhitelistHandler(err, "No such container").InjectMeta(map[string]interface{}{
+		return ds.ignoreErrors(err, entity.CodeNotFound).InjectMeta(map[string]interface{}{
 			"name": sc.Name,
 			"type": "StartContainer",
 		})
@@ -291,39 +451,36 @@ func (ds dockerService) RemoveContainer(ctx context.Context, cli entity.DockerCl
 
 // CreateNetwork attempts to create a network
 func (ds dockerService) CreateNetwork(ctx context.Context, cli entity.DockerCli,
-	net command.Network) entity.Result {
+	net entity.Network) entity.Result {
 
 	networkCreate := types.NetworkCreate{
 		CheckDuplicate: true,
 		Attachable:     true,
 		Ingress:        false,
-		Internal:       false,
+		Internal:       net.Internal,
+		EnableIPv6:     net.GetEnableIPv6(),
 		Labels:         cli.Labels,
 		IPAM: &network.IPAM{
 			Driver:  "default",
 			Options: nil,
-			Config: []network.IPAMConfig{
-				network.IPAMConfig{
-					Subnet:  net.Subnet,
-					Gateway: net.Gateway,
-				},
-			},
+			Config:  net.GetIPAMConfig(),
 		},
-		Options: map[string]string{},
-	}
-	if net.Global {
-		networkCreate.Driver = "overlay"
-		networkCreate.Scope = "swarm"
-	} else {
-		networkCreate.Driver = "bridge"
-		networkCreate.Scope = "local"
-		networkCreate.Options["com.docker.network.bridge.name"] = net.Name
+		Driver:  net.GetDriver(),
+		Scope:   net.GetScope(),
+		Options: net.GetOptions(),
 	}
 	ds.withFields(cli, logrus.Fields{"name": net.Name,
 		"conf": networkCreate}).Debug("creating a network")
 	_, err := cli.NetworkCreate(ctx, net.Name, networkCreate)
-
-	return ds.errorWhitelistHandler(err, "already exists")
+	res := ds.ignoreErrors(err, entity.CodeConflict)
+	if res.ErrorCode() == entity.CodeUnauthorized {
+		//daemons before 20.10 forbid, rather than conflict with, a network which already exists
+		_, inspectErr := cli.NetworkInspect(ctx, net.Name, types.NetworkInspectOptions{})
+		if inspectErr == nil {
+			return ds.ignored(err)
+		}
+	}
+	return res
 }
 
 //RemoveNetwork attempts to remove a network
@@ -351,7 +508,7 @@ func (ds dockerService) AttachNetwork(ctx context.Context, cli entity.DockerCli,
 	ds.withField(cli, "cmd", cmd).Info("attaching a network")
 	macAddress, err := generateMacAddress()
 	if err != nil {
-		return ds.errorWhitelistHandler(err)
+		return ds.ignoreErrors(err)
 	}
 	err = cli.NetworkConnect(ctx, cmd.Network, cmd.Container, &network.EndpointSettings{
 		IPAMConfig: &network.EndpointIPAMConfig{
@@ -359,17 +516,24 @@ func (ds dockerService) AttachNetwork(ctx context.Context, cli entity.DockerCli,
 		},
 		MacAddress: macAddress,
 	})
-	return ds.errorWhitelistHandler(err,
-		"is already attached to network",
-		"Address already in use")
+	return ds.ignoreErrors(err, entity.CodeConflict)
 }
 
 func (ds dockerService) DetachNetwork(ctx context.Context, cli entity.DockerCli,
 	networkName string, containerName string) entity.Result {
 
 	err := cli.NetworkDisconnect(ctx, networkName, containerName, true)
-
-	return ds.errorWhitelistHandler(err, "is not connected to the network")
+	res := ds.ignoreErrors(err, entity.CodeNotFound)
+	if res.ErrorCode() == entity.CodeUnknown {
+		//docker does not give a type to the error from a container which is not connected
+		cntr, inspectErr := cli.ContainerInspect(ctx, containerName)
+		if inspectErr == nil && cntr.NetworkSettings != nil {
+			if _, ok := cntr.NetworkSettings.Networks[networkName]; !ok {
+				return ds.ignored(err)
+			}
+		}
+	}
+	return res
 }
 
 func (ds dockerService) CreateVolume(ctx context.Context, ecli entity.DockerCli,
@@ -573,13 +737,15 @@ func (ds dockerService) Emulation(ctx context.Context, cli entity.DockerCli,
 
 	err = <-errChan
 	if err != nil {
-		return entity.NewErrorResult(err)
+		return pullFailed(err)
 	}
 
+	iface, err := interfaceLookup(net)
+	if err != nil {
+		return entity.NewFatalResult(err)
+	}
 	name := netem.Container + "-" + net.ID
-	netemCmd := fmt.Sprintf(
-		"tc qdisc add dev $(ip -o addr show to %s | sed -n 's/.*\\(eth[0-9]*\\).*/\\1/p') root netem",
-		net.IPAM.Config[0].Subnet)
+	netemCmd := fmt.Sprintf("tc qdisc add dev %s root netem", iface)
 
 	if netem.Limit > 0 {
 		netemCmd += fmt.Sprintf(" limit %d", netem.Limit)
@@ -629,6 +795,29 @@ func (ds dockerService) Emulation(ctx context.Context, cli entity.DockerCli,
 	return ds.StartContainer(ctx, cli, command.StartContainer{Name: name})
 }
 
+//interfaceLookup creates the shell command which finds the interface of a container on the
+//network, from the address the container has within any of the ipv4 or ipv6 subnets of the network
+func interfaceLookup(nw types.NetworkResource) (string, error) {
+	lookups := []string{}
+	for _, conf := range nw.IPAM.Config {
+		_, subnet, err := net.ParseCIDR(conf.Subnet)
+		if err != nil {
+			continue
+		}
+		family := "-4"
+		if subnet.IP.To4() == nil {
+			family = "-6"
+		}
+		lookups = append(lookups, fmt.Sprintf("ip -o %s addr show to %s", family, subnet))
+	}
+	if len(lookups) == 0 {
+		return "", fmt.Errorf("network %q has no subnet to find the interface of a container with",
+			nw.Name)
+	}
+	return fmt.Sprintf("$({ %s; } | sed -n 's/.*\\(eth[0-9]*\\).*/\\1/p' | head -n 1)",
+		strings.Join(lookups, "; ")), nil
+}
+
 func (ds dockerService) SwarmCluster(ctx context.Context, entryCLI entity.DockerCli,
 	dswarm command.SetupSwarm) entity.Result {
 
@@ -696,8 +885,20 @@ func (ds dockerService) PullImage(ctx context.Context, cli entity.DockerCli,
 			"image": imagePull.Image,
 			"error": err,
 		}).Error("unable to pull an image")
+		return pullFailed(err)
 	}
-	return entity.NewResult(err)
+	return entity.NewSuccessResult()
+}
+
+//pullFailed creates the result for an image which could not be pulled. Failures to reach
+//the docker daemon keep their own code, as they are not caused by the image.
+func pullFailed(err error) entity.Result {
+	res := entity.NewErrorResult(err)
+	switch res.ErrorCode() {
+	case entity.CodeDaemonUnreachable, entity.CodeTimeout:
+		return res
+	}
+	return res.WithCode(entity.CodeImagePullFailed)
 }
 
 func (ds dockerService) mkConfigs() (*container.Config, *container.HostConfig, *network.NetworkingConfig, string) {
@@ -746,7 +947,7 @@ func (ds dockerService) VolumeShare(ctx context.Context, ecli entity.DockerCli,
 	for range vs.Hosts {
 		err := <-errChan
 		if err != nil {
-			return entity.NewErrorResult(err)
+			return pullFailed(err)
 		}
 	}
 
@@ -7,8 +7,11 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	//"strings"
+	"strconv"
+	"strings"
 	"testing"
 
 	entityMock "github.com/whiteblock/genesis/mocks/pkg/entity"
@@ -23,6 +26,8 @@ import (
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	dockerVolume "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -37,7 +42,7 @@ func TestNewDockerService(t *testing.T) {
 
 func TestDockerService_CreateContainer(t *testing.T) {
 	testNetwork := types.NetworkResource{Name: "Testnet", ID: "id1"}
-	testContainer := command.Container{
+	testContainer := entity.Container{Container: command.Container{
 		EntryPoint: "/bin/bash",
 		Environment: map[string]string{
 			"FOO": "BAR",
@@ -48,7 +53,7 @@ func TestDockerService_CreateContainer(t *testing.T) {
 		Volumes: []command.Mount{{Name: "volume1", Directory: "/foo/bar", ReadOnly: false}},
 		Image:   "alpine",
 		Args:    []string{"test"},
-	}
+	}}
 	testContainer.Cpus = "2.5"
 	testContainer.Memory = "5gb"
 
@@ -99,6 +104,11 @@ func TestDockerService_CreateContainer(t *testing.T) {
 				assert.Nil(t, hostConfig.Mounts[i].BindOptions)
 			}
 			assert.True(t, hostConfig.AutoRemove)
+			assert.True(t, hostConfig.RestartPolicy.IsNone())
+			assert.Equal(t, container.Resources{NanoCPUs: 2500000000, Memory: 5 * utils.Gibi},
+				hostConfig.Resources)
+			assert.Nil(t, hostConfig.CapAdd)
+			assert.Zero(t, hostConfig.ShmSize)
 		}
 		{
 			networkingConfig, ok := args.Get(3).(*network.NetworkingConfig)
@@ -144,6 +154,195 @@ func TestDockerService_CreateContainer(t *testing.T) {
 	assert.NoError(t, res.Error)
 }
 
+func TestDockerService_CreateContainer_Resources(t *testing.T) {
+	testContainer := entity.Container{
+		Container: command.Container{Name: "TEST", Image: "alpine", Cpus: "1", Memory: "1gb",
+			BoundCPUs: []int{0, 2}},
+		Resources: entity.Resources{
+			CPUShares:         512,
+			CPUPeriod:         100000,
+			CPUQuota:          50000,
+			MemorySwap:        "-1",
+			MemoryReservation: "512mb",
+			PidsLimit:         100,
+			Ulimits:           []entity.Ulimit{{Name: "nofile", Soft: 1024, Hard: 4096}},
+			BlkioWeight:       300,
+			DeviceReadBps:     []entity.DeviceRate{{Path: "/dev/sda", Rate: "1mb"}},
+			DeviceWriteBps:    []entity.DeviceRate{{Path: "/dev/sdb", Rate: "2mb"}},
+		},
+		Sysctls: map[string]string{"net.core.somaxconn": "1024"},
+		ShmSize: "64mb",
+		Tmpfs:   map[string]string{"/run": "rw"},
+		CapAdd:  []string{"NET_ADMIN"},
+		CapDrop: []string{"MKNOD"},
+	}
+
+	cli := new(entityMock.Client)
+	cli.On("ContainerCreate", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		testContainer.Name).Return(container.ContainerCreateCreatedBody{}, nil).Run(
+		func(args mock.Arguments) {
+			hostConfig := args.Get(2).(*container.HostConfig)
+			res := hostConfig.Resources
+			assert.Equal(t, int64(1000000000), res.NanoCPUs)
+			assert.Equal(t, int64(utils.Gibi), res.Memory)
+			assert.Equal(t, "0,2", res.CpusetCpus)
+			assert.Equal(t, int64(512), res.CPUShares)
+			assert.Equal(t, int64(100000), res.CPUPeriod)
+			assert.Equal(t, int64(50000), res.CPUQuota)
+			assert.Equal(t, int64(-1), res.MemorySwap)
+			assert.Equal(t, int64(512*utils.Mibi), res.MemoryReservation)
+			require.NotNil(t, res.PidsLimit)
+			assert.Equal(t, int64(100), *res.PidsLimit)
+			require.Len(t, res.Ulimits, 1)
+			assert.Equal(t, "nofile=1024:4096", res.Ulimits[0].String())
+			assert.Equal(t, uint16(300), res.BlkioWeight)
+			require.Len(t, res.BlkioDeviceReadBps, 1)
+			assert.Equal(t, uint64(utils.Mibi), res.BlkioDeviceReadBps[0].Rate)
+			require.Len(t, res.BlkioDeviceWriteBps, 1)
+			assert.Equal(t, "/dev/sdb", res.BlkioDeviceWriteBps[0].Path)
+
+			assert.Equal(t, testContainer.Sysctls, hostConfig.Sysctls)
+			assert.Equal(t, int64(64*utils.Mibi), hostConfig.ShmSize)
+			assert.Equal(t, testContainer.Tmpfs, hostConfig.Tmpfs)
+			assert.Equal(t, []string{"NET_ADMIN"}, []string(hostConfig.CapAdd))
+			assert.Equal(t, []string{"MKNOD"}, []string(hostConfig.CapDrop))
+		}).Once()
+
+	repo := new(repoMock.DockerRepository)
+	repo.On("EnsureImagePulled", mock.Anything, mock.Anything, "alpine", "").Return(nil).Once()
+
+	ds := NewDockerService(repo, config.Docker{}, nil, logrus.New())
+	res := ds.CreateContainer(context.Background(), entity.DockerCli{Client: cli}, testContainer)
+	assert.NoError(t, res.Error)
+	cli.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestDockerService_CreateContainer_Runtime(t *testing.T) {
+	timeout := 30
+	testContainer := entity.Container{
+		Container: command.Container{Name: "TEST", Image: "alpine", Cpus: "1", Memory: "1gb",
+			Args: []string{"--dev"}},
+		User:          "1000",
+		WorkingDir:    "/data",
+		StopSignal:    "SIGINT",
+		StopTimeout:   &timeout,
+		DNS:           []string{"10.0.0.1"},
+		DNSSearch:     []string{"testnet"},
+		DNSOptions:    []string{"ndots:1"},
+		ExtraHosts:    []string{"bootnode:10.0.0.2"},
+		RestartPolicy: entity.RestartPolicy{Name: "on-failure", MaximumRetryCount: 5},
+	}
+
+	cli := new(entityMock.Client)
+	cli.On("ContainerCreate", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		testContainer.Name).Return(container.ContainerCreateCreatedBody{}, nil).Run(
+		func(args mock.Arguments) {
+			config := args.Get(1).(*container.Config)
+			assert.Nil(t, config.Entrypoint)
+			assert.Equal(t, []string{"--dev"}, []string(config.Cmd))
+			assert.Equal(t, "1000", config.User)
+			assert.Equal(t, "/data", config.WorkingDir)
+			assert.Equal(t, "SIGINT", config.StopSignal)
+			assert.Equal(t, &timeout, config.StopTimeout)
+
+			hostConfig := args.Get(2).(*container.HostConfig)
+			assert.False(t, hostConfig.AutoRemove)
+			assert.Equal(t, container.RestartPolicy{Name: "on-failure", MaximumRetryCount: 5},
+				hostConfig.RestartPolicy)
+			assert.Equal(t, testContainer.DNS, hostConfig.DNS)
+			assert.Equal(t, testContainer.DNSSearch, hostConfig.DNSSearch)
+			assert.Equal(t, testContainer.DNSOptions, hostConfig.DNSOptions)
+			assert.Equal(t, testContainer.ExtraHosts, hostConfig.ExtraHosts)
+		}).Once()
+
+	repo := new(repoMock.DockerRepository)
+	repo.On("EnsureImagePulled", mock.Anything, mock.Anything, "alpine", "").Return(nil).Once()
+
+	ds := NewDockerService(repo, config.Docker{}, nil, logrus.New())
+	res := ds.CreateContainer(context.Background(), entity.DockerCli{Client: cli}, testContainer)
+	assert.NoError(t, res.Error)
+	cli.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestDockerService_CreateContainer_Networks(t *testing.T) {
+	testContainer := entity.Container{
+		Container: command.Container{Name: "TEST", Image: "alpine", Cpus: "1", Memory: "1gb",
+			Network: "public", IP: "10.0.0.2"},
+		Networks: []entity.NetworkAttachment{
+			{Name: "private", IPv6Address: "fd00::2", Aliases: []string{"node"}},
+			{Name: "overlay", MacAddress: "02:42:ac:11:00:02"},
+		},
+	}
+	public := types.NetworkResource{Name: "public",
+		IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "10.0.0.0/24"}}}}
+	private := types.NetworkResource{Name: "private",
+		IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "10.1.0.0/24"}, {Subnet: "fd00::/64"}}}}
+
+	cli := new(entityMock.Client)
+	cli.On("ContainerCreate", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		testContainer.Name).Return(container.ContainerCreateCreatedBody{}, nil).Run(
+		func(args mock.Arguments) {
+			netConf := args.Get(3).(*network.NetworkingConfig)
+			require.Len(t, netConf.EndpointsConfig, 1)
+			require.Contains(t, netConf.EndpointsConfig, "public")
+			assert.Equal(t, "10.0.0.2", netConf.EndpointsConfig["public"].IPAddress)
+			assert.Equal(t, "10.0.0.2", netConf.EndpointsConfig["public"].IPAMConfig.IPv4Address)
+		}).Once()
+	cli.On("NetworkConnect", mock.Anything, "private", testContainer.Name, mock.Anything).Return(
+		nil).Run(func(args mock.Arguments) {
+		endpoint := args.Get(3).(*network.EndpointSettings)
+		assert.Equal(t, "fd00::2", endpoint.IPAMConfig.IPv6Address)
+		assert.Equal(t, []string{"node"}, endpoint.Aliases)
+	}).Once()
+	alreadyAttached := errdefs.Conflict(
+		errors.New("endpoint with name TEST is already attached to network overlay"))
+	cli.On("NetworkConnect", mock.Anything, "overlay", testContainer.Name, mock.Anything).Return(
+		alreadyAttached).Run(
+		func(args mock.Arguments) {
+			endpoint := args.Get(3).(*network.EndpointSettings)
+			assert.Nil(t, endpoint.IPAMConfig)
+			assert.Equal(t, "02:42:ac:11:00:02", endpoint.MacAddress)
+		}).Once()
+
+	repo := new(repoMock.DockerRepository)
+	repo.On("EnsureImagePulled", mock.Anything, mock.Anything, "alpine", "").Return(nil).Once()
+	repo.On("GetNetworkByName", mock.Anything, mock.Anything, "public").Return(public, nil).Once()
+	repo.On("GetNetworkByName", mock.Anything, mock.Anything, "private").Return(private, nil).Once()
+
+	ds := NewDockerService(repo, config.Docker{}, nil, logrus.New())
+	res := ds.CreateContainer(context.Background(), entity.DockerCli{Client: cli}, testContainer)
+	assert.NoError(t, res.Error)
+	cli.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestDockerService_CreateContainer_AddressOutsideSubnet(t *testing.T) {
+	testContainer := entity.Container{
+		Container: command.Container{Name: "TEST", Image: "alpine", Cpus: "1", Memory: "1gb"},
+		Networks: []entity.NetworkAttachment{
+			{Name: "unknown", IPv4Address: "192.168.0.2"},
+			{Name: "private", IPv4Address: "10.1.0.2", IPv6Address: "fd01::2"},
+		},
+	}
+	private := types.NetworkResource{Name: "private",
+		IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: "10.1.0.0/24"}, {Subnet: "fd00::/64"}}}}
+
+	repo := new(repoMock.DockerRepository)
+	repo.On("EnsureImagePulled", mock.Anything, mock.Anything, "alpine", "").Return(nil).Once()
+	repo.On("GetNetworkByName", mock.Anything, mock.Anything, "unknown").Return(
+		types.NetworkResource{}, errors.New("not found")).Once()
+	repo.On("GetNetworkByName", mock.Anything, mock.Anything, "private").Return(private, nil).Once()
+
+	ds := NewDockerService(repo, config.Docker{}, nil, logrus.New())
+	res := ds.CreateContainer(context.Background(), entity.DockerCli{Client: new(entityMock.Client)},
+		testContainer)
+	assert.EqualError(t, res.Error, `fd01::2 is outside of the subnets fd00::/64 of the network "private"`)
+	assert.True(t, res.IsFatal())
+	repo.AssertExpectations(t)
+}
+
 func TestDockerService_StartContainer_Success(t *testing.T) {
 	scCommand := command.StartContainer{Name: "TEST"}
 	cli := new(entityMock.Client)
@@ -177,12 +376,12 @@ func TestDockerService_StartContainer_Success(t *testing.T) {
 }
 
 func TestDockerService_CreateNetwork_Success(t *testing.T) {
-	testNetwork := command.Network{
+	testNetwork := entity.Network{Network: command.Network{
 		Name:    "testnet",
 		Global:  true,
 		Gateway: "10.14.0.1",
 		Subnet:  "10.14.0.0/16",
-	}
+	}}
 	cli := new(entityMock.Client)
 	cli.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
 		types.NetworkCreateResponse{}, nil).Run(func(args mock.Arguments) {
@@ -245,8 +444,91 @@ func TestDockerService_CreateNetwork_Success(t *testing.T) {
 	cli.AssertExpectations(t)
 }
 
+func TestDockerService_CreateNetwork_DualStack(t *testing.T) {
+	testNetwork := entity.Network{
+		Network:      command.Network{Name: "testnet", Subnet: "10.14.0.0/16", Gateway: "10.14.0.1"},
+		IPRange:      "10.14.1.0/24",
+		AuxAddresses: map[string]string{"router": "10.14.0.2"},
+		Subnets:      []entity.Subnet{{Subnet: "fd00:14::/64", Gateway: "fd00:14::1"}},
+	}
+	cli := new(entityMock.Client)
+	cli.On("NetworkCreate", mock.Anything, testNetwork.Name, mock.Anything).Return(
+		types.NetworkCreateResponse{}, nil).Run(func(args mock.Arguments) {
+		networkCreate := args.Get(2).(types.NetworkCreate)
+		assert.True(t, networkCreate.EnableIPv6)
+		require.NotNil(t, networkCreate.IPAM)
+		assert.Equal(t, []network.IPAMConfig{
+			{Subnet: "10.14.0.0/16", Gateway: "10.14.0.1", IPRange: "10.14.1.0/24",
+				AuxAddress: map[string]string{"router": "10.14.0.2"}},
+			{Subnet: "fd00:14::/64", Gateway: "fd00:14::1"},
+		}, networkCreate.IPAM.Config)
+	}).Once()
+
+	ds := NewDockerService(new(repoMock.DockerRepository), config.Docker{}, nil, logrus.New())
+	res := ds.CreateNetwork(context.Background(), entity.DockerCli{Client: cli}, testNetwork)
+	assert.NoError(t, res.Error)
+	cli.AssertExpectations(t)
+}
+
+func TestDockerService_CreateNetwork_Drivers(t *testing.T) {
+	var tests = []struct {
+		nw       entity.Network
+		driver   string
+		scope    string
+		internal bool
+		options  map[string]string
+	}{
+		{
+			nw: entity.Network{Network: command.Network{Name: "airgap"}, Internal: true, MTU: 1400,
+				Options: map[string]string{"com.docker.network.bridge.enable_icc": "true"}},
+			driver:   "bridge",
+			scope:    "local",
+			internal: true,
+			options: map[string]string{
+				"com.docker.network.bridge.name":       "airgap",
+				"com.docker.network.bridge.enable_icc": "true",
+				"com.docker.network.driver.mtu":        "1400",
+			},
+		},
+		{
+			nw: entity.Network{Network: command.Network{Name: "lan"}, Driver: "macvlan",
+				Parent: "eth0.10", Mode: "bridge"},
+			driver:  "macvlan",
+			scope:   "local",
+			options: map[string]string{"parent": "eth0.10", "macvlan_mode": "bridge"},
+		},
+		{
+			nw: entity.Network{Network: command.Network{Name: "l3"}, Driver: "ipvlan",
+				Parent: "eth1", Mode: "l3", Internal: true},
+			driver:   "ipvlan",
+			scope:    "local",
+			internal: true,
+			options:  map[string]string{"parent": "eth1", "ipvlan_mode": "l3"},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cli := new(entityMock.Client)
+			cli.On("NetworkCreate", mock.Anything, tt.nw.Name, mock.Anything).Return(
+				types.NetworkCreateResponse{}, nil).Run(func(args mock.Arguments) {
+				networkCreate := args.Get(2).(types.NetworkCreate)
+				assert.Equal(t, tt.driver, networkCreate.Driver)
+				assert.Equal(t, tt.scope, networkCreate.Scope)
+				assert.Equal(t, tt.internal, networkCreate.Internal)
+				assert.Equal(t, tt.options, networkCreate.Options)
+			}).Once()
+
+			ds := NewDockerService(new(repoMock.DockerRepository), config.Docker{}, nil, logrus.New())
+			res := ds.CreateNetwork(context.Background(), entity.DockerCli{Client: cli}, tt.nw)
+			assert.NoError(t, res.Error)
+			cli.AssertExpectations(t)
+		})
+	}
+}
+
 func TestDockerService_CreateNetwork_Failure(t *testing.T) {
-	testNetwork := command.Network{
+	testNetwork := entity.Network{Network: command.Network{
 		Name:   "testnet",
 		Global: true,
 		Labels: map[string]string{
@@ -254,7 +536,7 @@ func TestDockerService_CreateNetwork_Failure(t *testing.T) {
 		},
 		Gateway: "10.14.0.1",
 		Subnet:  "10.14.0.0/16",
-	}
+	}}
 	cli := new(entityMock.Client)
 	cli.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything).Return(
 		types.NetworkCreateResponse{}, fmt.Errorf("error")).Once()
@@ -407,6 +689,92 @@ func TestDockerService_DetachNetwork(t *testing.T) {
 	cli.AssertExpectations(t)
 }
 
+func TestDockerService_IgnoreErrors(t *testing.T) {
+	var tests = []struct {
+		err     error
+		codes   []entity.ErrorCode
+		ignored bool
+	}{
+		{err: nil, ignored: true},
+		{err: errdefs.Conflict(errors.New("is already attached to network")),
+			codes: []entity.ErrorCode{entity.CodeConflict}, ignored: true},
+		{err: errdefs.NotFound(errors.New("No such container")),
+			codes: []entity.ErrorCode{entity.CodeConflict}, ignored: false},
+		{err: errors.New("is already attached to network"),
+			codes: []entity.ErrorCode{entity.CodeConflict}, ignored: false},
+		{err: errdefs.Conflict(errors.New("conflict")), ignored: false},
+	}
+
+	ds := NewDockerService(nil, config.Docker{}, nil, logrus.New()).(dockerService)
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := ds.ignoreErrors(tt.err, tt.codes...)
+			assert.Equal(t, tt.ignored, res.IsSuccess())
+		})
+	}
+}
+
+func TestDockerService_CreateNetwork_AlreadyExists(t *testing.T) {
+	forbidden := errdefs.Forbidden(errors.New("network with name testnet already exists"))
+	var tests = []struct {
+		err     error
+		exists  bool
+		ignored bool
+	}{
+		{err: errdefs.Conflict(errors.New("already exists")), ignored: true},
+		{err: forbidden, exists: true, ignored: true},
+		{err: forbidden, exists: false, ignored: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cli := new(entityMock.Client)
+			cli.On("NetworkCreate", mock.Anything, "testnet", mock.Anything).Return(
+				types.NetworkCreateResponse{}, tt.err).Once()
+			if errdefs.IsForbidden(tt.err) {
+				var inspectErr error
+				if !tt.exists {
+					inspectErr = errdefs.NotFound(errors.New("not found"))
+				}
+				cli.On("NetworkInspect", mock.Anything, "testnet", mock.Anything).Return(
+					types.NetworkResource{}, inspectErr).Once()
+			}
+
+			ds := NewDockerService(nil, config.Docker{}, nil, logrus.New())
+			res := ds.CreateNetwork(context.Background(), entity.DockerCli{Client: cli},
+				entity.Network{Network: command.Network{Name: "testnet"}})
+			assert.Equal(t, tt.ignored, res.IsSuccess())
+			cli.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDockerService_DetachNetwork_NotConnected(t *testing.T) {
+	var tests = []struct {
+		networks map[string]*network.EndpointSettings
+		ignored  bool
+	}{
+		{networks: map[string]*network.EndpointSettings{}, ignored: true},
+		{networks: map[string]*network.EndpointSettings{"test2": {}}, ignored: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			cli := new(entityMock.Client)
+			cli.On("NetworkDisconnect", mock.Anything, "test2", "test1", true).Return(
+				errors.New("container test1 is not connected to the network test2")).Once()
+			cli.On("ContainerInspect", mock.Anything, "test1").Return(types.ContainerJSON{
+				NetworkSettings: &types.NetworkSettings{Networks: tt.networks},
+			}, nil).Once()
+
+			ds := NewDockerService(nil, config.Docker{}, nil, logrus.New())
+			res := ds.DetachNetwork(context.Background(), entity.DockerCli{Client: cli}, "test2", "test1")
+			assert.Equal(t, tt.ignored, res.IsSuccess())
+			cli.AssertExpectations(t)
+		})
+	}
+}
+
 func TestDockerService_CreateVolume(t *testing.T) {
 	volume := types.Volume{
 		Name:   "test_volume",
@@ -464,9 +832,71 @@ func TestDockerService_RemoveVolume(t *testing.T) {
 	cli.AssertExpectations(t)
 }
 
+func TestDockerService_PullImage(t *testing.T) {
+	var tests = []struct {
+		err      error
+		expected entity.ErrorCode
+	}{
+		{err: nil, expected: ""},
+		{err: fmt.Errorf("manifest unknown"), expected: entity.CodeImagePullFailed},
+		{err: errdefs.NotFound(fmt.Errorf("no such image")), expected: entity.CodeImagePullFailed},
+		{err: client.ErrorConnectionFailed("10.0.0.2"), expected: entity.CodeDaemonUnreachable},
+		{err: context.DeadlineExceeded, expected: entity.CodeTimeout},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			repo := new(repoMock.DockerRepository)
+			repo.On("EnsureImagePulled", mock.Anything, mock.Anything, "test", "").Return(tt.err).Once()
+
+			ds := NewDockerService(repo, config.Docker{}, nil, logrus.New())
+			res := ds.PullImage(nil, entity.DockerCli{}, command.PullImage{Image: "test"})
+			assert.Equal(t, tt.err == nil, res.IsSuccess())
+			assert.Equal(t, tt.expected, res.ErrorCode())
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestRandomMacAddress(t *testing.T) {
 	_, err := generateMacAddress()
 	if err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestInterfaceLookup(t *testing.T) {
+	var tests = []struct {
+		configs  []network.IPAMConfig
+		expected string
+	}{
+		{
+			configs:  []network.IPAMConfig{{Subnet: "10.0.0.0/24"}},
+			expected: "{ ip -o -4 addr show to 10.0.0.0/24; }",
+		},
+		{
+			configs:  []network.IPAMConfig{{Subnet: "fd00::/64"}},
+			expected: "{ ip -o -6 addr show to fd00::/64; }",
+		},
+		{
+			configs:  []network.IPAMConfig{{Subnet: "10.0.0.0/24"}, {Subnet: "fd00::/64"}},
+			expected: "{ ip -o -4 addr show to 10.0.0.0/24; ip -o -6 addr show to fd00::/64; }",
+		},
+		{configs: []network.IPAMConfig{}, expected: ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			lookup, err := interfaceLookup(types.NetworkResource{Name: "n",
+				IPAM: network.IPAM{Config: tt.configs}})
+			if tt.expected == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, lookup, tt.expected)
+			assert.True(t, strings.HasSuffix(lookup, "| head -n 1)"), lookup)
+		})
+	}
+}
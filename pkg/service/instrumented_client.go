@@ -0,0 +1,321 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/metrics"
+	"github.com/whiteblock/genesis/pkg/tracing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/api/types/volume"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//instrumentedClient records how long each call to the docker api of a host takes, and traces it
+type instrumentedClient struct {
+	entity.Client
+	host string
+}
+
+//NewInstrumentedClient wraps cli, so that the latency of its calls to the docker daemon of the
+//given host are recorded
+func NewInstrumentedClient(host string, cli entity.Client) entity.Client {
+	return &instrumentedClient{Client: cli, host: host}
+}
+
+func (ic instrumentedClient) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "docker."+operation, attribute.String("genesis.docker.host", ic.host))
+}
+
+func (ic instrumentedClient) observe(span trace.Span, operation string, start time.Time, err error) {
+	metrics.ObserveDockerRequest(ic.host, operation, start, err)
+	tracing.EndWithError(span, err)
+}
+
+//ContainerAttach calls ContainerAttach of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerAttach(ctx context.Context, container string, options types.ContainerAttachOptions) (types.HijackedResponse, error) {
+	ctx, span := ic.start(ctx, "ContainerAttach")
+	start := time.Now()
+	out, err := ic.Client.ContainerAttach(ctx, container, options)
+	ic.observe(span, "ContainerAttach", start, err)
+	return out, err
+}
+
+//ContainerCreate calls ContainerCreate of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig,
+	networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+
+	ctx, span := ic.start(ctx, "ContainerCreate")
+	start := time.Now()
+	out, err := ic.Client.ContainerCreate(ctx, config, hostConfig, networkingConfig, containerName)
+	ic.observe(span, "ContainerCreate", start, err)
+	return out, err
+}
+
+//ContainerExecAttach calls ContainerExecAttach of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+	ctx, span := ic.start(ctx, "ContainerExecAttach")
+	start := time.Now()
+	out, err := ic.Client.ContainerExecAttach(ctx, execID, config)
+	ic.observe(span, "ContainerExecAttach", start, err)
+	return out, err
+}
+
+//ContainerExecCreate calls ContainerExecCreate of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerExecCreate(ctx context.Context, container string, config types.ExecConfig) (types.IDResponse, error) {
+	ctx, span := ic.start(ctx, "ContainerExecCreate")
+	start := time.Now()
+	out, err := ic.Client.ContainerExecCreate(ctx, container, config)
+	ic.observe(span, "ContainerExecCreate", start, err)
+	return out, err
+}
+
+//ContainerExecInspect calls ContainerExecInspect of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	ctx, span := ic.start(ctx, "ContainerExecInspect")
+	start := time.Now()
+	out, err := ic.Client.ContainerExecInspect(ctx, execID)
+	ic.observe(span, "ContainerExecInspect", start, err)
+	return out, err
+}
+
+//ContainerExecStart calls ContainerExecStart of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerExecStart(ctx context.Context, execID string, config types.ExecStartCheck) error {
+	ctx, span := ic.start(ctx, "ContainerExecStart")
+	start := time.Now()
+	err := ic.Client.ContainerExecStart(ctx, execID, config)
+	ic.observe(span, "ContainerExecStart", start, err)
+	return err
+}
+
+//ContainerInspect calls ContainerInspect of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	ctx, span := ic.start(ctx, "ContainerInspect")
+	start := time.Now()
+	out, err := ic.Client.ContainerInspect(ctx, containerID)
+	ic.observe(span, "ContainerInspect", start, err)
+	return out, err
+}
+
+//ContainerList calls ContainerList of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	ctx, span := ic.start(ctx, "ContainerList")
+	start := time.Now()
+	out, err := ic.Client.ContainerList(ctx, options)
+	ic.observe(span, "ContainerList", start, err)
+	return out, err
+}
+
+//ContainerRemove calls ContainerRemove of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	ctx, span := ic.start(ctx, "ContainerRemove")
+	start := time.Now()
+	err := ic.Client.ContainerRemove(ctx, containerID, options)
+	ic.observe(span, "ContainerRemove", start, err)
+	return err
+}
+
+//ContainerStart calls ContainerStart of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error {
+	ctx, span := ic.start(ctx, "ContainerStart")
+	start := time.Now()
+	err := ic.Client.ContainerStart(ctx, containerID, options)
+	ic.observe(span, "ContainerStart", start, err)
+	return err
+}
+
+//ContainerStatPath calls ContainerStatPath of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ContainerStatPath(ctx context.Context, containerID, path string) (types.ContainerPathStat, error) {
+	ctx, span := ic.start(ctx, "ContainerStatPath")
+	start := time.Now()
+	out, err := ic.Client.ContainerStatPath(ctx, containerID, path)
+	ic.observe(span, "ContainerStatPath", start, err)
+	return out, err
+}
+
+//CopyToContainer calls CopyToContainer of the wrapped client, recording how long it takes
+func (ic instrumentedClient) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader,
+	options types.CopyToContainerOptions) error {
+
+	ctx, span := ic.start(ctx, "CopyToContainer")
+	start := time.Now()
+	err := ic.Client.CopyToContainer(ctx, containerID, dstPath, content, options)
+	ic.observe(span, "CopyToContainer", start, err)
+	return err
+}
+
+//DiskUsage calls DiskUsage of the wrapped client, recording how long it takes
+func (ic instrumentedClient) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	ctx, span := ic.start(ctx, "DiskUsage")
+	start := time.Now()
+	out, err := ic.Client.DiskUsage(ctx)
+	ic.observe(span, "DiskUsage", start, err)
+	return out, err
+}
+
+//ImageList calls ImageList of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	ctx, span := ic.start(ctx, "ImageList")
+	start := time.Now()
+	out, err := ic.Client.ImageList(ctx, options)
+	ic.observe(span, "ImageList", start, err)
+	return out, err
+}
+
+//ImageLoad calls ImageLoad of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	ctx, span := ic.start(ctx, "ImageLoad")
+	start := time.Now()
+	out, err := ic.Client.ImageLoad(ctx, input, quiet)
+	ic.observe(span, "ImageLoad", start, err)
+	return out, err
+}
+
+//ImagePull calls ImagePull of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ImagePull(ctx context.Context, refStr string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	ctx, span := ic.start(ctx, "ImagePull")
+	start := time.Now()
+	out, err := ic.Client.ImagePull(ctx, refStr, options)
+	ic.observe(span, "ImagePull", start, err)
+	return out, err
+}
+
+//NetworkCreate calls NetworkCreate of the wrapped client, recording how long it takes
+func (ic instrumentedClient) NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	ctx, span := ic.start(ctx, "NetworkCreate")
+	start := time.Now()
+	out, err := ic.Client.NetworkCreate(ctx, name, options)
+	ic.observe(span, "NetworkCreate", start, err)
+	return out, err
+}
+
+//NetworkConnect calls NetworkConnect of the wrapped client, recording how long it takes
+func (ic instrumentedClient) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	ctx, span := ic.start(ctx, "NetworkConnect")
+	start := time.Now()
+	err := ic.Client.NetworkConnect(ctx, networkID, containerID, config)
+	ic.observe(span, "NetworkConnect", start, err)
+	return err
+}
+
+//NetworkDisconnect calls NetworkDisconnect of the wrapped client, recording how long it takes
+func (ic instrumentedClient) NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error {
+	ctx, span := ic.start(ctx, "NetworkDisconnect")
+	start := time.Now()
+	err := ic.Client.NetworkDisconnect(ctx, networkID, containerID, force)
+	ic.observe(span, "NetworkDisconnect", start, err)
+	return err
+}
+
+//NetworkInspect calls NetworkInspect of the wrapped client, recording how long it takes
+func (ic instrumentedClient) NetworkInspect(ctx context.Context, networkID string, options types.NetworkInspectOptions) (types.NetworkResource, error) {
+	ctx, span := ic.start(ctx, "NetworkInspect")
+	start := time.Now()
+	out, err := ic.Client.NetworkInspect(ctx, networkID, options)
+	ic.observe(span, "NetworkInspect", start, err)
+	return out, err
+}
+
+//NetworkRemove calls NetworkRemove of the wrapped client, recording how long it takes
+func (ic instrumentedClient) NetworkRemove(ctx context.Context, networkID string) error {
+	ctx, span := ic.start(ctx, "NetworkRemove")
+	start := time.Now()
+	err := ic.Client.NetworkRemove(ctx, networkID)
+	ic.observe(span, "NetworkRemove", start, err)
+	return err
+}
+
+//NetworkList calls NetworkList of the wrapped client, recording how long it takes
+func (ic instrumentedClient) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	ctx, span := ic.start(ctx, "NetworkList")
+	start := time.Now()
+	out, err := ic.Client.NetworkList(ctx, options)
+	ic.observe(span, "NetworkList", start, err)
+	return out, err
+}
+
+//Ping calls Ping of the wrapped client, recording how long it takes
+func (ic instrumentedClient) Ping(ctx context.Context) (types.Ping, error) {
+	ctx, span := ic.start(ctx, "Ping")
+	start := time.Now()
+	out, err := ic.Client.Ping(ctx)
+	ic.observe(span, "Ping", start, err)
+	return out, err
+}
+
+//ServerVersion calls ServerVersion of the wrapped client, recording how long it takes
+func (ic instrumentedClient) ServerVersion(ctx context.Context) (types.Version, error) {
+	ctx, span := ic.start(ctx, "ServerVersion")
+	start := time.Now()
+	out, err := ic.Client.ServerVersion(ctx)
+	ic.observe(span, "ServerVersion", start, err)
+	return out, err
+}
+
+//SwarmInit calls SwarmInit of the wrapped client, recording how long it takes
+func (ic instrumentedClient) SwarmInit(ctx context.Context, req swarm.InitRequest) (string, error) {
+	ctx, span := ic.start(ctx, "SwarmInit")
+	start := time.Now()
+	out, err := ic.Client.SwarmInit(ctx, req)
+	ic.observe(span, "SwarmInit", start, err)
+	return out, err
+}
+
+//SwarmJoin calls SwarmJoin of the wrapped client, recording how long it takes
+func (ic instrumentedClient) SwarmJoin(ctx context.Context, req swarm.JoinRequest) error {
+	ctx, span := ic.start(ctx, "SwarmJoin")
+	start := time.Now()
+	err := ic.Client.SwarmJoin(ctx, req)
+	ic.observe(span, "SwarmJoin", start, err)
+	return err
+}
+
+//SwarmInspect calls SwarmInspect of the wrapped client, recording how long it takes
+func (ic instrumentedClient) SwarmInspect(ctx context.Context) (swarm.Swarm, error) {
+	ctx, span := ic.start(ctx, "SwarmInspect")
+	start := time.Now()
+	out, err := ic.Client.SwarmInspect(ctx)
+	ic.observe(span, "SwarmInspect", start, err)
+	return out, err
+}
+
+//VolumeCreate calls VolumeCreate of the wrapped client, recording how long it takes
+func (ic instrumentedClient) VolumeCreate(ctx context.Context, options volume.VolumeCreateBody) (types.Volume, error) {
+	ctx, span := ic.start(ctx, "VolumeCreate")
+	start := time.Now()
+	out, err := ic.Client.VolumeCreate(ctx, options)
+	ic.observe(span, "VolumeCreate", start, err)
+	return out, err
+}
+
+//VolumeList calls VolumeList of the wrapped client, recording how long it takes
+func (ic instrumentedClient) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	ctx, span := ic.start(ctx, "VolumeList")
+	start := time.Now()
+	out, err := ic.Client.VolumeList(ctx, filter)
+	ic.observe(span, "VolumeList", start, err)
+	return out, err
+}
+
+//VolumeRemove calls VolumeRemove of the wrapped client, recording how long it takes
+func (ic instrumentedClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	ctx, span := ic.start(ctx, "VolumeRemove")
+	start := time.Now()
+	err := ic.Client.VolumeRemove(ctx, volumeID, force)
+	ic.observe(span, "VolumeRemove", start, err)
+	return err
+}
@@ -0,0 +1,64 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	entityMock "github.com/whiteblock/genesis/mocks/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/metrics"
+
+	"github.com/docker/docker/api/types"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func dockerRequests(t *testing.T, host, operation, outcome string) uint64 {
+	metric, ok := metrics.DockerRequestDuration.WithLabelValues(host, operation, outcome).(prometheus.Metric)
+	require.True(t, ok)
+	var out dto.Metric
+	require.NoError(t, metric.Write(&out))
+	return out.GetHistogram().GetSampleCount()
+}
+
+func TestInstrumentedClient(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{APIVersion: "1.40"}, nil).Once()
+	cli.On("ContainerRemove", mock.Anything, "test", mock.Anything).Return(errors.New("err")).Once()
+	cli.On("DaemonHost").Return("tcp://10.1.2.3:2376").Once()
+
+	ic := NewInstrumentedClient("10.1.2.3", cli)
+	ping, err := ic.Ping(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "1.40", ping.APIVersion)
+	assert.Error(t, ic.ContainerRemove(context.Background(), "test", types.ContainerRemoveOptions{}))
+	assert.Equal(t, "tcp://10.1.2.3:2376", ic.DaemonHost())
+
+	assert.Equal(t, uint64(1), dockerRequests(t, "10.1.2.3", "Ping", "success"))
+	assert.Equal(t, uint64(1), dockerRequests(t, "10.1.2.3", "ContainerRemove", "failure"))
+	assert.Equal(t, uint64(0), dockerRequests(t, "10.1.2.3", "ContainerRemove", "success"))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "docker.Ping", spans[0].Name())
+	assert.Equal(t, "docker.ContainerRemove", spans[1].Name())
+	assert.Equal(t, "Error", spans[1].Status().Code.String())
+	cli.AssertExpectations(t)
+}
@@ -0,0 +1,153 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/whiteblock/definition/command"
+)
+
+//JobService keeps track of the progress of the instructions submitted over the REST api, so
+//that it survives a restart
+type JobService interface {
+	//Start records the submission of the given instructions
+	Start(inst command.Instructions) error
+
+	//Step records an attempt at executing the given commands of the given round. inst is the
+	//instructions as they are after the attempt
+	Step(inst command.Instructions, round int, cmds []command.Command, res entity.Result) error
+
+	//Finish records that the job with the given id is no longer executing
+	Finish(id string, status entity.JobStatus, res entity.Result) error
+
+	//Get gets the job with the given id
+	Get(id string) (entity.Job, error)
+
+	//Unfinished gets all of the jobs which are still marked as executing
+	Unfinished() ([]entity.Job, error)
+}
+
+type jobService struct {
+	repo repository.JobRepository
+	log  logrus.Ext1FieldLogger
+}
+
+//NewJobService creates a new JobService. If repo is nil, nothing is recorded
+func NewJobService(repo repository.JobRepository, log logrus.Ext1FieldLogger) JobService {
+	return &jobService{repo: repo, log: log}
+}
+
+//Start records the submission of the given instructions
+func (js jobService) Start(inst command.Instructions) error {
+	if js.repo == nil {
+		return nil
+	}
+	data, err := json.Marshal(inst)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return js.repo.Put(entity.Job{
+		ID:           inst.ID,
+		Status:       entity.JobRunning,
+		Instructions: data,
+		Steps:        []entity.JobStep{},
+		Created:      now,
+		Updated:      now,
+	})
+}
+
+func (js jobService) update(id string, fn func(*entity.Job) error) error {
+	if js.repo == nil {
+		return nil
+	}
+	job, err := js.repo.Get(id)
+	if err != nil {
+		return err
+	}
+	err = fn(&job)
+	if err != nil {
+		return err
+	}
+	job.Updated = time.Now()
+	return js.repo.Put(job)
+}
+
+//Step records an attempt at executing the given commands of the given round. inst is the
+//instructions as they are after the attempt
+func (js jobService) Step(inst command.Instructions, round int,
+	cmds []command.Command, res entity.Result) error {
+
+	return js.update(inst.ID, func(job *entity.Job) error {
+		data, err := json.Marshal(inst)
+		if err != nil {
+			return err
+		}
+		result, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		step := entity.JobStep{
+			Round:     round,
+			Timestamp: time.Now(),
+			Result:    result,
+			Commands:  []string{},
+		}
+		for _, cmd := range cmds {
+			step.Commands = append(step.Commands, cmd.ID)
+		}
+		if failed, ok := res.Meta["failed"].([]string); ok {
+			step.Failed = failed
+		}
+		job.Instructions = data
+		job.Steps = append(job.Steps, step)
+		return nil
+	})
+}
+
+//Finish records that the job with the given id is no longer executing
+func (js jobService) Finish(id string, status entity.JobStatus, res entity.Result) error {
+	return js.update(id, func(job *entity.Job) error {
+		job.Status = status
+		if !res.IsSuccess() {
+			job.Error = res.Error.Error()
+		}
+		return nil
+	})
+}
+
+//Get gets the job with the given id
+func (js jobService) Get(id string) (entity.Job, error) {
+	if js.repo == nil {
+		return entity.Job{}, repository.ErrJobNotFound
+	}
+	return js.repo.Get(id)
+}
+
+//Unfinished gets all of the jobs which are still marked as executing
+func (js jobService) Unfinished() ([]entity.Job, error) {
+	out := []entity.Job{}
+	if js.repo == nil {
+		return out, nil
+	}
+	jobs, err := js.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if !job.Status.IsFinished() {
+			out = append(out, job)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	repoMocks "github.com/whiteblock/genesis/mocks/pkg/repository"
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/whiteblock/definition/command"
+)
+
+func TestJobService_Start(t *testing.T) {
+	repo := new(repoMocks.JobRepository)
+	repo.On("Put", mock.MatchedBy(func(job entity.Job) bool {
+		return job.ID == "test" && job.Status == entity.JobRunning && len(job.Instructions) > 0
+	})).Return(nil).Once()
+
+	assert.NoError(t, NewJobService(repo, logrus.New()).Start(command.Instructions{ID: "test"}))
+	repo.AssertExpectations(t)
+}
+
+func TestJobService_Step(t *testing.T) {
+	repo := new(repoMocks.JobRepository)
+	repo.On("Get", "test").Return(entity.Job{ID: "test", Status: entity.JobRunning}, nil).Once()
+	repo.On("Put", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		job := args.Get(0).(entity.Job)
+		require.Len(t, job.Steps, 1)
+		assert.Equal(t, 1, job.Steps[0].Round)
+		assert.Equal(t, []string{"1", "2"}, job.Steps[0].Commands)
+		assert.Equal(t, []string{"2"}, job.Steps[0].Failed)
+
+		var inst command.Instructions
+		require.NoError(t, json.Unmarshal(job.Instructions, &inst))
+		assert.Equal(t, 2, inst.Round)
+	}).Once()
+
+	res := entity.NewErrorResult("err").InjectMeta(map[string]interface{}{"failed": []string{"2"}})
+	err := NewJobService(repo, logrus.New()).Step(command.Instructions{ID: "test", Round: 2}, 1,
+		[]command.Command{{ID: "1"}, {ID: "2"}}, res)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestJobService_Finish(t *testing.T) {
+	repo := new(repoMocks.JobRepository)
+	repo.On("Get", "test").Return(entity.Job{ID: "test", Status: entity.JobRunning}, nil).Once()
+	repo.On("Put", mock.MatchedBy(func(job entity.Job) bool {
+		return job.Status == entity.JobFailed && job.Error == "err"
+	})).Return(nil).Once()
+
+	err := NewJobService(repo, logrus.New()).Finish("test", entity.JobFailed, entity.NewFatalResult("err"))
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestJobService_Unfinished(t *testing.T) {
+	repo := new(repoMocks.JobRepository)
+	repo.On("List").Return([]entity.Job{
+		{ID: "1", Status: entity.JobRunning},
+		{ID: "2", Status: entity.JobCompleted},
+	}, nil).Once()
+
+	jobs, err := NewJobService(repo, logrus.New()).Unfinished()
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "1", jobs[0].ID)
+	repo.AssertExpectations(t)
+}
+
+func TestJobService_Disabled(t *testing.T) {
+	serv := NewJobService(nil, logrus.New())
+	assert.NoError(t, serv.Start(command.Instructions{ID: "test"}))
+	assert.NoError(t, serv.Finish("test", entity.JobCompleted, entity.NewSuccessResult()))
+
+	_, err := serv.Get("test")
+	assert.Equal(t, repository.ErrJobNotFound, err)
+
+	jobs, err := serv.Unfinished()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 0)
+}
@@ -0,0 +1,88 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+	"github.com/whiteblock/definition/command"
+)
+
+//LedgerService keeps track of the commands which have already completed, so that they are
+//not executed again when their instructions are redelivered
+type LedgerService interface {
+	//Completed gets the result the given command completed with, if it has already completed
+	Completed(cmd command.Command) (entity.Result, bool)
+
+	//Record records the result of the given command, if it completed
+	Record(cmd command.Command, res entity.Result)
+
+	//Forget forgets the commands of the given instructions, once they are finished
+	Forget(testID string)
+}
+
+type ledgerService struct {
+	repo repository.LedgerRepository
+	log  logrus.Ext1FieldLogger
+}
+
+//NewLedgerService creates a new LedgerService. If repo is nil, nothing is recorded and every
+//command is executed
+func NewLedgerService(repo repository.LedgerRepository, log logrus.Ext1FieldLogger) LedgerService {
+	return &ledgerService{repo: repo, log: log}
+}
+
+//Completed gets the result the given command completed with, if it has already completed
+func (ls ledgerService) Completed(cmd command.Command) (entity.Result, bool) {
+	key, ok := entity.NewLedgerKey(cmd)
+	if !ok || ls.repo == nil {
+		return entity.Result{}, false
+	}
+	entry, found, err := ls.repo.Get(key)
+	if err != nil {
+		ls.log.WithFields(logrus.Fields{
+			"key": key.String(),
+			"err": err}).Error("failed to check the ledger, executing the command")
+		return entity.Result{}, false
+	}
+	if !found {
+		return entity.Result{}, false
+	}
+	ls.log.WithField("key", key.String()).Info("skipping a command which already completed")
+	return entry.Skipped(), true
+}
+
+//Record records the result of the given command, if it completed
+func (ls ledgerService) Record(cmd command.Command, res entity.Result) {
+	key, ok := entity.NewLedgerKey(cmd)
+	if !ok || ls.repo == nil || !res.IsSuccess() {
+		return
+	}
+	err := ls.repo.Put(entity.LedgerEntry{Key: key, Timestamp: time.Now(), Result: res})
+	if err != nil {
+		ls.log.WithFields(logrus.Fields{
+			"key": key.String(),
+			"err": err}).Error("failed to record a completed command")
+	}
+}
+
+//Forget forgets the commands of the given instructions, once they are finished
+func (ls ledgerService) Forget(testID string) {
+	if ls.repo == nil || testID == "" {
+		return
+	}
+	err := ls.repo.Forget(testID)
+	if err != nil {
+		ls.log.WithFields(logrus.Fields{
+			"testID": testID,
+			"err":    err}).Error("failed to remove instructions from the ledger")
+	}
+}
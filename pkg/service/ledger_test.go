@@ -0,0 +1,86 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	repoMocks "github.com/whiteblock/genesis/mocks/pkg/repository"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/whiteblock/definition/command"
+)
+
+func testCommand(t *testing.T) command.Command {
+	var inst command.Instructions
+	require.NoError(t, json.Unmarshal([]byte(
+		`{"id":"test","round":2,"commands":[[{"id":"cmd"}]]}`), &inst))
+	return inst.Commands[0][0]
+}
+
+var testKey = entity.LedgerKey{TestID: "test", Round: 2, CommandID: "cmd"}
+
+func TestLedgerService_Completed(t *testing.T) {
+	repo := new(repoMocks.LedgerRepository)
+	var stored entity.Result
+	data, err := json.Marshal(entity.NewTrapResult().InjectMeta(map[string]interface{}{"id": "abc"}))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &stored))
+	repo.On("Get", testKey).Return(entity.LedgerEntry{
+		Key:       testKey,
+		Timestamp: time.Now(),
+		Result:    stored,
+	}, true, nil).Once()
+
+	res, done := NewLedgerService(repo, logrus.New()).Completed(testCommand(t))
+	assert.True(t, done)
+	assert.True(t, res.IsTrap())
+	assert.Equal(t, "abc", res.Meta["id"])
+	assert.Equal(t, true, res.Meta["skipped"])
+	assert.NotContains(t, stored.Meta, "skipped")
+	repo.AssertExpectations(t)
+}
+
+func TestLedgerService_Completed_Failure(t *testing.T) {
+	repo := new(repoMocks.LedgerRepository)
+	repo.On("Get", testKey).Return(entity.LedgerEntry{}, false, fmt.Errorf("err")).Once()
+
+	_, done := NewLedgerService(repo, logrus.New()).Completed(testCommand(t))
+	assert.False(t, done)
+
+	_, done = NewLedgerService(repo, logrus.New()).Completed(command.Command{ID: "orphan"})
+	assert.False(t, done)
+	repo.AssertExpectations(t)
+}
+
+func TestLedgerService_Record(t *testing.T) {
+	repo := new(repoMocks.LedgerRepository)
+	repo.On("Put", mock.MatchedBy(func(entry entity.LedgerEntry) bool {
+		return entry.Key == testKey && entry.Result.Type == entity.SuccessType &&
+			entry.Result.Meta["id"] == "abc"
+	})).Return(nil).Once()
+
+	serv := NewLedgerService(repo, logrus.New())
+	serv.Record(testCommand(t), entity.NewSuccessResult().InjectMeta(map[string]interface{}{"id": "abc"}))
+	serv.Record(testCommand(t), entity.NewErrorResult("err"))
+	repo.AssertExpectations(t)
+}
+
+func TestLedgerService_Disabled(t *testing.T) {
+	serv := NewLedgerService(nil, logrus.New())
+	_, done := serv.Completed(testCommand(t))
+	assert.False(t, done)
+	serv.Record(testCommand(t), entity.NewSuccessResult())
+	serv.Forget("test")
+}
@@ -0,0 +1,187 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+const poolPingTimeout = 5 * time.Second
+
+//ClientPool shares one docker client per host between commands, so that their connections are
+//reused rather than being set up for every command
+type ClientPool interface {
+	//Get gets the client of the given host, creating one if there is none. Closing the client
+	//which is returned does nothing, as it is shared.
+	Get(host string) (entity.Client, error)
+	//Evict closes the client of the host if err shows that its connection failed, so that the
+	//next command for the host reconnects. It returns true if the client was evicted.
+	Evict(host string, err error) bool
+	//Close closes every client in the pool
+	Close()
+}
+
+//pooledClient is a shared client, which the users of the pool may not close
+type pooledClient struct {
+	entity.Client
+}
+
+//Close does nothing, as the client is closed by the pool
+func (pooledClient) Close() error {
+	return nil
+}
+
+type poolEntry struct {
+	cli         entity.Client
+	lastUsed    time.Time
+	lastChecked time.Time
+}
+
+type clientPool struct {
+	create       func(host string) (entity.Client, error)
+	idleTimeout  time.Duration
+	pingInterval time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*poolEntry
+	now     func() time.Time
+
+	log logrus.Ext1FieldLogger
+}
+
+//NewClientPool creates a new ClientPool, which creates clients with create. Clients are closed
+//once they have not been used for idleTimeout, and pinged before being reused if they have not
+//been checked for pingInterval.
+func NewClientPool(
+	create func(host string) (entity.Client, error),
+	idleTimeout time.Duration,
+	pingInterval time.Duration,
+	log logrus.Ext1FieldLogger) ClientPool {
+
+	return &clientPool{
+		create:       create,
+		idleTimeout:  idleTimeout,
+		pingInterval: pingInterval,
+		clients:      map[string]*poolEntry{},
+		now:          time.Now,
+		log:          log,
+	}
+}
+
+//prune closes the clients which have been idle for too long. The lock must be held.
+func (cp *clientPool) prune(now time.Time) {
+	for host, entry := range cp.clients {
+		if now.Sub(entry.lastUsed) >= cp.idleTimeout {
+			cp.log.WithField("host", host).Debug("closing an idle docker client")
+			entry.cli.Close()
+			delete(cp.clients, host)
+		}
+	}
+}
+
+//remove closes and removes the client of the host, if it is still entry
+func (cp *clientPool) remove(host string, entry *poolEntry) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if cp.clients[host] == entry {
+		entry.cli.Close()
+		delete(cp.clients, host)
+	}
+}
+
+//healthy pings the client of entry, if it has not been checked recently
+func (cp *clientPool) healthy(host string, entry *poolEntry, now time.Time) bool {
+	cp.mu.Lock()
+	check := now.Sub(entry.lastChecked) >= cp.pingInterval
+	if check {
+		entry.lastChecked = now
+	}
+	cp.mu.Unlock()
+	if !check {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), poolPingTimeout)
+	defer cancel()
+	_, err := entry.cli.Ping(ctx)
+	if err != nil {
+		cp.log.WithFields(logrus.Fields{
+			"host":  host,
+			"error": err}).Warn("evicting a docker client which failed its ping")
+		cp.remove(host, entry)
+		return false
+	}
+	return true
+}
+
+//Get gets the client of the given host, creating one if there is none
+func (cp *clientPool) Get(host string) (entity.Client, error) {
+	now := cp.now()
+	cp.mu.Lock()
+	cp.prune(now)
+	entry, ok := cp.clients[host]
+	if ok {
+		entry.lastUsed = now
+	}
+	cp.mu.Unlock()
+
+	if ok && cp.healthy(host, entry, now) {
+		return pooledClient{entry.cli}, nil
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if entry, ok := cp.clients[host]; ok { //created while the lock was released
+		entry.lastUsed = now
+		return pooledClient{entry.cli}, nil
+	}
+	cli, err := cp.create(host)
+	if err != nil {
+		return nil, err
+	}
+	cp.log.WithField("host", host).Debug("created a pooled docker client")
+	cp.clients[host] = &poolEntry{cli: cli, lastUsed: now, lastChecked: now}
+	return pooledClient{cli}, nil
+}
+
+//Evict closes the client of the host if err shows that its connection failed
+func (cp *clientPool) Evict(host string, err error) bool {
+	if !IsConnectionError(err) {
+		return false
+	}
+	cp.mu.Lock()
+	entry, ok := cp.clients[host]
+	cp.mu.Unlock()
+	if !ok {
+		return false
+	}
+	cp.log.WithFields(logrus.Fields{
+		"host":  host,
+		"error": err}).Warn("evicting a docker client after a connection error")
+	cp.remove(host, entry)
+	return true
+}
+
+//Close closes every client in the pool
+func (cp *clientPool) Close() {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	for host, entry := range cp.clients {
+		entry.cli.Close()
+		delete(cp.clients, host)
+	}
+}
+
+//IsConnectionError returns true if err shows that the docker daemon could not be reached
+func IsConnectionError(err error) bool {
+	return entity.ClassifyError(err) == entity.CodeDaemonUnreachable
+}
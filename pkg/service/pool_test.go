@@ -0,0 +1,163 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	entityMock "github.com/whiteblock/genesis/mocks/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type testPool struct {
+	pool    *clientPool
+	created map[string][]*entityMock.Client
+	now     time.Time
+}
+
+func newTestPool() *testPool {
+	out := &testPool{created: map[string][]*entityMock.Client{}, now: time.Unix(1000, 0)}
+	out.pool = NewClientPool(func(host string) (entity.Client, error) {
+		if host == "bad" {
+			return nil, fmt.Errorf("bad host")
+		}
+		cli := new(entityMock.Client)
+		cli.On("Close").Return(nil)
+		out.created[host] = append(out.created[host], cli)
+		return cli, nil
+	}, time.Minute, 10*time.Second, logrus.New()).(*clientPool)
+	out.pool.now = func() time.Time { return out.now }
+	return out
+}
+
+func TestClientPool_Get(t *testing.T) {
+	tp := newTestPool()
+
+	first, err := tp.pool.Get("a")
+	require.NoError(t, err)
+	second, err := tp.pool.Get("a")
+	require.NoError(t, err)
+	_, err = tp.pool.Get("b")
+	require.NoError(t, err)
+
+	assert.Len(t, tp.created["a"], 1)
+	assert.Len(t, tp.created["b"], 1)
+	assert.Equal(t, first, second)
+
+	assert.NoError(t, first.Close())
+	tp.created["a"][0].AssertNotCalled(t, "Close")
+
+	_, err = tp.pool.Get("bad")
+	assert.Error(t, err)
+}
+
+func TestClientPool_Get_IdleTimeout(t *testing.T) {
+	tp := newTestPool()
+	_, err := tp.pool.Get("a")
+	require.NoError(t, err)
+
+	tp.now = tp.now.Add(30 * time.Second)
+	tp.created["a"][0].On("Ping", mock.Anything).Return(types.Ping{}, nil).Once()
+	_, err = tp.pool.Get("b")
+	require.NoError(t, err)
+	_, err = tp.pool.Get("a")
+	require.NoError(t, err)
+	assert.Len(t, tp.created["a"], 1)
+
+	tp.now = tp.now.Add(time.Minute)
+	_, err = tp.pool.Get("b")
+	require.NoError(t, err)
+	tp.created["a"][0].AssertCalled(t, "Close")
+	tp.created["b"][0].AssertCalled(t, "Close")
+	assert.Len(t, tp.created["b"], 2)
+}
+
+func TestClientPool_Get_Ping(t *testing.T) {
+	var tests = []struct {
+		pingErr  error
+		expected int
+	}{
+		{pingErr: nil, expected: 1},
+		{pingErr: fmt.Errorf("down"), expected: 2},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tp := newTestPool()
+			_, err := tp.pool.Get("a")
+			require.NoError(t, err)
+
+			tp.now = tp.now.Add(5 * time.Second) //not yet due for a ping
+			_, err = tp.pool.Get("a")
+			require.NoError(t, err)
+
+			tp.now = tp.now.Add(10 * time.Second)
+			tp.created["a"][0].On("Ping", mock.Anything).Return(types.Ping{}, tt.pingErr).Once()
+			_, err = tp.pool.Get("a")
+			require.NoError(t, err)
+			assert.Len(t, tp.created["a"], tt.expected)
+			tp.created["a"][0].AssertNumberOfCalls(t, "Ping", 1)
+		})
+	}
+}
+
+func TestClientPool_Evict(t *testing.T) {
+	var tests = []struct {
+		err      error
+		expected bool
+	}{
+		{err: nil, expected: false},
+		{err: fmt.Errorf("no such container"), expected: false},
+		{err: client.ErrorConnectionFailed("a"), expected: true},
+		{err: entity.NewErrorResult(client.ErrorConnectionFailed("a")).Error, expected: true},
+		{err: fmt.Errorf("wrapped: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}),
+			expected: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tp := newTestPool()
+			_, err := tp.pool.Get("a")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, tp.pool.Evict("a", tt.err))
+			assert.False(t, tp.pool.Evict("b", tt.err))
+			_, err = tp.pool.Get("a")
+			require.NoError(t, err)
+			if tt.expected {
+				assert.Len(t, tp.created["a"], 2)
+				tp.created["a"][0].AssertCalled(t, "Close")
+			} else {
+				assert.Len(t, tp.created["a"], 1)
+			}
+		})
+	}
+}
+
+func TestClientPool_Close(t *testing.T) {
+	tp := newTestPool()
+	for _, host := range []string{"a", "b"} {
+		_, err := tp.pool.Get(host)
+		require.NoError(t, err)
+	}
+	tp.pool.Close()
+	tp.created["a"][0].AssertCalled(t, "Close")
+	tp.created["b"][0].AssertCalled(t, "Close")
+	assert.Empty(t, tp.pool.clients)
+}
@@ -0,0 +1,208 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	queue "github.com/whiteblock/amqp"
+)
+
+//ReadinessCheck checks whether Genesis can reach one of its dependencies
+type ReadinessCheck struct {
+	//Name identifies the dependency
+	Name string
+	//Critical dependencies must be reachable for Genesis to be ready
+	Critical bool
+	//Check contacts the dependency, returning any details it found out
+	Check func(ctx context.Context) (interface{}, error)
+}
+
+//ReadinessService checks whether Genesis can reach the dependencies it needs to execute commands
+type ReadinessService interface {
+	//Check runs every check at once, giving each of them until the timeout to finish. The outcome
+	//is reused until the cache ttl passes, and callers in the meantime wait for the same run.
+	Check(ctx context.Context) entity.Readiness
+}
+
+//ErrCheckTimedOut is the error of a check which took longer than the timeout
+var ErrCheckTimedOut = errors.New("timed out")
+
+type readinessService struct {
+	checks   []ReadinessCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+	last     entity.Readiness
+	lastRun  time.Time
+	mu       sync.Mutex
+	log      logrus.Ext1FieldLogger
+}
+
+//NewReadinessService creates a new ReadinessService, which runs the given checks at most once
+//every cacheTTL
+func NewReadinessService(checks []ReadinessCheck, timeout time.Duration, cacheTTL time.Duration,
+	log logrus.Ext1FieldLogger) ReadinessService {
+	return &readinessService{checks: checks, timeout: timeout, cacheTTL: cacheTTL, log: log}
+}
+
+func (rs *readinessService) run(ctx context.Context, check ReadinessCheck) entity.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, rs.timeout)
+	defer cancel()
+
+	type checkResult struct {
+		details interface{}
+		err     error
+	}
+	done := make(chan checkResult, 1)
+	start := time.Now()
+	go func() {
+		details, err := check.Check(ctx)
+		done <- checkResult{details: details, err: err}
+	}()
+
+	var res checkResult
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res.err = ErrCheckTimedOut
+	}
+	out := entity.DependencyStatus{
+		Name:          check.Name,
+		Critical:      check.Critical,
+		OK:            res.err == nil,
+		LatencyMillis: entity.Millis(time.Since(start)),
+		Details:       res.details,
+	}
+	if res.err != nil {
+		out.Error = res.err.Error()
+	}
+	return out
+}
+
+//Check runs every check at once, giving each of them until the timeout to finish. The outcome
+//is reused until the cache ttl passes, and callers in the meantime wait for the same run.
+func (rs *readinessService) Check(ctx context.Context) entity.Readiness {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if !rs.lastRun.IsZero() && time.Since(rs.lastRun) < rs.cacheTTL {
+		return rs.last
+	}
+	out := rs.check(ctx)
+	if ctx.Err() == nil {
+		//the outcome of a cancelled run is down to the caller, not the dependencies
+		rs.last = out
+		rs.lastRun = time.Now()
+	}
+	return out
+}
+
+func (rs *readinessService) check(ctx context.Context) entity.Readiness {
+	out := entity.Readiness{Ready: true, Dependencies: make([]entity.DependencyStatus, len(rs.checks))}
+	var wg sync.WaitGroup
+	for i := range rs.checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			out.Dependencies[i] = rs.run(ctx, rs.checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, status := range out.Dependencies {
+		if status.OK {
+			continue
+		}
+		rs.log.WithFields(logrus.Fields{
+			"dependency": status.Name,
+			"critical":   status.Critical,
+			"error":      status.Error}).Warn("a dependency could not be reached")
+		if status.Critical {
+			out.Ready = false
+		}
+	}
+	return out
+}
+
+//NewAMQPCheck creates a critical check which connects to the broker of the given queue, and
+//makes sure that the queue exists
+func NewAMQPCheck(conf queue.AMQPConfig) ReadinessCheck {
+	return ReadinessCheck{
+		Name:     "amqp:" + conf.QueueName,
+		Critical: true,
+		Check: func(ctx context.Context) (interface{}, error) {
+			conn, err := queue.OpenAMQPConnection(conf.Endpoint)
+			if err != nil {
+				return nil, err
+			}
+			defer conn.Close()
+			ch, err := conn.Channel()
+			if err != nil {
+				return nil, err
+			}
+			defer ch.Close()
+			q, err := ch.QueueDeclarePassive(conf.QueueName, conf.Queue.Durable,
+				conf.Queue.AutoDelete, conf.Queue.Exclusive, false, conf.Queue.Args)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]int{"messages": q.Messages, "consumers": q.Consumers}, nil
+		},
+	}
+}
+
+//NewDockerCheck creates a critical check which diagnoses the connection to the docker daemon of
+//the host, the same way as when a command for it fails
+func NewDockerCheck(host string, docker DockerService) ReadinessCheck {
+	return ReadinessCheck{
+		Name:     "docker:" + host,
+		Critical: true,
+		Check: func(ctx context.Context) (interface{}, error) {
+			cli, err := docker.CreateClient(host)
+			if err != nil {
+				return nil, err
+			}
+			defer cli.Close()
+			diag := DiagnoseClient(ctx, host, cli)
+			if step, failed := diag.Failure(); failed {
+				return diag.Steps, fmt.Errorf("%s failed: %s", step.Name, step.Error)
+			}
+			return diag.Steps, nil
+		},
+	}
+}
+
+//NewHTTPCheck creates a check which makes sure that the server at the endpoint responds, with
+//any status code below 500
+func NewHTTPCheck(name string, endpoint string, critical bool) ReadinessCheck {
+	return ReadinessCheck{
+		Name:     name,
+		Critical: critical,
+		Check: func(ctx context.Context) (interface{}, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+			}
+			return resp.StatusCode, nil
+		},
+	}
+}
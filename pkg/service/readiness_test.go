@@ -0,0 +1,154 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	entityMock "github.com/whiteblock/genesis/mocks/pkg/entity"
+	serviceMock "github.com/whiteblock/genesis/mocks/pkg/service"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/docker/docker/api/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func staticCheck(name string, critical bool, err error) ReadinessCheck {
+	return ReadinessCheck{Name: name, Critical: critical,
+		Check: func(context.Context) (interface{}, error) {
+			return "details", err
+		}}
+}
+
+func TestReadinessService_Check(t *testing.T) {
+	slow := ReadinessCheck{Name: "slow", Critical: true,
+		Check: func(ctx context.Context) (interface{}, error) {
+			time.Sleep(time.Second)
+			return nil, nil
+		}}
+
+	var tests = []struct {
+		checks []ReadinessCheck
+		ready  bool
+		errors []string
+	}{
+		{checks: []ReadinessCheck{}, ready: true, errors: []string{}},
+		{
+			checks: []ReadinessCheck{staticCheck("a", true, nil), staticCheck("b", false, errors.New("err"))},
+			ready:  true,
+			errors: []string{"", "err"},
+		},
+		{
+			checks: []ReadinessCheck{staticCheck("a", true, errors.New("err")), staticCheck("b", false, nil)},
+			ready:  false,
+			errors: []string{"err", ""},
+		},
+		{
+			checks: []ReadinessCheck{slow, staticCheck("b", true, nil)},
+			ready:  false,
+			errors: []string{ErrCheckTimedOut.Error(), ""},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := NewReadinessService(tt.checks, 50*time.Millisecond, 0, logrus.New()).Check(
+				context.Background())
+			assert.Equal(t, tt.ready, res.Ready)
+			require.Len(t, res.Dependencies, len(tt.checks))
+			for j, dep := range res.Dependencies {
+				assert.Equal(t, tt.checks[j].Name, dep.Name)
+				assert.Equal(t, tt.checks[j].Critical, dep.Critical)
+				assert.Equal(t, tt.errors[j] == "", dep.OK)
+				assert.Equal(t, tt.errors[j], dep.Error)
+				assert.True(t, dep.LatencyMillis < 500)
+			}
+		})
+	}
+}
+
+func TestReadinessService_Check_Cache(t *testing.T) {
+	var runs int32
+	counted := ReadinessCheck{Name: "counted", Critical: true,
+		Check: func(ctx context.Context) (interface{}, error) {
+			atomic.AddInt32(&runs, 1)
+			return nil, ctx.Err()
+		}}
+
+	serv := NewReadinessService([]ReadinessCheck{counted}, time.Second, 50*time.Millisecond,
+		logrus.New())
+	for i := 0; i < 3; i++ {
+		assert.True(t, serv.Check(context.Background()).Ready)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+	time.Sleep(60 * time.Millisecond)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, serv.Check(cancelled).Ready)
+	assert.True(t, serv.Check(context.Background()).Ready)
+}
+
+func TestNewHTTPCheck(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	check := NewHTTPCheck("fileAPI", srv.URL, false)
+	assert.Equal(t, "fileAPI", check.Name)
+	assert.False(t, check.Critical)
+
+	details, err := check.Check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, details)
+
+	status = http.StatusBadGateway
+	_, err = check.Check(context.Background())
+	assert.Error(t, err)
+
+	srv.Close()
+	_, err = check.Check(context.Background())
+	assert.Error(t, err)
+}
+
+func TestNewDockerCheck(t *testing.T) {
+	cli := new(entityMock.Client)
+	cli.On("Ping", mock.Anything).Return(types.Ping{APIVersion: "1.40"}, nil).Once()
+	cli.On("Close").Return(nil).Once()
+
+	docker := new(serviceMock.DockerService)
+	docker.On("CreateClient", "10.0.0.2").Return(cli, nil).Once()
+	docker.On("CreateClient", "10.0.0.3").Return(nil, errors.New("bad host")).Once()
+
+	check := NewDockerCheck("10.0.0.2", docker)
+	assert.Equal(t, "docker:10.0.0.2", check.Name)
+	assert.True(t, check.Critical)
+	details, err := check.Check(context.Background())
+	assert.NoError(t, err)
+	steps, ok := details.([]entity.DiagnosticStep)
+	require.True(t, ok)
+	require.Len(t, steps, 1)
+	assert.True(t, steps[0].OK)
+
+	_, err = NewDockerCheck("10.0.0.3", docker).Check(context.Background())
+	assert.EqualError(t, err, "bad host")
+
+	cli.AssertExpectations(t)
+	docker.AssertExpectations(t)
+}
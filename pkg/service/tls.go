@@ -0,0 +1,169 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+//CertReloader provides the TLS configuration of the REST API, reloading the certificate, key
+//and client CA whenever their files change
+type CertReloader interface {
+	//TLSConfig gets a TLS config which always serves the most recently loaded files
+	TLSConfig() *tls.Config
+	//Reload loads the files again if any of them have changed since they were last loaded
+	Reload() error
+}
+
+//restNextProtos are the application protocols the REST API negotiates, which the http server
+//only sets on its own config, not on the configs given for each client
+var restNextProtos = []string{"h2", "http/1.1"}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type certReloader struct {
+	conf       entity.RestConfig
+	minVersion uint16
+
+	mu      sync.RWMutex
+	current *tls.Config
+	stamps  map[string]fileStamp
+
+	log logrus.Ext1FieldLogger
+}
+
+//NewCertReloader creates a new CertReloader, loading the files given in conf
+func NewCertReloader(conf entity.RestConfig, log logrus.Ext1FieldLogger) (CertReloader, error) {
+	minVersion, ok := entity.TLSVersions[conf.MinTLSVersion]
+	if !ok {
+		return nil, fmt.Errorf(`unknown tls version "%s"`, conf.MinTLSVersion)
+	}
+	out := &certReloader{conf: conf, minVersion: minVersion, log: log}
+	return out, out.Reload()
+}
+
+func (cr *certReloader) paths() []string {
+	out := []string{cr.conf.CertPath, cr.conf.KeyPath}
+	if cr.conf.ClientCAPath != "" {
+		out = append(out, cr.conf.ClientCAPath)
+	}
+	return out
+}
+
+func (cr *certReloader) stat() (map[string]fileStamp, error) {
+	out := map[string]fileStamp{}
+	for _, path := range cr.paths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		out[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return out, nil
+}
+
+func (cr *certReloader) changed(stamps map[string]fileStamp) bool {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	if cr.current == nil {
+		return true
+	}
+	for path, stamp := range stamps {
+		if cr.stamps[path] != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+func (cr *certReloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cr.conf.CertPath, cr.conf.KeyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the certificate")
+	}
+	out := &tls.Config{
+		MinVersion:   cr.minVersion,
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   restNextProtos,
+	}
+	if cr.conf.ClientCAPath == "" {
+		return out, nil
+	}
+	data, err := ioutil.ReadFile(cr.conf.ClientCAPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the client ca")
+	}
+	out.ClientCAs = x509.NewCertPool()
+	if !out.ClientCAs.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", cr.conf.ClientCAPath)
+	}
+	out.ClientAuth = tls.RequireAndVerifyClientCert
+	return out, nil
+}
+
+//Reload loads the files again if any of them have changed since they were last loaded. On failure,
+//the previously loaded files continue to be served.
+func (cr *certReloader) Reload() error {
+	stamps, err := cr.stat()
+	if err != nil {
+		return err
+	}
+	if !cr.changed(stamps) {
+		return nil
+	}
+	conf, err := cr.load()
+	if err != nil {
+		return err
+	}
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.current != nil {
+		cr.log.WithField("cert", cr.conf.CertPath).Info("reloaded the rest certificates")
+	}
+	cr.current = conf
+	cr.stamps = stamps
+	return nil
+}
+
+//TLSConfig gets a TLS config which always serves the most recently loaded files. The files
+//are checked for changes on each new connection.
+func (cr *certReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: cr.minVersion,
+		NextProtos: restNextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			err := cr.Reload()
+			if err != nil {
+				cr.log.WithField("error", err).Error("failed to reload the rest certificates")
+			}
+			return cr.latest(), nil
+		},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &cr.latest().Certificates[0], nil
+		},
+	}
+}
+
+func (cr *certReloader) latest() *tls.Config {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.current
+}
@@ -0,0 +1,237 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, serial int64, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "genesis"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	parentCert, parentKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parentCert, key.Public(), parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (tc testCert) write(t *testing.T, certPath, keyPath string, modTime time.Time) {
+	require.NoError(t, ioutil.WriteFile(certPath, tc.certPEM, 0600))
+	require.NoError(t, ioutil.WriteFile(keyPath, tc.keyPEM, 0600))
+	require.NoError(t, os.Chtimes(certPath, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyPath, modTime, modTime))
+}
+
+func startTLSServer(t *testing.T, conf entity.RestConfig) *httptest.Server {
+	certs, err := NewCertReloader(conf, logrus.New())
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.TLS = certs.TLSConfig()
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func tlsClient(ca testCert, conf *tls.Config) *http.Client {
+	if conf == nil {
+		conf = &tls.Config{}
+	}
+	conf.RootCAs = x509.NewCertPool()
+	conf.RootCAs.AddCert(ca.cert)
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: conf, DisableKeepAlives: true}}
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	conf := entity.RestConfig{
+		CertPath:      filepath.Join(dir, "cert.pem"),
+		KeyPath:       filepath.Join(dir, "key.pem"),
+		MinTLSVersion: "1.2",
+	}
+	ca := newTestCert(t, 1, nil)
+	first := newTestCert(t, 2, &ca)
+	first.write(t, conf.CertPath, conf.KeyPath, time.Now().Add(-time.Minute))
+
+	server := startTLSServer(t, conf)
+	client := tlsClient(ca, nil)
+
+	res, err := client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, int64(2), res.TLS.PeerCertificates[0].SerialNumber.Int64())
+
+	second := newTestCert(t, 3, &ca)
+	second.write(t, conf.CertPath, conf.KeyPath, time.Now())
+
+	res, err = client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, int64(3), res.TLS.PeerCertificates[0].SerialNumber.Int64())
+
+	//a broken certificate keeps the last one which loaded
+	require.NoError(t, ioutil.WriteFile(conf.CertPath, []byte("garbage"), 0600))
+	res, err = client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, int64(3), res.TLS.PeerCertificates[0].SerialNumber.Int64())
+}
+
+func TestCertReloader_NextProtos(t *testing.T) {
+	dir := t.TempDir()
+	conf := entity.RestConfig{
+		CertPath:      filepath.Join(dir, "cert.pem"),
+		KeyPath:       filepath.Join(dir, "key.pem"),
+		MinTLSVersion: "1.2",
+	}
+	ca := newTestCert(t, 1, nil)
+	newTestCert(t, 2, &ca).write(t, conf.CertPath, conf.KeyPath, time.Now())
+	certs, err := NewCertReloader(conf, logrus.New())
+	require.NoError(t, err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", certs.TLSConfig())
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	for _, proto := range restNextProtos {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			RootCAs:    roots,
+			ServerName: "127.0.0.1",
+			NextProtos: []string{proto},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, proto, conn.ConnectionState().NegotiatedProtocol)
+		conn.Close()
+	}
+}
+
+func TestCertReloader_ClientCA(t *testing.T) {
+	dir := t.TempDir()
+	conf := entity.RestConfig{
+		CertPath:      filepath.Join(dir, "cert.pem"),
+		KeyPath:       filepath.Join(dir, "key.pem"),
+		ClientCAPath:  filepath.Join(dir, "ca.pem"),
+		MinTLSVersion: "1.2",
+	}
+	ca := newTestCert(t, 1, nil)
+	newTestCert(t, 2, &ca).write(t, conf.CertPath, conf.KeyPath, time.Now())
+	require.NoError(t, ioutil.WriteFile(conf.ClientCAPath, ca.certPEM, 0600))
+	server := startTLSServer(t, conf)
+
+	_, err := tlsClient(ca, nil).Get(server.URL)
+	assert.Error(t, err)
+
+	clientCert := newTestCert(t, 4, &ca)
+	pair, err := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+	require.NoError(t, err)
+	res, err := tlsClient(ca, &tls.Config{Certificates: []tls.Certificate{pair}}).Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	other := newTestCert(t, 5, nil)
+	pair, err = tls.X509KeyPair(other.certPEM, other.keyPEM)
+	require.NoError(t, err)
+	_, err = tlsClient(ca, &tls.Config{Certificates: []tls.Certificate{pair}}).Get(server.URL)
+	assert.Error(t, err)
+}
+
+func TestCertReloader_MinTLSVersion(t *testing.T) {
+	dir := t.TempDir()
+	conf := entity.RestConfig{
+		CertPath:      filepath.Join(dir, "cert.pem"),
+		KeyPath:       filepath.Join(dir, "key.pem"),
+		MinTLSVersion: "1.3",
+	}
+	ca := newTestCert(t, 1, nil)
+	newTestCert(t, 2, &ca).write(t, conf.CertPath, conf.KeyPath, time.Now())
+	server := startTLSServer(t, conf)
+
+	_, err := tlsClient(ca, &tls.Config{MaxVersion: tls.VersionTLS12}).Get(server.URL)
+	assert.Error(t, err)
+
+	res, err := tlsClient(ca, nil).Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, uint16(tls.VersionTLS13), res.TLS.Version)
+}
+
+func TestNewCertReloader_Failure(t *testing.T) {
+	_, err := NewCertReloader(entity.RestConfig{MinTLSVersion: "2.0"}, logrus.New())
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	_, err = NewCertReloader(entity.RestConfig{
+		CertPath:      filepath.Join(dir, "cert.pem"),
+		KeyPath:       filepath.Join(dir, "key.pem"),
+		MinTLSVersion: "1.2",
+	}, logrus.New())
+	assert.Error(t, err)
+}
@@ -0,0 +1,142 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/tracing"
+
+	"github.com/whiteblock/definition/command"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+//tracedDockerService traces each call to the methods of a DockerService
+type tracedDockerService struct {
+	DockerService
+}
+
+//NewTracedDockerService wraps ds, so that each of the orders it carries out is traced as a
+//span of its own
+func NewTracedDockerService(ds DockerService) DockerService {
+	return tracedDockerService{DockerService: ds}
+}
+
+func (tds tracedDockerService) start(ctx context.Context, method string,
+	attrs ...attribute.KeyValue) (context.Context, func(entity.Result) entity.Result) {
+
+	ctx, span := tracing.Start(ctx, "DockerService."+method, attrs...)
+	return ctx, func(res entity.Result) entity.Result {
+		tracing.End(span, res)
+		return res
+	}
+}
+
+//CreateContainer traces CreateContainer of the wrapped DockerService
+func (tds tracedDockerService) CreateContainer(ctx context.Context, cli entity.DockerCli,
+	container entity.Container) entity.Result {
+	ctx, end := tds.start(ctx, "CreateContainer", attribute.String("genesis.container", container.Name))
+	return end(tds.DockerService.CreateContainer(ctx, cli, container))
+}
+
+//StartContainer traces StartContainer of the wrapped DockerService
+func (tds tracedDockerService) StartContainer(ctx context.Context, cli entity.DockerCli,
+	sc command.StartContainer) entity.Result {
+	ctx, end := tds.start(ctx, "StartContainer", attribute.String("genesis.container", sc.Name))
+	return end(tds.DockerService.StartContainer(ctx, cli, sc))
+}
+
+//RemoveContainer traces RemoveContainer of the wrapped DockerService
+func (tds tracedDockerService) RemoveContainer(ctx context.Context, cli entity.DockerCli,
+	name string) entity.Result {
+	ctx, end := tds.start(ctx, "RemoveContainer", attribute.String("genesis.container", name))
+	return end(tds.DockerService.RemoveContainer(ctx, cli, name))
+}
+
+//CreateNetwork traces CreateNetwork of the wrapped DockerService
+func (tds tracedDockerService) CreateNetwork(ctx context.Context, cli entity.DockerCli,
+	net entity.Network) entity.Result {
+	ctx, end := tds.start(ctx, "CreateNetwork", attribute.String("genesis.network", net.Name))
+	return end(tds.DockerService.CreateNetwork(ctx, cli, net))
+}
+
+//RemoveNetwork traces RemoveNetwork of the wrapped DockerService
+func (tds tracedDockerService) RemoveNetwork(ctx context.Context, cli entity.DockerCli,
+	name string) entity.Result {
+	ctx, end := tds.start(ctx, "RemoveNetwork", attribute.String("genesis.network", name))
+	return end(tds.DockerService.RemoveNetwork(ctx, cli, name))
+}
+
+//AttachNetwork traces AttachNetwork of the wrapped DockerService
+func (tds tracedDockerService) AttachNetwork(ctx context.Context, cli entity.DockerCli,
+	cmd command.ContainerNetwork) entity.Result {
+	ctx, end := tds.start(ctx, "AttachNetwork",
+		attribute.String("genesis.container", cmd.Container),
+		attribute.String("genesis.network", cmd.Network))
+	return end(tds.DockerService.AttachNetwork(ctx, cli, cmd))
+}
+
+//DetachNetwork traces DetachNetwork of the wrapped DockerService
+func (tds tracedDockerService) DetachNetwork(ctx context.Context, cli entity.DockerCli,
+	network string, container string) entity.Result {
+	ctx, end := tds.start(ctx, "DetachNetwork",
+		attribute.String("genesis.container", container),
+		attribute.String("genesis.network", network))
+	return end(tds.DockerService.DetachNetwork(ctx, cli, network, container))
+}
+
+//CreateVolume traces CreateVolume of the wrapped DockerService
+func (tds tracedDockerService) CreateVolume(ctx context.Context, cli entity.DockerCli,
+	volume command.Volume) entity.Result {
+	ctx, end := tds.start(ctx, "CreateVolume", attribute.String("genesis.volume", volume.Name))
+	return end(tds.DockerService.CreateVolume(ctx, cli, volume))
+}
+
+//RemoveVolume traces RemoveVolume of the wrapped DockerService
+func (tds tracedDockerService) RemoveVolume(ctx context.Context, cli entity.DockerCli,
+	name string) entity.Result {
+	ctx, end := tds.start(ctx, "RemoveVolume", attribute.String("genesis.volume", name))
+	return end(tds.DockerService.RemoveVolume(ctx, cli, name))
+}
+
+//PlaceFileInContainer traces PlaceFileInContainer of the wrapped DockerService
+func (tds tracedDockerService) PlaceFileInContainer(ctx context.Context, cli entity.DockerCli,
+	containerName string, file command.File) entity.Result {
+	ctx, end := tds.start(ctx, "PlaceFileInContainer",
+		attribute.String("genesis.container", containerName),
+		attribute.String("genesis.file", file.Destination))
+	return end(tds.DockerService.PlaceFileInContainer(ctx, cli, containerName, file))
+}
+
+//Emulation traces Emulation of the wrapped DockerService
+func (tds tracedDockerService) Emulation(ctx context.Context, cli entity.DockerCli,
+	netem command.Netconf) entity.Result {
+	ctx, end := tds.start(ctx, "Emulation", attribute.String("genesis.container", netem.Container))
+	return end(tds.DockerService.Emulation(ctx, cli, netem))
+}
+
+//SwarmCluster traces SwarmCluster of the wrapped DockerService
+func (tds tracedDockerService) SwarmCluster(ctx context.Context, cli entity.DockerCli,
+	swarm command.SetupSwarm) entity.Result {
+	ctx, end := tds.start(ctx, "SwarmCluster", attribute.Int("genesis.hosts", len(swarm.Hosts)))
+	return end(tds.DockerService.SwarmCluster(ctx, cli, swarm))
+}
+
+//PullImage traces PullImage of the wrapped DockerService
+func (tds tracedDockerService) PullImage(ctx context.Context, cli entity.DockerCli,
+	imagePull command.PullImage) entity.Result {
+	ctx, end := tds.start(ctx, "PullImage", attribute.String("genesis.image", imagePull.Image))
+	return end(tds.DockerService.PullImage(ctx, cli, imagePull))
+}
+
+//VolumeShare traces VolumeShare of the wrapped DockerService
+func (tds tracedDockerService) VolumeShare(ctx context.Context, cli entity.DockerCli,
+	vs command.VolumeShare) entity.Result {
+	ctx, end := tds.start(ctx, "VolumeShare", attribute.Int("genesis.hosts", len(vs.Hosts)))
+	return end(tds.DockerService.VolumeShare(ctx, cli, vs))
+}
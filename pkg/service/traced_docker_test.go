@@ -0,0 +1,62 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	serviceMock "github.com/whiteblock/genesis/mocks/pkg/service"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/whiteblock/definition/command"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracedDockerService(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "command")
+	var called trace.SpanContext
+	ds := new(serviceMock.DockerService)
+	ds.On("PullImage", mock.Anything, mock.Anything, command.PullImage{Image: "alpine"}).Return(
+		entity.NewErrorResult("err").WithCode(entity.CodeImagePullFailed)).Run(
+		func(args mock.Arguments) {
+			called = trace.SpanContextFromContext(args.Get(0).(context.Context))
+		}).Once()
+	ds.On("RemoveContainer", mock.Anything, mock.Anything, "test").Return(
+		entity.NewSuccessResult()).Once()
+
+	tds := NewTracedDockerService(ds)
+	assert.False(t, tds.PullImage(ctx, entity.DockerCli{}, command.PullImage{Image: "alpine"}).IsSuccess())
+	assert.True(t, tds.RemoveContainer(ctx, entity.DockerCli{}, "test").IsSuccess())
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "DockerService.PullImage", spans[0].Name())
+	assert.Equal(t, called.SpanID(), spans[0].SpanContext().SpanID())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("genesis.image", "alpine"))
+	assert.Contains(t, spans[0].Attributes(),
+		attribute.String("genesis.result.code", string(entity.CodeImagePullFailed)))
+	assert.Equal(t, "DockerService.RemoveContainer", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	ds.AssertExpectations(t)
+}
@@ -0,0 +1,72 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tableCarrier carries trace context in the headers of an amqp message
+type tableCarrier amqp.Table
+
+func (tc tableCarrier) Get(key string) string {
+	out, _ := tc[key].(string)
+	return out
+}
+
+func (tc tableCarrier) Set(key, value string) {
+	tc[key] = value
+}
+
+func (tc tableCarrier) Keys() []string {
+	out := make([]string, 0, len(tc))
+	for key := range tc {
+		out = append(out, key)
+	}
+	return out
+}
+
+// Inject adds the trace context of ctx to the given amqp headers, creating them if they are nil
+func Inject(ctx context.Context, headers amqp.Table) amqp.Table {
+	if headers == nil {
+		headers = amqp.Table{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, tableCarrier(headers))
+	return headers
+}
+
+// Extract gets the trace context from the given amqp headers, adding it to ctx
+func Extract(ctx context.Context, headers amqp.Table) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, tableCarrier(headers))
+}
+
+// InjectHTTP adds the trace context of ctx to the headers of an outgoing request
+func InjectHTTP(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Middleware continues the trace given in the headers of each request, wrapping the handling of
+// the request in a server span
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := Tracer().Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.target", r.URL.Path)))
+		defer span.End()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,75 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupRecorder() *tracetest.SpanRecorder {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return rec
+}
+
+func TestInjectExtract(t *testing.T) {
+	setupRecorder()
+	defer resetProvider()
+
+	ctx, span := Start(context.Background(), "producer")
+	defer span.End()
+
+	headers := Inject(ctx, nil)
+	require.NotNil(t, headers)
+	assert.NotEmpty(t, headers["traceparent"])
+
+	headers = Inject(ctx, amqp.Table{"attempts": int64(1)})
+	assert.Equal(t, int64(1), headers["attempts"])
+
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), headers))
+	assert.True(t, extracted.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+
+	assert.False(t, trace.SpanContextFromContext(
+		Extract(context.Background(), amqp.Table{"traceparent": 5})).IsValid())
+}
+
+func TestMiddleware(t *testing.T) {
+	rec := setupRecorder()
+	defer resetProvider()
+
+	ctx, client := Start(context.Background(), "client")
+	req := httptest.NewRequest(http.MethodGet, "/jobs/test", nil)
+	InjectHTTP(ctx, req.Header)
+	client.End()
+
+	var handled trace.SpanContext
+	Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled = trace.SpanContextFromContext(r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "HTTP GET", spans[1].Name())
+	assert.Equal(t, handled.SpanID(), spans[1].SpanContext().SpanID())
+	assert.Equal(t, client.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Equal(t, trace.SpanKindServer, spans[1].SpanKind())
+}
@@ -0,0 +1,165 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collector "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func resetProvider() {
+	otel.SetTracerProvider(trace.NewNoopTracerProvider())
+}
+
+func TestSetup_OTLP(t *testing.T) {
+	defer resetProvider()
+
+	var mu sync.Mutex
+	names := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req collector.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(data, &req))
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					names = append(names, span.Name)
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer srv.Close()
+
+	shutdown, err := Setup(config.Tracing{
+		Exporter:     config.OTLPTracing,
+		OTLPEndpoint: strings.TrimPrefix(srv.URL, "http://"),
+		OTLPInsecure: true,
+		SampleRatio:  1,
+		ServiceName:  "genesis",
+	})
+	require.NoError(t, err)
+
+	ctx, parent := Start(context.Background(), "instructions")
+	_, child := Start(ctx, "step")
+	End(child, entity.NewSuccessResult())
+	End(parent, entity.NewSuccessResult())
+	require.NoError(t, shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{"instructions", "step"}, names)
+}
+
+func TestSetup_File(t *testing.T) {
+	defer resetProvider()
+
+	dir, err := ioutil.TempDir("", "tracing")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "traces.json")
+	shutdown, err := Setup(config.Tracing{Exporter: config.FileTracing, File: file,
+		SampleRatio: 1, ServiceName: "genesis"})
+	require.NoError(t, err)
+
+	_, span := Start(context.Background(), "command")
+	End(span, entity.NewFatalResult("err").WithCode(entity.CodeTimeout))
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	var out struct {
+		Name   string
+		Status struct {
+			Code        string
+			Description string
+		}
+		Attributes []struct {
+			Key   string
+			Value struct {
+				Value interface{}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, "command", out.Name)
+	assert.Equal(t, "Error", out.Status.Code)
+	assert.Equal(t, "err", out.Status.Description)
+
+	attrs := map[string]interface{}{}
+	for _, attr := range out.Attributes {
+		attrs[attr.Key] = attr.Value.Value
+	}
+	assert.Equal(t, "Fatal", attrs["genesis.result.type"])
+	assert.Equal(t, string(entity.CodeTimeout), attrs["genesis.result.code"])
+}
+
+func TestSetup_Unknown(t *testing.T) {
+	_, err := Setup(config.Tracing{Exporter: "zipkin"})
+	assert.Error(t, err)
+
+	shutdown, err := Setup(config.Tracing{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestEnd(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "success")
+	End(span, entity.NewSuccessResult())
+	_, span = tracer.Start(context.Background(), "error")
+	End(span, entity.NewErrorResult("err"))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "err", spans[1].Status().Description)
+}
+
+func TestDetach(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, span := tracer.Start(ctx, "parent")
+	defer span.End()
+	cancel()
+
+	detached := Detach(ctx)
+	assert.NoError(t, detached.Err())
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(detached))
+}
@@ -8,9 +8,6 @@ package usecase
 
 import (
 	"context"
-	"fmt"
-	"io/ioutil"
-	"net"
 	"strings"
 	"time"
 
@@ -25,33 +22,36 @@ import (
 
 //DockerUseCase is the usecase for executing the commands in docker
 type DockerUseCase interface {
-	// Run is equivalent to Execute, except it generates context based on the given command
-	Run(cmd command.Command) entity.Result
+	// Run is equivalent to Execute, except it derives the timeout of ctx from the given command
+	Run(ctx context.Context, cmd command.Command) entity.Result
 	// Execute executes the command with the given context
 	Execute(ctx context.Context, cmd command.Command) entity.Result
+	// Validate checks the command for the problems which would stop it from executing, without
+	// contacting docker
+	Validate(cmd command.Command) entity.Result
 }
 
 var (
 	// ErrEmptyFieldName missing a name field
-	ErrEmptyFieldName = entity.NewFatalResult("empty field \"name\"")
+	ErrEmptyFieldName = entity.NewFatalResult("empty field \"name\"").WithCode(entity.CodeInvalidPayload)
 
 	// ErrEmptyFieldContainer missing a container field
-	ErrEmptyFieldContainer = entity.NewFatalResult("empty field \"container\"")
+	ErrEmptyFieldContainer = entity.NewFatalResult("empty field \"container\"").WithCode(entity.CodeInvalidPayload)
 
 	// ErrEmptyFieldImage missing an image field
-	ErrEmptyFieldImage = entity.NewFatalResult("empty field \"image\"")
+	ErrEmptyFieldImage = entity.NewFatalResult("empty field \"image\"").WithCode(entity.CodeInvalidPayload)
 
 	// ErrEmptyFieldHosts missing hosts field
-	ErrEmptyFieldHosts = entity.NewFatalResult("empty field \"hosts\"")
+	ErrEmptyFieldHosts = entity.NewFatalResult("empty field \"hosts\"").WithCode(entity.CodeInvalidPayload)
 
 	// ErrEmptyFieldNetwork missing network field
-	ErrEmptyFieldNetwork = entity.NewFatalResult("empty field \"network\"")
+	ErrEmptyFieldNetwork = entity.NewFatalResult("empty field \"network\"").WithCode(entity.CodeInvalidPayload)
 
 	// ErrInvalidTargetIP target IP is not a dest IP or is malformed
-	ErrInvalidTargetIP = entity.NewFatalResult("invalid target ip")
+	ErrInvalidTargetIP = entity.NewFatalResult("invalid target ip").WithCode(entity.CodeInvalidPayload)
 
 	// ErrUnknownCommandType the given command is of an unknown type
-	ErrUnknownCommandType = entity.NewFatalResult("unknown command type")
+	ErrUnknownCommandType = entity.NewFatalResult("unknown command type").WithCode(entity.CodeInvalidPayload)
 )
 
 type dockerUseCase struct {
@@ -75,8 +75,8 @@ func (duc dockerUseCase) withField(cmd command.Command, key string, value interf
 	return duc.withFields(cmd, logrus.Fields{key: value})
 }
 
-// Run is equivalent to Execute, except it generates context based on the given command
-func (duc dockerUseCase) Run(cmd command.Command) entity.Result {
+// Run is equivalent to Execute, except it derives the timeout of ctx from the given command
+func (duc dockerUseCase) Run(ctx context.Context, cmd command.Command) entity.Result {
 	stat, ok := duc.validationCheck(cmd)
 	if !ok {
 		return stat
@@ -95,61 +95,26 @@ func (duc dockerUseCase) Run(cmd command.Command) entity.Result {
 		}
 	}
 
-	ctx, cancelFn := context.WithTimeout(context.Background(), timeout)
+	ctx, cancelFn := context.WithTimeout(ctx, timeout)
 	defer cancelFn()
 	return duc.Execute(ctx, cmd)
 }
 
 func (duc dockerUseCase) diagnoseConnIssue(ctx context.Context, cli entity.Client, cmd command.Command) {
-	res, err := cli.Ping(ctx)
-	if err != nil {
-		duc.withField(cmd, "error", err).Error("failed to ping the docker api")
-	} else {
-		duc.withField(cmd, "res", res).Info("got a ping response from the docker daemon")
-		return
-	}
-	baseHost := strings.TrimPrefix(cli.DaemonHost(), "tcp://")
-	conn, err := net.DialTimeout("tcp", baseHost, 1*time.Second)
-	if err != nil {
-		duc.withFields(cmd, logrus.Fields{
-			"error": err,
-			"host":  cli.DaemonHost()}).Error("docker appears to be down")
-		return //nothing more to report
-	}
-	duc.withField(cmd, "host", cli.DaemonHost()).Error("docker is listening on the expected port")
-	conn.Close()
-
-	httpClient := cli.HTTPClient()
-	resp, err := httpClient.Get(fmt.Sprintf("https://%s/info", baseHost))
-	if err != nil {
-		duc.withField(cmd, "error", err).Error("failed to get info from the docker host directly")
-	} else {
-
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			duc.withField(cmd, "error", err).Error("an addition io error occured")
-		}
-		resp.Body.Close()
-		duc.withFields(cmd, logrus.Fields{
-			"statusCode": resp.StatusCode,
-			"body":       string(data),
-		}).Info("got back information from the docker daemon")
-	}
-	resp, err = httpClient.Get(fmt.Sprintf("https://%s/_ping", baseHost))
-	if err != nil {
-		duc.withField(cmd, "error", err).Error("failed to ping the docker host directly")
-	} else {
-		defer resp.Body.Close()
-		data, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			duc.withField(cmd, "error", err).Error("an addition io error occured")
+	diag := service.DiagnoseClient(ctx, cmd.Target.IP, cli)
+	for _, step := range diag.Steps {
+		entry := duc.withFields(cmd, logrus.Fields{
+			"step":    step.Name,
+			"latency": step.LatencyMillis,
+			"detail":  step.Detail,
+			"host":    cli.DaemonHost(),
+		})
+		if step.OK {
+			entry.Info("a step of diagnosing the docker daemon succeeded")
+		} else {
+			entry.WithField("error", step.Error).Error("a step of diagnosing the docker daemon failed")
 		}
-		duc.withFields(cmd, logrus.Fields{
-			"statusCode": resp.StatusCode,
-			"body":       string(data),
-		}).Info("successfully pinged docker daemon")
 	}
-
 }
 
 // Execute executes the command with the given context
@@ -165,6 +130,14 @@ func (duc dockerUseCase) Execute(ctx context.Context, cmd command.Command) entit
 		}
 	}()
 	duc.withField(cmd, "client", cli).Trace("created a client")
+	res := duc.route(ctx, cli, cmd)
+	if !res.IsSuccess() && service.IsConnectionError(res.Error) {
+		duc.service.EvictClient(cmd.Target.IP, res.Error)
+	}
+	return res
+}
+
+func (duc dockerUseCase) route(ctx context.Context, cli entity.Client, cmd command.Command) entity.Result {
 	duc.withField(cmd, "type", cmd.Order.Type).Trace("routing a command")
 	switch command.OrderType(strings.ToLower(string(cmd.Order.Type))) {
 	case command.Createcontainer:
@@ -203,6 +176,43 @@ func (duc dockerUseCase) Execute(ctx context.Context, cmd command.Command) entit
 	return ErrUnknownCommandType.InjectMeta(map[string]interface{}{"type": cmd.Order.Type})
 }
 
+// Validate checks the command for the problems which would stop it from executing, without
+// contacting docker
+func (duc dockerUseCase) Validate(cmd command.Command) entity.Result {
+	stat, ok := duc.validationCheck(cmd)
+	if !ok {
+		return stat
+	}
+	var res entity.Result
+	switch command.OrderType(strings.ToLower(string(cmd.Order.Type))) {
+	case command.Createcontainer:
+		_, res = parseContainer(cmd)
+	case command.Startcontainer:
+		_, res = parseStartContainer(cmd)
+	case command.Removecontainer, command.Removenetwork, command.Removevolume:
+		_, res = parseSimpleName(cmd)
+	case command.Createnetwork:
+		_, res = parseNetwork(cmd)
+	case command.Attachnetwork, command.Detachnetwork:
+		_, res = parseContainerNetwork(cmd)
+	case command.Createvolume:
+		_, res = parseVolume(cmd)
+	case command.Putfileincontainer:
+		_, res = parseFileAndContainer(cmd)
+	case command.Emulation:
+		_, res = parseNetconf(cmd)
+	case command.SwarmInit:
+		_, res = parseSetupSwarm(cmd)
+	case command.Pullimage:
+		_, res = parsePullImage(cmd)
+	case command.Volumeshare:
+		_, res = parseVolumeShare(cmd)
+	default:
+		return ErrUnknownCommandType.InjectMeta(map[string]interface{}{"type": cmd.Order.Type})
+	}
+	return res
+}
+
 func (duc dockerUseCase) validationCheck(cmd command.Command) (result entity.Result, ok bool) {
 	ok = false
 	if len(cmd.Target.IP) == 0 || cmd.Target.IP == "0.0.0.0" {
@@ -222,21 +232,28 @@ func (duc dockerUseCase) injectLabels(cli entity.Client, cmd command.Command) en
 	return out
 }
 
-func (duc dockerUseCase) createContainerShim(ctx context.Context, cli entity.Client,
-	cmd command.Command) entity.Result {
-
-	var container command.Container
+func parseContainer(cmd command.Command) (container entity.Container, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&container)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return container, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
 	err = validator.Container(container)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return container, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
+	}
+	return container, entity.NewSuccessResult()
+}
+
+func (duc dockerUseCase) createContainerShim(ctx context.Context, cli entity.Client,
+	cmd command.Command) entity.Result {
+
+	container, res := parseContainer(cmd)
+	if !res.IsSuccess() {
+		return res
 	}
 
 	docker := duc.injectLabels(cli, cmd)
-	err = mergo.Map(&docker.Labels, container.Labels)
+	err := mergo.Map(&docker.Labels, container.Labels)
 	if err != nil {
 		return entity.NewFatalResult(err)
 	}
@@ -246,61 +263,93 @@ func (duc dockerUseCase) createContainerShim(ctx context.Context, cli entity.Cli
 	return duc.service.CreateContainer(ctx, docker, container)
 }
 
-func (duc dockerUseCase) startContainerShim(ctx context.Context, cli entity.Client,
-	cmd command.Command) entity.Result {
-
-	var sc command.StartContainer
+func parseStartContainer(cmd command.Command) (sc command.StartContainer, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&sc)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return sc, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
 	if len(sc.Name) == 0 {
-		return ErrEmptyFieldName
+		return sc, ErrEmptyFieldName
 	}
-	return duc.service.StartContainer(ctx, duc.injectLabels(cli, cmd), sc)
+	return sc, entity.NewSuccessResult()
 }
 
-func (duc dockerUseCase) removeContainerShim(ctx context.Context, cli entity.Client,
+func (duc dockerUseCase) startContainerShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
 
-	var payload command.SimpleName
+	sc, res := parseStartContainer(cmd)
+	if !res.IsSuccess() {
+		return res
+	}
+	return duc.service.StartContainer(ctx, duc.injectLabels(cli, cmd), sc)
+}
+
+func parseSimpleName(cmd command.Command) (payload command.SimpleName, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&payload)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return payload, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
 	if payload.Name == "" {
-		return ErrEmptyFieldName
+		return payload, ErrEmptyFieldName
 	}
-	return duc.service.RemoveContainer(ctx, duc.injectLabels(cli, cmd), payload.Name)
+	return payload, entity.NewSuccessResult()
 }
 
-func (duc dockerUseCase) createNetworkShim(ctx context.Context, cli entity.Client,
+func (duc dockerUseCase) removeContainerShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
-	var net command.Network
+
+	payload, res := parseSimpleName(cmd)
+	if !res.IsSuccess() {
+		return res
+	}
+	return duc.service.RemoveContainer(ctx, duc.injectLabels(cli, cmd), payload.Name)
+}
+
+func parseNetwork(cmd command.Command) (net entity.Network, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&net)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return net, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
+	}
+	err = validator.Network(net)
+	if err != nil {
+		return net, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
+	}
+	return net, entity.NewSuccessResult()
+}
+
+func (duc dockerUseCase) createNetworkShim(ctx context.Context, cli entity.Client,
+	cmd command.Command) entity.Result {
+	net, res := parseNetwork(cmd)
+	if !res.IsSuccess() {
+		return res
 	}
 	docker := duc.injectLabels(cli, cmd)
-	err = mergo.Map(&docker.Labels, net.Labels)
+	err := mergo.Map(&docker.Labels, net.Labels)
 	if err != nil {
 		return entity.NewFatalResult(err)
 	}
 	return duc.service.CreateNetwork(ctx, docker, net)
 }
 
-func (duc dockerUseCase) attachNetworkShim(ctx context.Context, cli entity.Client,
-	cmd command.Command) entity.Result {
-	var payload command.ContainerNetwork
+func parseContainerNetwork(cmd command.Command) (payload command.ContainerNetwork, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&payload)
 	if err != nil {
-		return entity.NewErrorResult(err)
+		return payload, entity.NewErrorResult(err).WithCode(entity.CodeInvalidPayload)
 	}
 	if len(payload.Container) == 0 {
-		return ErrEmptyFieldContainer
+		return payload, ErrEmptyFieldContainer
 	}
 	if len(payload.Network) == 0 {
-		return ErrEmptyFieldNetwork
+		return payload, ErrEmptyFieldNetwork
+	}
+	return payload, entity.NewSuccessResult()
+}
+
+func (duc dockerUseCase) attachNetworkShim(ctx context.Context, cli entity.Client,
+	cmd command.Command) entity.Result {
+	payload, res := parseContainerNetwork(cmd)
+	if !res.IsSuccess() {
+		return res
 	}
 	return duc.service.AttachNetwork(ctx, duc.injectLabels(cli, cmd), payload)
 }
@@ -308,16 +357,9 @@ func (duc dockerUseCase) attachNetworkShim(ctx context.Context, cli entity.Clien
 func (duc dockerUseCase) detachNetworkShim(ctx context.Context,
 	cli entity.Client, cmd command.Command) entity.Result {
 
-	var payload command.ContainerNetwork
-	err := cmd.ParseOrderPayloadInto(&payload)
-	if err != nil {
-		return entity.NewErrorResult(err)
-	}
-	if len(payload.Container) == 0 {
-		return ErrEmptyFieldContainer
-	}
-	if len(payload.Network) == 0 {
-		return ErrEmptyFieldNetwork
+	payload, res := parseContainerNetwork(cmd)
+	if !res.IsSuccess() {
+		return res
 	}
 	return duc.service.DetachNetwork(ctx, duc.injectLabels(cli, cmd),
 		payload.Network, payload.Container)
@@ -326,26 +368,30 @@ func (duc dockerUseCase) detachNetworkShim(ctx context.Context,
 func (duc dockerUseCase) removeNetworkShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
 
-	var payload command.SimpleName
+	payload, res := parseSimpleName(cmd)
+	if !res.IsSuccess() {
+		return res
+	}
+	return duc.service.RemoveNetwork(ctx, duc.injectLabels(cli, cmd), payload.Name)
+}
+
+func parseVolume(cmd command.Command) (payload command.Volume, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&payload)
 	if err != nil {
-		return entity.NewFatalResult(err)
-	}
-	if payload.Name == "" {
-		return ErrEmptyFieldName
+		return payload, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
-	return duc.service.RemoveNetwork(ctx, duc.injectLabels(cli, cmd), payload.Name)
+	return payload, entity.NewSuccessResult()
 }
+
 func (duc dockerUseCase) createVolumeShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
 
-	var payload command.Volume
-	err := cmd.ParseOrderPayloadInto(&payload)
-	if err != nil {
-		return entity.NewFatalResult(err)
+	payload, res := parseVolume(cmd)
+	if !res.IsSuccess() {
+		return res
 	}
 	docker := duc.injectLabels(cli, cmd)
-	err = mergo.Map(&docker.Labels, payload.Labels)
+	err := mergo.Map(&docker.Labels, payload.Labels)
 	if err != nil {
 		return entity.NewFatalResult(err)
 	}
@@ -355,80 +401,112 @@ func (duc dockerUseCase) createVolumeShim(ctx context.Context, cli entity.Client
 func (duc dockerUseCase) removeVolumeShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
 
-	var payload command.SimpleName
-	err := cmd.ParseOrderPayloadInto(&payload)
-	if err != nil {
-		return entity.NewFatalResult(err)
-	}
-	if payload.Name == "" {
-		return ErrEmptyFieldName
+	payload, res := parseSimpleName(cmd)
+	if !res.IsSuccess() {
+		return res
 	}
 	return duc.service.RemoveVolume(ctx, duc.injectLabels(cli, cmd), payload.Name)
 }
-func (duc dockerUseCase) putFileInContainerShim(ctx context.Context, cli entity.Client,
-	cmd command.Command) entity.Result {
 
-	var payload command.FileAndContainer
+func parseFileAndContainer(cmd command.Command) (payload command.FileAndContainer, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&payload)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return payload, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
 	if len(payload.ContainerName) == 0 {
-		return ErrEmptyFieldContainer
+		return payload, ErrEmptyFieldContainer
 	}
-	return duc.service.PlaceFileInContainer(ctx, duc.injectLabels(cli, cmd),
-		payload.ContainerName, payload.File)
+	return payload, entity.NewSuccessResult()
 }
 
-func (duc dockerUseCase) emulationShim(ctx context.Context, cli entity.Client,
+func (duc dockerUseCase) putFileInContainerShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
 
-	var payload command.Netconf
+	payload, res := parseFileAndContainer(cmd)
+	if !res.IsSuccess() {
+		return res
+	}
+	return duc.service.PlaceFileInContainer(ctx, duc.injectLabels(cli, cmd),
+		payload.ContainerName, payload.File)
+}
+
+func parseNetconf(cmd command.Command) (payload command.Netconf, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&payload)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return payload, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
-	return duc.service.Emulation(ctx, duc.injectLabels(cli, cmd), payload)
+	return payload, entity.NewSuccessResult()
 }
 
-func (duc dockerUseCase) swarmSetupShim(ctx context.Context, cli entity.Client,
+func (duc dockerUseCase) emulationShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
 
-	var payload command.SetupSwarm
+	payload, res := parseNetconf(cmd)
+	if !res.IsSuccess() {
+		return res
+	}
+	return duc.service.Emulation(ctx, duc.injectLabels(cli, cmd), payload)
+}
+
+func parseSetupSwarm(cmd command.Command) (payload command.SetupSwarm, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&payload)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return payload, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
 	if len(payload.Hosts) == 0 {
-		return ErrEmptyFieldHosts
+		return payload, ErrEmptyFieldHosts
 	}
-	return duc.service.SwarmCluster(ctx, duc.injectLabels(cli, cmd), payload)
+	return payload, entity.NewSuccessResult()
 }
 
-func (duc dockerUseCase) pullImageShim(ctx context.Context, cli entity.Client,
+func (duc dockerUseCase) swarmSetupShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
 
-	var payload command.PullImage
+	payload, res := parseSetupSwarm(cmd)
+	if !res.IsSuccess() {
+		return res
+	}
+	return duc.service.SwarmCluster(ctx, duc.injectLabels(cli, cmd), payload)
+}
+
+func parsePullImage(cmd command.Command) (payload command.PullImage, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&payload)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return payload, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
 	if len(payload.Image) == 0 {
-		return ErrEmptyFieldImage
+		return payload, ErrEmptyFieldImage
 	}
-	return duc.service.PullImage(ctx, duc.injectLabels(cli, cmd), payload)
+	return payload, entity.NewSuccessResult()
 }
 
-func (duc dockerUseCase) volumeShareShim(ctx context.Context, cli entity.Client,
+func (duc dockerUseCase) pullImageShim(ctx context.Context, cli entity.Client,
 	cmd command.Command) entity.Result {
 
-	var payload command.VolumeShare
+	payload, res := parsePullImage(cmd)
+	if !res.IsSuccess() {
+		return res
+	}
+	return duc.service.PullImage(ctx, duc.injectLabels(cli, cmd), payload)
+}
+
+func parseVolumeShare(cmd command.Command) (payload command.VolumeShare, res entity.Result) {
 	err := cmd.ParseOrderPayloadInto(&payload)
 	if err != nil {
-		return entity.NewFatalResult(err)
+		return payload, entity.NewFatalResult(err).WithCode(entity.CodeInvalidPayload)
 	}
 	if len(payload.Hosts) == 0 {
-		return ErrEmptyFieldHosts
+		return payload, ErrEmptyFieldHosts
+	}
+	return payload, entity.NewSuccessResult()
+}
+
+func (duc dockerUseCase) volumeShareShim(ctx context.Context, cli entity.Client,
+	cmd command.Command) entity.Result {
+
+	payload, res := parseVolumeShare(cmd)
+	if !res.IsSuccess() {
+		return res
 	}
 	return duc.service.VolumeShare(ctx, duc.injectLabels(cli, cmd), payload)
 }
@@ -8,12 +8,15 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"testing"
 
 	mockService "github.com/whiteblock/genesis/mocks/pkg/service"
 	"github.com/whiteblock/genesis/pkg/entity"
 
+	"github.com/docker/docker/client"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -68,7 +71,7 @@ func TestDockerUseCase_Run_Failure_CreateClient(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{Target: testTarget})
+	res := usecase.Run(context.Background(), command.Command{Target: testTarget})
 	assert.Error(t, res.Error)
 	service.AssertExpectations(t)
 }
@@ -76,7 +79,7 @@ func TestDockerUseCase_Run_Failure_CreateClient(t *testing.T) {
 func TestDockerUseCase_Run_Failure_Invalid_IP(t *testing.T) {
 	usecase := NewDockerUseCase(nil, logrus.New())
 
-	res := usecase.Run(command.Command{Target: command.Target{IP: "0.0.0.0"}})
+	res := usecase.Run(context.Background(), command.Command{Target: command.Target{IP: "0.0.0.0"}})
 	assert.Error(t, res.Error)
 }
 
@@ -88,7 +91,7 @@ func TestDockerUseCase_Run_CreateContainer_Success(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -111,7 +114,7 @@ func TestDockerUseCase_Run_CreateContainer_Failure(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -123,6 +126,39 @@ func TestDockerUseCase_Run_CreateContainer_Failure(t *testing.T) {
 	service.AssertExpectations(t)
 }
 
+func TestDockerUseCase_Run_EvictsClient(t *testing.T) {
+	var tests = []struct {
+		err   error
+		evict bool
+	}{
+		{err: client.ErrorConnectionFailed(testTarget.IP), evict: true},
+		{err: fmt.Errorf("no such network"), evict: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			service := new(mockService.DockerService)
+			service.On("CreateClient", mock.Anything).Return(nil, nil).Once()
+			service.On("RemoveNetwork", mock.Anything, mock.Anything, "foo").Return(
+				entity.NewErrorResult(tt.err)).Once()
+			if tt.evict {
+				service.On("EvictClient", testTarget.IP, mock.Anything).Return().Once()
+			}
+
+			res := NewDockerUseCase(service, logrus.New()).Run(context.Background(), command.Command{
+				ID:     "TEST",
+				Target: testTarget,
+				Order: command.Order{
+					Type:    "removeNetwork",
+					Payload: command.SimpleName{Name: "foo"},
+				},
+			})
+			assert.True(t, errors.Is(res.Error, tt.err))
+			service.AssertExpectations(t)
+		})
+	}
+}
+
 func TestDockerUseCase_Run_StartContainer_Success(t *testing.T) {
 	service := new(mockService.DockerService)
 	service.On("CreateClient", mock.Anything, mock.Anything).Return(nil, nil).Once()
@@ -131,7 +167,7 @@ func TestDockerUseCase_Run_StartContainer_Success(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -149,7 +185,7 @@ func TestDockerUseCase_Run_StartContainer_Failure_ExtraField(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -167,7 +203,7 @@ func TestDockerUseCase_Run_StartContainer_Failure_EmptyName(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -196,7 +232,7 @@ func TestDockerUseCase_Run_RemoveContainer_Success(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -216,7 +252,7 @@ func TestDockerUseCase_Run_RemoveContainer_Failure_EmptyName(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -235,7 +271,7 @@ func TestDockerUseCase_Run_RemoveContainer_Failure_ExtraField(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -255,7 +291,7 @@ func TestDockerUseCase_Run_CreateNetwork(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -268,6 +304,30 @@ func TestDockerUseCase_Run_CreateNetwork(t *testing.T) {
 
 }
 
+func TestDockerUseCase_Run_InvalidPayload(t *testing.T) {
+	var tests = []command.Order{
+		{Type: "createContainer", Payload: map[string]interface{}{"image": "alpine"}},
+		{Type: "createNetwork", Payload: map[string]interface{}{"name": "test", "subnet": "bad"}},
+	}
+
+	for _, order := range tests {
+		t.Run(string(order.Type), func(t *testing.T) {
+			service := new(mockService.DockerService)
+			service.On("CreateClient", mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+			usecase := NewDockerUseCase(service, logrus.New())
+			res := usecase.Run(context.Background(), command.Command{
+				ID:     "TEST",
+				Target: testTarget,
+				Order:  order,
+			})
+			assert.Error(t, res.Error)
+			assert.True(t, res.IsFatal())
+			assert.Equal(t, entity.CodeInvalidPayload, res.ErrorCode())
+		})
+	}
+}
+
 func TestDockerUseCase_Run_AttachNetwork_Success(t *testing.T) {
 	service := new(mockService.DockerService)
 	service.On("CreateClient", mock.Anything, mock.Anything).Return(nil, nil).Once()
@@ -276,7 +336,7 @@ func TestDockerUseCase_Run_AttachNetwork_Success(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -298,7 +358,7 @@ func TestDockerUseCase_Run_AttachNetwork_Failure_EmptyContainerName(t *testing.T
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -320,7 +380,7 @@ func TestDockerUseCase_Run_AttachNetwork_Failure_EmptyNetworkName(t *testing.T)
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -342,7 +402,7 @@ func TestDockerUseCase_Run_AttachNetwork_Failure_ExtraField(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -367,7 +427,7 @@ func TestDockerUseCase_Run_DetachNetwork(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -386,7 +446,7 @@ func TestDockerUseCase_Run_DetachNetwork_Failure(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -407,7 +467,7 @@ func TestDockerUseCase_RemoveNetwork_Success(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -428,7 +488,7 @@ func TestDockerUseCase_RemoveNetwork_Failure_EmptyName(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -449,7 +509,7 @@ func TestDockerUseCase_RemoveNetwork_Failure_ExtraField(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -473,7 +533,7 @@ func TestDockerUseCase_Run_CreateVolume(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -495,7 +555,7 @@ func TestDockerUseCase_Run_RemoveVolume(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -515,7 +575,7 @@ func TestDockerUseCase_Run_PutFileInContainer(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -538,7 +598,7 @@ func TestDockerUseCase_Run_Emulation(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		ID:     "TEST",
 		Target: testTarget,
 		Order: command.Order{
@@ -925,13 +985,47 @@ func TestDockerUseCase_Execute_UnknownType_Failure(t *testing.T) {
 	service.AssertExpectations(t)
 }
 
+func TestDockerUseCase_Execute_CreateContainer_Resources(t *testing.T) {
+	payload := map[string]interface{}{
+		"name":      "foo",
+		"image":     "bar",
+		"cpus":      "2.0",
+		"memory":    "2GB",
+		"resources": map[string]interface{}{"cpusetCpus": "0-1", "pidsLimit": 64},
+		"capAdd":    []string{"NET_ADMIN"},
+	}
+	service := new(mockService.DockerService)
+	service.On("CreateClient", mock.Anything, mock.Anything).Return(nil, nil).Twice()
+	service.On("CreateContainer", mock.Anything, mock.Anything, mock.MatchedBy(
+		func(cntr entity.Container) bool {
+			return cntr.Name == "foo" && cntr.Resources.CPUSetCPUs == "0-1" &&
+				cntr.Resources.PidsLimit == 64 && cntr.CapAdd[0] == "NET_ADMIN"
+		})).Return(entity.NewSuccessResult()).Once()
+
+	usecase := NewDockerUseCase(service, logrus.New())
+	res := usecase.Run(context.Background(), command.Command{
+		Target: testTarget,
+		Order:  command.Order{Type: command.Createcontainer, Payload: payload},
+	})
+	assert.NoError(t, res.Error)
+
+	payload["resources"] = map[string]interface{}{"pidsLimit": -2}
+	res = usecase.Run(context.Background(), command.Command{
+		Target: testTarget,
+		Order:  command.Order{Type: command.Createcontainer, Payload: payload},
+	})
+	assert.Error(t, res.Error)
+	assert.True(t, res.IsFatal())
+	service.AssertExpectations(t)
+}
+
 func TestDockerUseCase_Execute_CreateContainer_Failure_ExtraField(t *testing.T) {
 	service := new(mockService.DockerService)
 	service.On("CreateClient", mock.Anything, mock.Anything).Return(nil, nil).Once()
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		Target: testTarget,
 		Order: command.Order{
 			Type: command.Createcontainer,
@@ -950,7 +1044,7 @@ func TestDockerUseCase_Execute_CreateVolume_Failure_ExtraField(t *testing.T) {
 
 	usecase := NewDockerUseCase(service, logrus.New())
 
-	res := usecase.Run(command.Command{
+	res := usecase.Run(context.Background(), command.Command{
 		Target: testTarget,
 		Order: command.Order{
 			Type: command.Createvolume,
@@ -987,3 +1081,68 @@ func TestDockerUseCase_Execute_PutFileInContainer_NoName_Fail(t *testing.T) {
 	assert.Error(t, res.Error)
 	service.AssertExpectations(t)
 }
+
+func TestDockerUseCase_Validate(t *testing.T) {
+	var tests = []struct {
+		cmd      command.Command
+		expected entity.Result
+	}{
+		{
+			cmd:      command.Command{Target: command.Target{IP: "0.0.0.0"}},
+			expected: ErrInvalidTargetIP,
+		},
+		{
+			cmd: command.Command{Target: testTarget, Order: command.Order{
+				Type:    "pullImage",
+				Payload: command.PullImage{},
+			}},
+			expected: ErrEmptyFieldImage,
+		},
+		{
+			cmd: command.Command{Target: testTarget, Order: command.Order{
+				Type:    "removeVolume",
+				Payload: command.SimpleName{},
+			}},
+			expected: ErrEmptyFieldName,
+		},
+		{
+			cmd: command.Command{Target: testTarget, Order: command.Order{
+				Type:    "detachNetwork",
+				Payload: command.ContainerNetwork{Container: "a"},
+			}},
+			expected: ErrEmptyFieldNetwork,
+		},
+		{
+			cmd: command.Command{Target: testTarget, Order: command.Order{
+				Type:    "startContainer",
+				Payload: command.StartContainer{Name: "a"},
+			}},
+			expected: entity.NewSuccessResult(),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := NewDockerUseCase(nil, logrus.New()).Validate(tt.cmd)
+			assert.Equal(t, tt.expected.Error, res.Error)
+			assert.Equal(t, tt.expected.Type, res.Type)
+		})
+	}
+}
+
+func TestDockerUseCase_Validate_Failures(t *testing.T) {
+	var tests = []command.Command{
+		{Target: testTarget, Order: command.Order{Type: "unknown"}},
+		{Target: testTarget, Order: command.Order{
+			Type:    "createContainer",
+			Payload: command.Container{Name: "a", Image: "b", Cpus: "many", Memory: "2GB"},
+		}},
+	}
+
+	for i, cmd := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := NewDockerUseCase(nil, logrus.New()).Validate(cmd)
+			assert.True(t, res.IsFatal())
+		})
+	}
+}
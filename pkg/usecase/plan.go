@@ -0,0 +1,68 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package usecase
+
+import (
+	"context"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/repository"
+	"github.com/whiteblock/genesis/pkg/service"
+
+	"github.com/sirupsen/logrus"
+	"github.com/whiteblock/definition/command"
+)
+
+//PlanUseCase previews the docker calls which instructions would make, without making them
+type PlanUseCase interface {
+	// Plan runs every command of the instructions in dry run mode, recording the docker calls
+	// each one would make. Commands which would fail are recorded along with their error, and
+	// the rest of the instructions are still planned.
+	Plan(inst command.Instructions) entity.Plan
+}
+
+type planUseCase struct {
+	conf config.Docker
+	log  logrus.Ext1FieldLogger
+}
+
+//NewPlanUseCase creates a PlanUseCase which plans with the given docker configuration
+func NewPlanUseCase(conf config.Docker, log logrus.Ext1FieldLogger) PlanUseCase {
+	return &planUseCase{conf: conf, log: log}
+}
+
+// Plan runs every command of the instructions in dry run mode, recording the docker calls
+// each one would make. Commands which would fail are recorded along with their error, and
+// the rest of the instructions are still planned.
+func (puc planUseCase) Plan(inst command.Instructions) entity.Plan {
+	rec := repository.NewRecorder()
+	docker := NewDockerUseCase(service.NewDryRunDockerService(rec, puc.conf, puc.log), puc.log)
+
+	out := entity.Plan{Steps: []entity.PlanStep{}}
+	for i, step := range inst.Commands {
+		planStep := entity.PlanStep{Step: i, Commands: []entity.PlanCommand{}}
+		for _, cmd := range step {
+			res := docker.Run(context.Background(), cmd)
+			planCmd := entity.PlanCommand{
+				CommandID: cmd.ID,
+				Type:      cmd.Order.Type,
+				Target:    cmd.Target.IP,
+				Calls:     rec.Calls(),
+			}
+			if planCmd.Calls == nil {
+				planCmd.Calls = []entity.PlanCall{}
+			}
+			if !res.IsSuccess() {
+				planCmd.Error = res.Error.Error()
+			}
+			planStep.Commands = append(planStep.Commands, planCmd)
+		}
+		out.Steps = append(out.Steps, planStep)
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package usecase
+
+import (
+	"testing"
+
+	"github.com/whiteblock/genesis/pkg/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/whiteblock/definition/command"
+)
+
+func TestPlanUseCase_Plan(t *testing.T) {
+	inst := command.Instructions{Commands: [][]command.Command{
+		{{ID: "net", Target: testTarget, Order: command.Order{
+			Type:    "createNetwork",
+			Payload: command.Network{Name: "n", Subnet: "10.1.0.0/16", Gateway: "10.1.0.1"},
+		}}},
+		{
+			{ID: "cntr", Target: testTarget, Order: command.Order{
+				Type: "createContainer",
+				Payload: command.Container{Name: "a", Image: "img", Cpus: "1",
+					Memory: "1GB", Network: "n"},
+			}},
+			{ID: "bad", Target: testTarget, Order: command.Order{
+				Type:    "pullImage",
+				Payload: command.PullImage{},
+			}},
+		},
+		{{ID: "netem", Target: testTarget, Order: command.Order{
+			Type:    "emulation",
+			Payload: command.Netconf{Container: "a", Network: "n", Delay: 100},
+		}}},
+	}}
+
+	plan := NewPlanUseCase(config.Docker{}, logrus.New()).Plan(inst)
+	require.Len(t, plan.Steps, 3)
+
+	cmds := plan.Steps[1].Commands
+	require.Len(t, cmds, 2)
+	assert.Equal(t, "cntr", cmds[0].CommandID)
+	assert.Empty(t, cmds[0].Error)
+	if assert.Len(t, cmds[0].Calls, 2) {
+		assert.Equal(t, "ImagePull", cmds[0].Calls[0].Method)
+		assert.Equal(t, "ContainerCreate", cmds[0].Calls[1].Method)
+		assert.Equal(t, testTarget.IP, cmds[0].Calls[1].Host)
+	}
+	assert.Equal(t, ErrEmptyFieldImage.Error.Error(), cmds[1].Error)
+	assert.Empty(t, cmds[1].Calls)
+
+	netem := plan.Steps[2].Commands[0]
+	assert.Empty(t, netem.Error)
+	assert.Contains(t, plan.String(), "ip -o -4 addr show to 10.1.0.0/16; } | sed")
+	assert.Contains(t, plan.String(), "delay 100us")
+}
@@ -0,0 +1,52 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package usecase
+
+import (
+	"github.com/whiteblock/genesis/pkg/entity"
+	"github.com/whiteblock/genesis/pkg/validator"
+
+	"github.com/sirupsen/logrus"
+	"github.com/whiteblock/definition/command"
+)
+
+//ValidationUseCase checks instructions for problems before they are executed
+type ValidationUseCase interface {
+	// Validate checks every command of the instructions, both on its own and against the other
+	// commands, returning all of the problems found
+	Validate(inst command.Instructions) entity.Validation
+}
+
+type validationUseCase struct {
+	docker DockerUseCase
+	log    logrus.Ext1FieldLogger
+}
+
+//NewValidationUseCase creates a ValidationUseCase which checks individual commands with docker
+func NewValidationUseCase(docker DockerUseCase, log logrus.Ext1FieldLogger) ValidationUseCase {
+	return &validationUseCase{docker: docker, log: log}
+}
+
+// Validate checks every command of the instructions, both on its own and against the other
+// commands, returning all of the problems found
+func (vuc validationUseCase) Validate(inst command.Instructions) entity.Validation {
+	problems := []entity.Problem{}
+	for i, step := range inst.Commands {
+		for _, cmd := range step {
+			res := vuc.docker.Validate(cmd)
+			if res.IsSuccess() {
+				continue
+			}
+			problems = append(problems, entity.NewProblem(i, cmd, res.Error))
+		}
+	}
+	problems = append(problems, validator.Instructions(inst)...)
+	vuc.log.WithFields(logrus.Fields{
+		"testnet":  inst.ID,
+		"problems": len(problems)}).Debug("validated the instructions")
+	return entity.NewValidation(problems)
+}
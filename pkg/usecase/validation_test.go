@@ -0,0 +1,52 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package usecase
+
+import (
+	"testing"
+
+	mockUsecase "github.com/whiteblock/genesis/mocks/pkg/usecase"
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/whiteblock/definition/command"
+)
+
+func TestValidationUseCase_Validate(t *testing.T) {
+	docker := new(mockUsecase.DockerUseCase)
+	docker.On("Validate", mock.MatchedBy(func(cmd command.Command) bool {
+		return cmd.ID == "bad"
+	})).Return(ErrEmptyFieldImage).Once()
+	docker.On("Validate", mock.Anything).Return(entity.NewSuccessResult()).Twice()
+
+	inst := command.Instructions{Commands: [][]command.Command{
+		{{ID: "good"}},
+		{
+			{ID: "bad"},
+			{ID: "net", Order: command.Order{Type: "attachNetwork",
+				Payload: command.ContainerNetwork{Container: "a", Network: "missing"}}},
+		},
+	}}
+
+	res := NewValidationUseCase(docker, logrus.New()).Validate(inst)
+	assert.False(t, res.Valid)
+	if assert.Len(t, res.Problems, 2) {
+		assert.Equal(t, entity.Problem{Step: 1, CommandID: "bad",
+			Message: ErrEmptyFieldImage.Error.Error()}, res.Problems[0])
+		assert.Equal(t, "net", res.Problems[1].CommandID)
+	}
+	docker.AssertExpectations(t)
+}
+
+func TestValidationUseCase_Validate_Valid(t *testing.T) {
+	res := NewValidationUseCase(NewDockerUseCase(nil, logrus.New()), logrus.New()).Validate(
+		command.Instructions{})
+	assert.True(t, res.Valid)
+	assert.Empty(t, res.Problems)
+}
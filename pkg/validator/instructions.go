@@ -0,0 +1,194 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/whiteblock/definition/command"
+)
+
+var (
+	// ErrUnknownNetwork means a network is used before it is created
+	ErrUnknownNetwork = errors.New("network is used before it is created")
+
+	// ErrDuplicateContainer means a container is created while one of the same name exists
+	ErrDuplicateContainer = errors.New("container name is already in use")
+
+	// ErrInvalidSubnet means the subnet of a network is malformed
+	ErrInvalidSubnet = errors.New("invalid subnet")
+
+	// ErrInvalidIP means an ip address is malformed
+	ErrInvalidIP = errors.New("invalid ip address")
+
+	// ErrIPOutsideSubnet means an ip address is not within the subnet of its network
+	ErrIPOutsideSubnet = errors.New("ip address is outside of the subnet of the network")
+)
+
+// defaultNetworks are created by docker itself, so they exist without being created
+var defaultNetworks = map[string]bool{"bridge": true, "host": true, "none": true}
+
+// instructionsState tracks the networks and containers which exist after each step
+type instructionsState struct {
+	// networks maps each network name to its subnets, which are empty if none were given
+	networks map[string][]*net.IPNet
+	// containers holds the name of each container, prefixed by its host
+	containers map[string]bool
+
+	problems []entity.Problem
+}
+
+func (is *instructionsState) problem(step int, cmd command.Command, err error) {
+	is.problems = append(is.problems, entity.NewProblem(step, cmd, err))
+}
+
+func (is *instructionsState) checkNetwork(step int, cmd command.Command, name string) bool {
+	if _, ok := is.networks[name]; ok || defaultNetworks[name] {
+		return true
+	}
+	is.problem(step, cmd, fmt.Errorf("%w: %q", ErrUnknownNetwork, name))
+	return false
+}
+
+func (is *instructionsState) checkIP(step int, cmd command.Command, network, ip string) {
+	if ip == "" {
+		return
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		is.problem(step, cmd, fmt.Errorf("%w: %q", ErrInvalidIP, ip))
+		return
+	}
+	//only the subnets of the same ip version as the address could contain it
+	subnets := []string{}
+	for _, subnet := range is.networks[network] {
+		if (subnet.IP.To4() == nil) != (addr.To4() == nil) {
+			continue
+		}
+		if subnet.Contains(addr) {
+			return
+		}
+		subnets = append(subnets, subnet.String())
+	}
+	if len(subnets) > 0 {
+		is.problem(step, cmd, fmt.Errorf("%w: %s is not in %s", ErrIPOutsideSubnet, ip,
+			strings.Join(subnets, ", ")))
+	}
+}
+
+// Instructions checks that the commands of the instructions are consistent with each other.
+// The commands in a step execute concurrently, so each command only sees the networks and
+// containers from the steps before it. Commands with malformed payloads are skipped, as they are
+// reported by the checks on individual commands.
+func Instructions(inst command.Instructions) []entity.Problem {
+	state := &instructionsState{
+		networks:   map[string][]*net.IPNet{},
+		containers: map[string]bool{},
+	}
+	for i, step := range inst.Commands {
+		createdNets := map[string][]*net.IPNet{}
+		removedNets := []string{}
+		createdCntrs := map[string]bool{}
+		removedCntrs := []string{}
+
+		for _, cmd := range step {
+			switch command.OrderType(strings.ToLower(string(cmd.Order.Type))) {
+			case command.Createnetwork:
+				var payload entity.Network
+				if cmd.ParseOrderPayloadInto(&payload) != nil {
+					continue
+				}
+				subnets := []*net.IPNet{}
+				for _, subnet := range payload.GetSubnets() {
+					if subnet.Subnet == "" {
+						continue
+					}
+					_, parsed, err := net.ParseCIDR(subnet.Subnet)
+					if err != nil {
+						state.problem(i, cmd, fmt.Errorf("%w: %q", ErrInvalidSubnet, subnet.Subnet))
+						continue
+					}
+					subnets = append(subnets, parsed)
+				}
+				createdNets[payload.Name] = subnets
+
+			case command.Removenetwork:
+				var payload command.SimpleName
+				if cmd.ParseOrderPayloadInto(&payload) != nil {
+					continue
+				}
+				removedNets = append(removedNets, payload.Name)
+
+			case command.Createcontainer:
+				var payload entity.Container
+				if cmd.ParseOrderPayloadInto(&payload) != nil {
+					continue
+				}
+				key := cmd.Target.IP + "/" + payload.Name
+				if state.containers[key] || createdCntrs[key] {
+					state.problem(i, cmd, fmt.Errorf("%w: %q", ErrDuplicateContainer, payload.Name))
+				}
+				createdCntrs[key] = true
+				for _, attachment := range payload.GetNetworks() {
+					if state.checkNetwork(i, cmd, attachment.Name) {
+						state.checkIP(i, cmd, attachment.Name, attachment.IPv4Address)
+						state.checkIP(i, cmd, attachment.Name, attachment.IPv6Address)
+					}
+				}
+
+			case command.Removecontainer:
+				var payload command.SimpleName
+				if cmd.ParseOrderPayloadInto(&payload) != nil {
+					continue
+				}
+				removedCntrs = append(removedCntrs, cmd.Target.IP+"/"+payload.Name)
+
+			case command.Attachnetwork:
+				var payload command.ContainerNetwork
+				if cmd.ParseOrderPayloadInto(&payload) != nil || payload.Network == "" {
+					continue
+				}
+				if state.checkNetwork(i, cmd, payload.Network) {
+					state.checkIP(i, cmd, payload.Network, payload.IP)
+				}
+
+			case command.Detachnetwork:
+				var payload command.ContainerNetwork
+				if cmd.ParseOrderPayloadInto(&payload) != nil || payload.Network == "" {
+					continue
+				}
+				state.checkNetwork(i, cmd, payload.Network)
+
+			case command.Emulation:
+				var payload command.Netconf
+				if cmd.ParseOrderPayloadInto(&payload) != nil || payload.Network == "" {
+					continue
+				}
+				state.checkNetwork(i, cmd, payload.Network)
+			}
+		}
+
+		for name, subnets := range createdNets {
+			state.networks[name] = subnets
+		}
+		for _, name := range removedNets {
+			delete(state.networks, name)
+		}
+		for key := range createdCntrs {
+			state.containers[key] = true
+		}
+		for _, key := range removedCntrs {
+			delete(state.containers, key)
+		}
+	}
+	return state.problems
+}
@@ -0,0 +1,127 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package validator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whiteblock/definition/command"
+)
+
+func testCmd(id string, orderType command.OrderType, payload interface{}) command.Command {
+	return command.Command{
+		ID:     id,
+		Target: command.Target{IP: "127.0.0.1"},
+		Order:  command.Order{Type: orderType, Payload: payload},
+	}
+}
+
+func TestInstructions(t *testing.T) {
+	network := testCmd("net", "createNetwork", command.Network{Name: "n", Subnet: "10.0.0.0/24"})
+	var tests = []struct {
+		steps    [][]command.Command
+		expected []error
+	}{
+		{
+			steps: [][]command.Command{
+				{network},
+				{testCmd("a", "createContainer", command.Container{Name: "a", Network: "n",
+					IP: "10.0.0.2"})},
+				{testCmd("b", "attachNetwork", command.ContainerNetwork{Container: "a", Network: "n"})},
+			},
+			expected: nil,
+		},
+		{
+			steps: [][]command.Command{
+				{network, testCmd("a", "createContainer", command.Container{Name: "a", Network: "n"})},
+			},
+			expected: []error{ErrUnknownNetwork},
+		},
+		{
+			steps: [][]command.Command{
+				{testCmd("a", "createContainer", command.Container{Name: "a", Network: "bridge"})},
+				{testCmd("b", "createContainer", command.Container{Name: "a"})},
+			},
+			expected: []error{ErrDuplicateContainer},
+		},
+		{
+			steps: [][]command.Command{
+				{testCmd("a", "createContainer", command.Container{Name: "a"})},
+				{testCmd("b", "removeContainer", command.SimpleName{Name: "a"})},
+				{testCmd("c", "createContainer", command.Container{Name: "a"})},
+			},
+			expected: nil,
+		},
+		{
+			steps: [][]command.Command{
+				{network},
+				{testCmd("a", "createContainer", command.Container{Name: "a", Network: "n",
+					IP: "10.0.1.2"})},
+				{testCmd("b", "attachNetwork", command.ContainerNetwork{Container: "a", Network: "n",
+					IP: "nope"})},
+			},
+			expected: []error{ErrIPOutsideSubnet, ErrInvalidIP},
+		},
+		{
+			steps: [][]command.Command{
+				{network},
+				{testCmd("a", "createContainer", entity.Container{
+					Container: command.Container{Name: "a", Network: "n", IP: "fd00::2"},
+					Networks: []entity.NetworkAttachment{
+						{Name: "bridge", IPv4Address: "172.17.0.9"},
+						{Name: "m", IPv4Address: "10.1.0.2"},
+					},
+				})},
+				{testCmd("b", "createContainer", entity.Container{
+					Container: command.Container{Name: "b"},
+					Networks:  []entity.NetworkAttachment{{Name: "n", IPv4Address: "10.1.0.2"}},
+				})},
+			},
+			expected: []error{ErrUnknownNetwork, ErrIPOutsideSubnet},
+		},
+		{
+			steps: [][]command.Command{
+				{testCmd("net", "createNetwork", entity.Network{
+					Network: command.Network{Name: "d", Subnet: "10.2.0.0/24"},
+					Subnets: []entity.Subnet{{Subnet: "fd00::/64"}, {Subnet: "fd01::"}},
+				})},
+				{testCmd("a", "createContainer", entity.Container{
+					Container: command.Container{Name: "a", Network: "d", IP: "10.2.0.2"},
+				})},
+				{testCmd("b", "attachNetwork", command.ContainerNetwork{Container: "a", Network: "d",
+					IP: "fd02::2"})},
+			},
+			expected: []error{ErrInvalidSubnet, ErrIPOutsideSubnet},
+		},
+		{
+			steps: [][]command.Command{
+				{testCmd("net", "createNetwork", command.Network{Name: "n", Subnet: "10.0.0.0"})},
+				{testCmd("a", "removeNetwork", command.SimpleName{Name: "n"})},
+				{testCmd("b", "emulation", command.Netconf{Container: "a", Network: "n"})},
+			},
+			expected: []error{ErrInvalidSubnet, ErrUnknownNetwork},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			problems := Instructions(command.Instructions{Commands: tt.steps})
+			if !assert.Len(t, problems, len(tt.expected)) {
+				return
+			}
+			for j, problem := range problems {
+				assert.True(t, strings.HasPrefix(problem.Message, tt.expected[j].Error()),
+					problem.Message)
+			}
+		})
+	}
+}
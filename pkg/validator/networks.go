@@ -0,0 +1,234 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+)
+
+var (
+	// ErrMissingNetworkName means a network the container is attached to has no name
+	ErrMissingNetworkName = errors.New("networks must have a name")
+
+	// ErrDuplicateNetwork means a container is attached to the same network more than once
+	ErrDuplicateNetwork = errors.New("container is attached to the network more than once")
+
+	// ErrWrongIPVersion means an ipv4 address is given as an ipv6 address, or the other way around
+	ErrWrongIPVersion = errors.New("ip address is of the wrong version")
+
+	// ErrInvalidMacAddress means a mac address is malformed
+	ErrInvalidMacAddress = errors.New("invalid mac address")
+
+	// ErrInvalidAlias means a network alias is empty
+	ErrInvalidAlias = errors.New("network aliases cannot be empty")
+
+	// ErrMissingSubnet means a gateway, ip range or auxiliary address is given without its subnet
+	ErrMissingSubnet = errors.New(
+		"subnet is required along with a gateway, ip range or auxiliary address")
+
+	// ErrDuplicateSubnet means a network is given the same subnet more than once
+	ErrDuplicateSubnet = errors.New("subnet is given more than once")
+
+	// ErrInvalidIPRange means the ip range of a subnet is malformed
+	ErrInvalidIPRange = errors.New("invalid ip range")
+
+	// ErrInvalidDriver means a network driver is not one genesis supports
+	ErrInvalidDriver = errors.New(`driver must be one of "bridge", "overlay", "macvlan" or "ipvlan"`)
+
+	// ErrGlobalDriver means a global network is given a driver other than overlay
+	ErrGlobalDriver = errors.New("global networks must use the overlay driver")
+
+	// ErrMissingParent means a macvlan or ipvlan network has no parent interface
+	ErrMissingParent = errors.New("macvlan and ipvlan networks require a parent interface")
+
+	// ErrInvalidParent means the parent interface of a network is not a valid interface name
+	ErrInvalidParent = errors.New("invalid parent interface")
+
+	// ErrUnsupportedOption means a network is given an option its driver does not support
+	ErrUnsupportedOption = errors.New("option is not supported by the driver of the network")
+
+	// ErrInvalidNetworkMode means the mode of a macvlan or ipvlan network is not one its driver
+	// supports
+	ErrInvalidNetworkMode = errors.New("invalid network mode")
+
+	// ErrInvalidMTU means the mtu of a network is out of range
+	ErrInvalidMTU = fmt.Errorf("mtu must be between %d and %d", MinMTU, MaxMTU)
+
+	// ErrInvalidDriverOption means a driver option has no name
+	ErrInvalidDriverOption = errors.New("driver options must have a name")
+)
+
+// The bounds of the mtu of a network
+const (
+	MinMTU = 68
+	MaxMTU = 65535
+)
+
+// networkModes are the modes each driver supports, for the drivers which have modes
+var networkModes = map[string]map[string]bool{
+	entity.MacvlanDriver: {"bridge": true, "private": true, "vepa": true, "passthru": true},
+	entity.IPvlanDriver:  {"l2": true, "l3": true, "l3s": true},
+}
+
+// interfacePattern matches linux interface names, which are at most 15 characters
+var interfacePattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,15}$`)
+
+func networks(cntr entity.Container) error {
+	seen := map[string]bool{}
+	for _, attachment := range cntr.GetNetworks() {
+		if attachment.Name == "" {
+			return ErrMissingNetworkName
+		}
+		if seen[attachment.Name] {
+			return fmt.Errorf("%w: %q", ErrDuplicateNetwork, attachment.Name)
+		}
+		seen[attachment.Name] = true
+
+		if err := ipVersion(attachment.IPv4Address, true); err != nil {
+			return err
+		}
+		if err := ipVersion(attachment.IPv6Address, false); err != nil {
+			return err
+		}
+		if attachment.MacAddress != "" {
+			if _, err := net.ParseMAC(attachment.MacAddress); err != nil {
+				return fmt.Errorf("%w: %q", ErrInvalidMacAddress, attachment.MacAddress)
+			}
+		}
+		for _, alias := range attachment.Aliases {
+			if alias == "" {
+				return ErrInvalidAlias
+			}
+		}
+	}
+	return nil
+}
+
+func ipVersion(addr string, v4 bool) error {
+	if addr == "" {
+		return nil
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return fmt.Errorf("%w: %q", ErrInvalidIP, addr)
+	}
+	if (ip.To4() != nil) != v4 {
+		return fmt.Errorf("%w: %q", ErrWrongIPVersion, addr)
+	}
+	return nil
+}
+
+// Network validates a create network command payload, including each of its subnets and the
+// options of its driver
+func Network(nw entity.Network) error {
+	err := driver(nw)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, subnet := range nw.GetSubnets() {
+		if subnet.Subnet == "" {
+			if subnet.Gateway != "" || subnet.IPRange != "" || len(subnet.AuxAddresses) > 0 {
+				return ErrMissingSubnet
+			}
+			continue
+		}
+		_, parsed, err := net.ParseCIDR(subnet.Subnet)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidSubnet, subnet.Subnet)
+		}
+		if seen[parsed.String()] {
+			return fmt.Errorf("%w: %q", ErrDuplicateSubnet, subnet.Subnet)
+		}
+		seen[parsed.String()] = true
+
+		if err := withinSubnet(parsed, subnet.Gateway); err != nil {
+			return err
+		}
+		if subnet.IPRange != "" {
+			ip, ipRange, err := net.ParseCIDR(subnet.IPRange)
+			if err != nil {
+				return fmt.Errorf("%w: %q", ErrInvalidIPRange, subnet.IPRange)
+			}
+			rangeSize, _ := ipRange.Mask.Size()
+			subnetSize, _ := parsed.Mask.Size()
+			if !parsed.Contains(ip) || rangeSize < subnetSize {
+				return fmt.Errorf("%w: %s is not in %s", ErrIPOutsideSubnet, subnet.IPRange, parsed)
+			}
+		}
+		for _, addr := range subnet.AuxAddresses {
+			if err := withinSubnet(parsed, addr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func withinSubnet(subnet *net.IPNet, addr string) error {
+	if addr == "" {
+		return nil
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return fmt.Errorf("%w: %q", ErrInvalidIP, addr)
+	}
+	if !subnet.Contains(ip) {
+		return fmt.Errorf("%w: %s is not in %s", ErrIPOutsideSubnet, addr, subnet)
+	}
+	return nil
+}
+
+func driver(nw entity.Network) error {
+	switch nw.GetDriver() {
+	case entity.BridgeDriver, entity.OverlayDriver, entity.MacvlanDriver, entity.IPvlanDriver:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidDriver, nw.Driver)
+	}
+	if nw.Global && nw.GetDriver() != entity.OverlayDriver {
+		return fmt.Errorf("%w: %q", ErrGlobalDriver, nw.Driver)
+	}
+	for key := range nw.Options {
+		if key == "" {
+			return ErrInvalidDriverOption
+		}
+	}
+	if nw.MTU != 0 && (nw.MTU < MinMTU || nw.MTU > MaxMTU) {
+		return ErrInvalidMTU
+	}
+
+	opts := nw.GetOptions()
+	modes, hasModes := networkModes[nw.GetDriver()]
+	if !hasModes {
+		//only macvlan and ipvlan networks are created on a parent interface
+		if nw.Parent != "" || nw.Mode != "" {
+			return fmt.Errorf("%w: %s does not take a parent interface or a mode", ErrUnsupportedOption,
+				nw.GetDriver())
+		}
+		return nil
+	}
+	if nw.MTU != 0 {
+		return fmt.Errorf("%w: %s networks take the mtu of their parent interface",
+			ErrUnsupportedOption, nw.GetDriver())
+	}
+	parent := opts[entity.ParentOption]
+	if parent == "" {
+		return ErrMissingParent
+	}
+	if !interfacePattern.MatchString(parent) {
+		return fmt.Errorf("%w: %q", ErrInvalidParent, parent)
+	}
+	if mode, ok := opts[nw.GetModeOption()]; ok && !modes[mode] {
+		return fmt.Errorf("%w: %q for %s", ErrInvalidNetworkMode, mode, nw.GetDriver())
+	}
+	return nil
+}
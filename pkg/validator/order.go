@@ -9,9 +9,8 @@ package validator
 import (
 	"errors"
 	"fmt"
-	"strconv"
 
-	"github.com/whiteblock/definition/command"
+	"github.com/whiteblock/genesis/pkg/entity"
 )
 
 // UnixMinEphemeralPort is the lowest ephermeral port number
@@ -31,8 +30,9 @@ var (
 	ErrContainerPortTooHigh = fmt.Errorf(`container port mapping cannot exceed %d`, UnixMinEphemeralPort)
 )
 
-// Container validates a container command payload
-func Container(cntr command.Container) error {
+// Container validates a container command payload, including the limits on its resources, how
+// it is run and the networks it is attached to
+func Container(cntr entity.Container) error {
 	if len(cntr.Name) == 0 {
 		return ErrMissingName
 	}
@@ -46,12 +46,22 @@ func Container(cntr command.Container) error {
 		}
 	}
 
-	_, err := strconv.ParseFloat(cntr.Cpus, 64)
+	res, err := cntr.GetResources()
 	if err != nil {
 		return err
 	}
 
-	_, err = cntr.GetMemory()
+	err = resources(cntr, res)
+	if err != nil {
+		return err
+	}
+
+	err = runtime(cntr)
+	if err != nil {
+		return err
+	}
+
+	err = networks(cntr)
 	if err != nil {
 		return err
 	}
@@ -7,8 +7,11 @@
 package validator
 
 import (
+	"strconv"
 	"testing"
 
+	"github.com/whiteblock/genesis/pkg/entity"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/whiteblock/definition/command"
 )
@@ -21,7 +24,7 @@ func TestOrderValidator_ValidateContainer_Success(t *testing.T) {
 		Memory: "2GB",
 		Image:  "t",
 	}
-	assert.NoError(t, Container(testContainer))
+	assert.NoError(t, Container(entity.Container{Container: testContainer}))
 }
 
 func TestOrderValidator_ValidateContainer_BadName(t *testing.T) {
@@ -32,7 +35,7 @@ func TestOrderValidator_ValidateContainer_BadName(t *testing.T) {
 		Memory: "2GB",
 		Image:  "t",
 	}
-	assert.Error(t, Container(testContainer))
+	assert.Error(t, Container(entity.Container{Container: testContainer}))
 }
 
 func TestOrderValidator_ValidateContainer_BadPorts_Host(t *testing.T) {
@@ -43,7 +46,7 @@ func TestOrderValidator_ValidateContainer_BadPorts_Host(t *testing.T) {
 		Memory: "2GB",
 		Image:  "t",
 	}
-	assert.Error(t, Container(testContainer))
+	assert.Error(t, Container(entity.Container{Container: testContainer}))
 }
 
 func TestOrderValidator_ValidateContainer_BadPorts_Container(t *testing.T) {
@@ -54,7 +57,7 @@ func TestOrderValidator_ValidateContainer_BadPorts_Container(t *testing.T) {
 		Memory: "2GB",
 		Image:  "t",
 	}
-	assert.Error(t, Container(testContainer))
+	assert.Error(t, Container(entity.Container{Container: testContainer}))
 }
 
 func TestOrderValidator_ValidateContainer_BadCPUs(t *testing.T) {
@@ -65,7 +68,7 @@ func TestOrderValidator_ValidateContainer_BadCPUs(t *testing.T) {
 		Memory: "2GB",
 		Image:  "t",
 	}
-	assert.Error(t, Container(testContainer))
+	assert.Error(t, Container(entity.Container{Container: testContainer}))
 }
 
 func TestOrderValidator_ValidateContainer_BadMem(t *testing.T) {
@@ -76,7 +79,7 @@ func TestOrderValidator_ValidateContainer_BadMem(t *testing.T) {
 		Memory: "fdwe2",
 		Image:  "t",
 	}
-	assert.Error(t, Container(testContainer))
+	assert.Error(t, Container(entity.Container{Container: testContainer}))
 }
 
 func TestOrderValidator_ValidateContainer_BadImage(t *testing.T) {
@@ -87,5 +90,324 @@ func TestOrderValidator_ValidateContainer_BadImage(t *testing.T) {
 		Memory: "2GB",
 		Image:  "",
 	}
-	assert.Error(t, Container(testContainer))
+	assert.Error(t, Container(entity.Container{Container: testContainer}))
+}
+
+func TestOrderValidator_ValidateContainer_Resources(t *testing.T) {
+	base := command.Container{Name: "t", Cpus: "2.0", Memory: "2GB", Image: "t"}
+	var tests = []struct {
+		cntr     entity.Container
+		expected error
+	}{
+		{
+			cntr: entity.Container{
+				Container: base,
+				Resources: entity.Resources{
+					CPUSetCPUs: "0-3,6", CPUSetMems: "0", CPUShares: 512, CPUPeriod: 100000,
+					CPUQuota: 50000, MemorySwap: "4gb", MemoryReservation: "1gb", PidsLimit: -1,
+					Ulimits:       []entity.Ulimit{{Name: "nofile", Soft: 1024, Hard: 4096}},
+					BlkioWeight:   500,
+					DeviceReadBps: []entity.DeviceRate{{Path: "/dev/sda", Rate: "10mb"}},
+				},
+				Sysctls: map[string]string{"net.core.somaxconn": "1024"},
+				ShmSize: "256mb",
+				Tmpfs:   map[string]string{"/run": "rw,size=64m"},
+				CapAdd:  []string{"NET_ADMIN"},
+				CapDrop: []string{"ALL"},
+			},
+		},
+		{
+			cntr: entity.Container{Container: command.Container{Name: "t", Cpus: "2.0",
+				Memory: "2GB", Image: "t", BoundCPUs: []int{-1}}},
+			expected: ErrInvalidCPUSet,
+		},
+		{
+			cntr:     entity.Container{Container: base, Resources: entity.Resources{CPUSetCPUs: "3-1"}},
+			expected: ErrInvalidCPUSet,
+		},
+		{
+			cntr:     entity.Container{Container: base, Resources: entity.Resources{CPUSetMems: "a"}},
+			expected: ErrInvalidCPUSet,
+		},
+		{
+			cntr:     entity.Container{Container: base, Resources: entity.Resources{CPUShares: 1}},
+			expected: ErrInvalidCPUShares,
+		},
+		{
+			cntr:     entity.Container{Container: base, Resources: entity.Resources{CPUPeriod: 10}},
+			expected: ErrInvalidCPUPeriod,
+		},
+		{
+			cntr:     entity.Container{Container: base, Resources: entity.Resources{CPUQuota: 10}},
+			expected: ErrInvalidCPUQuota,
+		},
+		{
+			cntr:     entity.Container{Container: base, Resources: entity.Resources{MemorySwap: "1gb"}},
+			expected: ErrMemorySwapTooLow,
+		},
+		{
+			cntr: entity.Container{Container: base,
+				Resources: entity.Resources{MemoryReservation: "3gb"}},
+			expected: ErrInvalidMemoryReservation,
+		},
+		{
+			cntr:     entity.Container{Container: base, Resources: entity.Resources{PidsLimit: -2}},
+			expected: ErrInvalidPidsLimit,
+		},
+		{
+			cntr: entity.Container{Container: base, Resources: entity.Resources{
+				Ulimits: []entity.Ulimit{{Name: "nofile", Soft: 10, Hard: 5}}}},
+			expected: ErrInvalidUlimit,
+		},
+		{
+			cntr:     entity.Container{Container: base, Resources: entity.Resources{BlkioWeight: 5}},
+			expected: ErrInvalidBlkioWeight,
+		},
+		{
+			cntr: entity.Container{Container: base, Resources: entity.Resources{
+				DeviceWriteBps: []entity.DeviceRate{{Path: "sda", Rate: "1mb"}}}},
+			expected: ErrRelativePath,
+		},
+		{
+			cntr:     entity.Container{Container: base, Tmpfs: map[string]string{"run": ""}},
+			expected: ErrRelativePath,
+		},
+		{
+			cntr:     entity.Container{Container: base, ShmSize: "-1"},
+			expected: ErrInvalidShmSize,
+		},
+		{
+			cntr:     entity.Container{Container: base, Sysctls: map[string]string{"net core": "1"}},
+			expected: ErrInvalidSysctl,
+		},
+		{
+			cntr:     entity.Container{Container: base, CapAdd: []string{"NET ADMIN"}},
+			expected: ErrInvalidCapability,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := Container(tt.cntr)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOrderValidator_ValidateContainer_BadSizes(t *testing.T) {
+	base := command.Container{Name: "t", Cpus: "2.0", Memory: "2GB", Image: "t"}
+	for i, cntr := range []entity.Container{
+		{Container: base, Resources: entity.Resources{MemorySwap: "lots"}},
+		{Container: base, Resources: entity.Resources{MemoryReservation: "lots"}},
+		{Container: base, Resources: entity.Resources{
+			DeviceReadBps: []entity.DeviceRate{{Path: "/dev/sda", Rate: "fast"}}}},
+		{Container: base, ShmSize: "lots"},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			assert.Error(t, Container(cntr))
+		})
+	}
+}
+
+func TestOrderValidator_ValidateContainer_Runtime(t *testing.T) {
+	base := command.Container{Name: "t", Cpus: "2.0", Memory: "2GB", Image: "t"}
+	yes := true
+	no := false
+	timeout := 10
+	badTimeout := -2
+	var tests = []struct {
+		cntr     entity.Container
+		expected error
+	}{
+		{
+			cntr: entity.Container{
+				Container:     base,
+				Command:       []string{"geth", "--dev"},
+				User:          "1000:1000",
+				WorkingDir:    "/data",
+				StopSignal:    "SIGINT",
+				StopTimeout:   &timeout,
+				DNS:           []string{"8.8.8.8", "2001:4860:4860::8888"},
+				ExtraHosts:    []string{"bootnode:10.0.0.2", "v6:fd00::2"},
+				RestartPolicy: entity.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3},
+				AutoRemove:    &no,
+			},
+		},
+		{
+			cntr:     entity.Container{Container: base, RestartPolicy: entity.RestartPolicy{Name: "sometimes"}},
+			expected: ErrInvalidRestartPolicy,
+		},
+		{
+			cntr: entity.Container{Container: base,
+				RestartPolicy: entity.RestartPolicy{Name: "always", MaximumRetryCount: 3}},
+			expected: ErrInvalidRetryCount,
+		},
+		{
+			cntr: entity.Container{Container: base, AutoRemove: &yes,
+				RestartPolicy: entity.RestartPolicy{Name: "unless-stopped"}},
+			expected: ErrAutoRemoveWithRestart,
+		},
+		{
+			cntr:     entity.Container{Container: base, StopSignal: "SIG INT"},
+			expected: ErrInvalidStopSignal,
+		},
+		{
+			cntr:     entity.Container{Container: base, StopTimeout: &badTimeout},
+			expected: ErrInvalidStopTimeout,
+		},
+		{
+			cntr:     entity.Container{Container: base, WorkingDir: "data"},
+			expected: ErrRelativePath,
+		},
+		{
+			cntr:     entity.Container{Container: base, DNS: []string{"dns.google"}},
+			expected: ErrInvalidIP,
+		},
+		{
+			cntr:     entity.Container{Container: base, ExtraHosts: []string{"bootnode"}},
+			expected: ErrInvalidExtraHost,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := Container(tt.cntr)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOrderValidator_ValidateContainer_Networks(t *testing.T) {
+	base := command.Container{Name: "t", Cpus: "2.0", Memory: "2GB", Image: "t", Network: "public"}
+	var tests = []struct {
+		networks []entity.NetworkAttachment
+		expected error
+	}{
+		{
+			networks: []entity.NetworkAttachment{{Name: "private", IPv4Address: "10.1.0.2",
+				IPv6Address: "fd00::2", Aliases: []string{"node"}, MacAddress: "02:42:ac:11:00:02"}},
+			expected: nil,
+		},
+		{networks: []entity.NetworkAttachment{{IPv4Address: "10.1.0.2"}}, expected: ErrMissingNetworkName},
+		{networks: []entity.NetworkAttachment{{Name: "public"}}, expected: ErrDuplicateNetwork},
+		{networks: []entity.NetworkAttachment{{Name: "n", IPv4Address: "fd00::2"}}, expected: ErrWrongIPVersion},
+		{networks: []entity.NetworkAttachment{{Name: "n", IPv6Address: "10.1.0.2"}}, expected: ErrWrongIPVersion},
+		{networks: []entity.NetworkAttachment{{Name: "n", IPv6Address: "fd00:::2"}}, expected: ErrInvalidIP},
+		{networks: []entity.NetworkAttachment{{Name: "n", MacAddress: "02:42"}}, expected: ErrInvalidMacAddress},
+		{networks: []entity.NetworkAttachment{{Name: "n", Aliases: []string{""}}}, expected: ErrInvalidAlias},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := Container(entity.Container{Container: base, Networks: tt.networks})
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOrderValidator_ValidateNetwork(t *testing.T) {
+	base := command.Network{Name: "n", Subnet: "10.0.0.0/24", Gateway: "10.0.0.1"}
+	var tests = []struct {
+		nw       entity.Network
+		expected error
+	}{
+		{nw: entity.Network{Network: command.Network{Name: "n"}}, expected: nil},
+		{
+			nw: entity.Network{Network: base, IPRange: "10.0.0.128/25",
+				AuxAddresses: map[string]string{"router": "10.0.0.2"},
+				Subnets:      []entity.Subnet{{Subnet: "fd00::/64", Gateway: "fd00::1", IPRange: "fd00::/80"}}},
+			expected: nil,
+		},
+		{nw: entity.Network{Network: command.Network{Name: "n", Gateway: "10.0.0.1"}}, expected: ErrMissingSubnet},
+		{nw: entity.Network{Network: command.Network{Name: "n", Subnet: "10.0.0.0"}}, expected: ErrInvalidSubnet},
+		{
+			nw:       entity.Network{Network: base, Subnets: []entity.Subnet{{Subnet: "10.0.0.0/24"}}},
+			expected: ErrDuplicateSubnet,
+		},
+		{
+			nw:       entity.Network{Network: command.Network{Name: "n", Subnet: "10.0.0.0/24", Gateway: "10.0.1.1"}},
+			expected: ErrIPOutsideSubnet,
+		},
+		{nw: entity.Network{Network: base, IPRange: "10.0.0.0/16"}, expected: ErrIPOutsideSubnet},
+		{nw: entity.Network{Network: base, IPRange: "10.0.0.128"}, expected: ErrInvalidIPRange},
+		{
+			nw:       entity.Network{Network: base, AuxAddresses: map[string]string{"router": "fd00::2"}},
+			expected: ErrIPOutsideSubnet,
+		},
+		{
+			nw:       entity.Network{Network: base, Subnets: []entity.Subnet{{Subnet: "fd00::/64", Gateway: "fd00"}}},
+			expected: ErrInvalidIP,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := Network(tt.nw)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOrderValidator_ValidateNetwork_Drivers(t *testing.T) {
+	base := command.Network{Name: "n", Subnet: "10.0.0.0/24"}
+	var tests = []struct {
+		nw       entity.Network
+		expected error
+	}{
+		{nw: entity.Network{Network: base, Internal: true, MTU: 1400}, expected: nil},
+		{nw: entity.Network{Network: base, Driver: "overlay", MTU: 9000}, expected: nil},
+		{nw: entity.Network{Network: base, Driver: "macvlan", Parent: "eth0.10"}, expected: nil},
+		{nw: entity.Network{Network: base, Driver: "macvlan", Parent: "eth0", Mode: "vepa"}, expected: nil},
+		{
+			nw: entity.Network{Network: base, Driver: "ipvlan",
+				Options: map[string]string{"parent": "ens3", "ipvlan_mode": "l3s"}},
+			expected: nil,
+		},
+		{nw: entity.Network{Network: base, Driver: "host"}, expected: ErrInvalidDriver},
+		{
+			nw:       entity.Network{Network: command.Network{Name: "n", Global: true}, Driver: "bridge"},
+			expected: ErrGlobalDriver,
+		},
+		{nw: entity.Network{Network: base, Driver: "macvlan"}, expected: ErrMissingParent},
+		{nw: entity.Network{Network: base, Driver: "ipvlan", Parent: "eth 0"}, expected: ErrInvalidParent},
+		{
+			nw:       entity.Network{Network: base, Driver: "ipvlan", Parent: "eth0", Mode: "bridge"},
+			expected: ErrInvalidNetworkMode,
+		},
+		{
+			nw:       entity.Network{Network: base, Driver: "macvlan", Parent: "eth0", MTU: 1400},
+			expected: ErrUnsupportedOption,
+		},
+		{nw: entity.Network{Network: base, Parent: "eth0"}, expected: ErrUnsupportedOption},
+		{nw: entity.Network{Network: base, Mode: "l2"}, expected: ErrUnsupportedOption},
+		{nw: entity.Network{Network: base, MTU: 20}, expected: ErrInvalidMTU},
+		{nw: entity.Network{Network: base, Options: map[string]string{"": "x"}}, expected: ErrInvalidDriverOption},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := Network(tt.nw)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
 }
@@ -0,0 +1,166 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	// MinCPUPeriod is the shortest cfs period, in microseconds
+	MinCPUPeriod = 1000
+	// MaxCPUPeriod is the longest cfs period, in microseconds
+	MaxCPUPeriod = 1000000
+	// MinCPUQuota is the smallest cfs quota, in microseconds
+	MinCPUQuota = 1000
+	// MinBlkioWeight is the lowest block io weight
+	MinBlkioWeight = 10
+	// MaxBlkioWeight is the highest block io weight
+	MaxBlkioWeight = 1000
+)
+
+var (
+	// ErrInvalidCPUSet means a set of cpus or memory nodes is malformed
+	ErrInvalidCPUSet = errors.New("invalid cpuset")
+
+	// ErrInvalidCPUShares means the cpu shares are below the minimum of 2
+	ErrInvalidCPUShares = errors.New("cpu shares must be at least 2")
+
+	// ErrInvalidCPUPeriod means the cfs period is out of range
+	ErrInvalidCPUPeriod = fmt.Errorf("cpu period must be between %d and %d", MinCPUPeriod, MaxCPUPeriod)
+
+	// ErrInvalidCPUQuota means the cfs quota is too small
+	ErrInvalidCPUQuota = fmt.Errorf("cpu quota must be at least %d, or -1 for unlimited", MinCPUQuota)
+
+	// ErrMemorySwapTooLow means the memory swap is less than the memory
+	ErrMemorySwapTooLow = errors.New("memory swap must be at least the memory")
+
+	// ErrInvalidMemoryReservation means the memory reservation is negative or above the memory
+	ErrInvalidMemoryReservation = errors.New("memory reservation cannot exceed the memory")
+
+	// ErrInvalidPidsLimit means the pids limit is negative, other than -1
+	ErrInvalidPidsLimit = errors.New("pids limit must be positive, or -1 for unlimited")
+
+	// ErrInvalidUlimit means a ulimit has no name or a soft limit above its hard limit
+	ErrInvalidUlimit = errors.New("invalid ulimit")
+
+	// ErrInvalidBlkioWeight means the block io weight is out of range
+	ErrInvalidBlkioWeight = fmt.Errorf("block io weight must be between %d and %d",
+		MinBlkioWeight, MaxBlkioWeight)
+
+	// ErrInvalidShmSize means the size of /dev/shm is negative
+	ErrInvalidShmSize = errors.New("shm size cannot be negative")
+
+	// ErrRelativePath means a device or tmpfs path is not absolute
+	ErrRelativePath = errors.New("path must be absolute")
+
+	// ErrInvalidSysctl means the name of a sysctl is malformed
+	ErrInvalidSysctl = errors.New("invalid sysctl")
+
+	// ErrInvalidCapability means the name of a capability is malformed
+	ErrInvalidCapability = errors.New("invalid capability")
+)
+
+var (
+	capabilityPattern = regexp.MustCompile(`^[A-Za-z_]+$`)
+	sysctlPattern     = regexp.MustCompile(`^[a-zA-Z0-9_./-]+$`)
+)
+
+// CPUSet validates a set of cpus or memory nodes in the format of cpuset, such as "0-3,6"
+func CPUSet(set string) error {
+	if set == "" {
+		return nil
+	}
+	for _, part := range strings.Split(set, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		low, err := strconv.ParseUint(bounds[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidCPUSet, set)
+		}
+		if len(bounds) == 1 {
+			continue
+		}
+		high, err := strconv.ParseUint(bounds[1], 10, 32)
+		if err != nil || high < low {
+			return fmt.Errorf("%w: %q", ErrInvalidCPUSet, set)
+		}
+	}
+	return nil
+}
+
+func resources(cntr entity.Container, res container.Resources) error {
+	if err := CPUSet(res.CpusetCpus); err != nil {
+		return err
+	}
+	if err := CPUSet(res.CpusetMems); err != nil {
+		return err
+	}
+	if res.CPUShares != 0 && res.CPUShares < 2 {
+		return ErrInvalidCPUShares
+	}
+	if res.CPUPeriod != 0 && (res.CPUPeriod < MinCPUPeriod || res.CPUPeriod > MaxCPUPeriod) {
+		return ErrInvalidCPUPeriod
+	}
+	if res.CPUQuota != 0 && res.CPUQuota != -1 && res.CPUQuota < MinCPUQuota {
+		return ErrInvalidCPUQuota
+	}
+	if res.MemorySwap > 0 && res.MemorySwap < res.Memory {
+		return ErrMemorySwapTooLow
+	}
+	if res.MemoryReservation < 0 || (res.Memory > 0 && res.MemoryReservation > res.Memory) {
+		return ErrInvalidMemoryReservation
+	}
+	if res.PidsLimit != nil && *res.PidsLimit < -1 {
+		return ErrInvalidPidsLimit
+	}
+	for _, ulimit := range res.Ulimits {
+		if ulimit.Name == "" || (ulimit.Hard != -1 && ulimit.Soft > ulimit.Hard) {
+			return fmt.Errorf("%w: %q", ErrInvalidUlimit, ulimit.String())
+		}
+	}
+	if res.BlkioWeight != 0 && (res.BlkioWeight < MinBlkioWeight || res.BlkioWeight > MaxBlkioWeight) {
+		return ErrInvalidBlkioWeight
+	}
+	for _, dev := range append(res.BlkioDeviceReadBps, res.BlkioDeviceWriteBps...) {
+		if !path.IsAbs(dev.Path) {
+			return fmt.Errorf("%w: %q", ErrRelativePath, dev.Path)
+		}
+	}
+
+	shmSize, err := cntr.GetShmSize()
+	if err != nil {
+		return err
+	}
+	if shmSize < 0 {
+		return ErrInvalidShmSize
+	}
+	for dir := range cntr.Tmpfs {
+		if !path.IsAbs(dir) {
+			return fmt.Errorf("%w: %q", ErrRelativePath, dir)
+		}
+	}
+	for name := range cntr.Sysctls {
+		if !sysctlPattern.MatchString(name) {
+			return fmt.Errorf("%w: %q", ErrInvalidSysctl, name)
+		}
+	}
+	for _, capability := range append(cntr.GetCapAdd(), cntr.GetCapDrop()...) {
+		if !capabilityPattern.MatchString(capability) {
+			return fmt.Errorf("%w: %q", ErrInvalidCapability, capability)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/whiteblock/genesis/pkg/entity"
+)
+
+var (
+	// ErrInvalidRestartPolicy means the restart policy is not one docker knows of
+	ErrInvalidRestartPolicy = errors.New(
+		`restart policy must be one of "no", "always", "unless-stopped" or "on-failure"`)
+
+	// ErrInvalidRetryCount means the maximum retry count is negative, or set for a restart policy
+	// other than "on-failure"
+	ErrInvalidRetryCount = errors.New(
+		`maximum retry count must be positive, and can only be given with "on-failure"`)
+
+	// ErrAutoRemoveWithRestart means a container is both auto removed and restarted
+	ErrAutoRemoveWithRestart = errors.New("auto remove cannot be combined with a restart policy")
+
+	// ErrInvalidStopSignal means the stop signal is malformed
+	ErrInvalidStopSignal = errors.New("invalid stop signal")
+
+	// ErrInvalidStopTimeout means the stop timeout is negative, other than -1
+	ErrInvalidStopTimeout = errors.New("stop timeout must be positive, or -1 to wait forever")
+
+	// ErrInvalidExtraHost means an extra host is not in the form "host:ip"
+	ErrInvalidExtraHost = errors.New(`extra hosts must be in the form "host:ip"`)
+)
+
+var stopSignalPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+-]*|[0-9]+)$`)
+
+func runtime(cntr entity.Container) error {
+	switch cntr.RestartPolicy.Name {
+	case "", "no", "always", "unless-stopped", "on-failure":
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidRestartPolicy, cntr.RestartPolicy.Name)
+	}
+	if cntr.RestartPolicy.MaximumRetryCount < 0 || (cntr.RestartPolicy.MaximumRetryCount > 0 &&
+		cntr.RestartPolicy.Name != "on-failure") {
+		return ErrInvalidRetryCount
+	}
+	if cntr.AutoRemove != nil && *cntr.AutoRemove && !cntr.RestartPolicy.IsNone() {
+		return ErrAutoRemoveWithRestart
+	}
+
+	if cntr.StopSignal != "" && !stopSignalPattern.MatchString(cntr.StopSignal) {
+		return fmt.Errorf("%w: %q", ErrInvalidStopSignal, cntr.StopSignal)
+	}
+	if cntr.StopTimeout != nil && *cntr.StopTimeout < -1 {
+		return ErrInvalidStopTimeout
+	}
+	if cntr.WorkingDir != "" && !path.IsAbs(cntr.WorkingDir) {
+		return fmt.Errorf("%w: %q", ErrRelativePath, cntr.WorkingDir)
+	}
+
+	for _, server := range cntr.DNS {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("%w: %q", ErrInvalidIP, server)
+		}
+	}
+	for _, host := range cntr.ExtraHosts {
+		parts := strings.SplitN(host, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || net.ParseIP(parts[1]) == nil {
+			return fmt.Errorf("%w: %q", ErrInvalidExtraHost, host)
+		}
+	}
+	return nil
+}
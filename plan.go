@@ -0,0 +1,60 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/usecase"
+
+	"github.com/whiteblock/definition/command"
+)
+
+const planUsage = `usage: genesis plan [--json] <file>
+
+prints the docker calls the instructions in file would make, without making them.
+use - as the file to read the instructions from stdin`
+
+func planCLI(args []string) error {
+	asJSON := len(args) > 0 && args[0] == "--json"
+	if asJSON {
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return fmt.Errorf(planUsage)
+	}
+
+	var in io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	var inst command.Instructions
+	err := json.NewDecoder(in).Decode(&inst)
+	if err != nil {
+		return err
+	}
+
+	conf, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
+	plan := usecase.NewPlanUseCase(conf.Docker, conf.GetLogger()).Plan(inst)
+	if asJSON {
+		return printJSON(plan)
+	}
+	fmt.Print(plan.String())
+	return nil
+}
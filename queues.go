@@ -0,0 +1,183 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"github.com/whiteblock/genesis/pkg/bus"
+	"github.com/whiteblock/genesis/pkg/config"
+	handAux "github.com/whiteblock/genesis/pkg/handler/auxillary"
+
+	"github.com/nats-io/nats.go"
+	queue "github.com/whiteblock/amqp"
+)
+
+type messageQueues struct {
+	cmds        bus.Queue
+	errors      bus.Queue
+	completion  bus.Queue
+	status      bus.Queue
+	deadLetters bus.Queue
+	retry       handAux.Retrier
+}
+
+func assertUniqueQueues(conf config.Config, names ...string) {
+	queues := map[string]bool{}
+	for i := range names {
+		queues[names[i]] = false
+		if len(queues)-1 != i {
+			for j := range names {
+				conf.GetLogger().Errorf("%d = %s", j, names[j])
+			}
+			panic("queue names are not unique")
+		}
+	}
+}
+
+// getMemoryBroker creates the broker of the memory message bus, which is shared with the REST api
+// so that instructions can be published and results received. It is nil for the other buses.
+func getMemoryBroker(conf config.Config) bus.MemoryBroker {
+	if conf.Bus.Backend != config.MemoryBus {
+		return nil
+	}
+	return bus.NewMemoryBroker(conf.Bus.MemoryQueueLimit, conf.GetLogger())
+}
+
+func getMessageQueues(conf config.Config, broker bus.MemoryBroker) (messageQueues, error) {
+	switch conf.Bus.Backend {
+	case config.NATSBus:
+		return getNATSQueues(conf)
+	case config.MemoryBus:
+		return getMemoryQueues(conf, broker)
+	}
+	return getAMQPQueues(conf)
+}
+
+func getMemoryQueues(conf config.Config, broker bus.MemoryBroker) (out messageQueues, err error) {
+	assertUniqueQueues(conf, conf.CommandQueueName, conf.ErrorQueueName, conf.CompletionQueueName,
+		conf.StatusQueueName, conf.DeadLetterQueueName)
+
+	out.cmds = broker.Queue(conf.CommandQueueName)
+	out.errors = broker.Queue(conf.ErrorQueueName)
+	out.completion = broker.Queue(conf.CompletionQueueName)
+	out.status = broker.Queue(conf.StatusQueueName)
+	out.deadLetters = broker.Queue(conf.DeadLetterQueueName)
+	out.retry, err = handAux.NewTimerRetrier(conf.Retry, out.cmds, conf.GetLogger())
+	return
+}
+
+func getNATSQueues(conf config.Config) (out messageQueues, err error) {
+	assertUniqueQueues(conf, conf.CommandQueueName, conf.ErrorQueueName, conf.CompletionQueueName,
+		conf.StatusQueueName, conf.DeadLetterQueueName)
+
+	conn, err := nats.Connect(conf.Bus.NATSURL)
+	if err != nil {
+		return
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		return
+	}
+	newQueue := func(name string) bus.Queue {
+		return bus.NewNATSQueue(js, name, conf.Bus.NATSAckWait, conf.GetLogger())
+	}
+
+	out.cmds = newQueue(conf.CommandQueueName)
+	out.errors = newQueue(conf.ErrorQueueName)
+	out.completion = newQueue(conf.CompletionQueueName)
+	out.status = newQueue(conf.StatusQueueName)
+	out.deadLetters = newQueue(conf.DeadLetterQueueName)
+	out.retry, err = handAux.NewDelayRetrier(conf.Retry, out.cmds, conf.GetLogger())
+	return
+}
+
+func getAMQPQueues(conf config.Config) (out messageQueues, err error) {
+	complConf, err := conf.CompletionAMQP()
+	if err != nil {
+		return
+	}
+
+	cmdConf, err := conf.CommandAMQP()
+	if err != nil {
+		return
+	}
+
+	errConf, err := conf.ErrorsAMQP()
+	if err != nil {
+		return
+	}
+
+	statusConf, err := conf.StatusAMQP()
+	if err != nil {
+		return
+	}
+
+	deadLetterConf, err := conf.DeadLetterAMQP()
+	if err != nil {
+		return
+	}
+
+	retryConfs := []queue.AMQPConfig{}
+	for _, delay := range conf.Retry.Delays() {
+		retryConf, err := conf.RetryAMQP(delay)
+		if err != nil {
+			return out, err
+		}
+		retryConfs = append(retryConfs, retryConf)
+	}
+
+	names := []string{complConf.QueueName, cmdConf.QueueName, errConf.QueueName,
+		statusConf.QueueName, deadLetterConf.QueueName}
+	for i := range retryConfs {
+		names = append(names, retryConfs[i].QueueName)
+	}
+	assertUniqueQueues(conf, names...)
+
+	cmdConn, err := queue.OpenAMQPConnection(cmdConf.Endpoint)
+	if err != nil {
+		return
+	}
+
+	complConn, err := queue.OpenAMQPConnection(complConf.Endpoint)
+	if err != nil {
+		return
+	}
+
+	errConn, err := queue.OpenAMQPConnection(errConf.Endpoint)
+	if err != nil {
+		return
+	}
+
+	statusConn, err := queue.OpenAMQPConnection(statusConf.Endpoint)
+	if err != nil {
+		return
+	}
+
+	deadLetterConn, err := queue.OpenAMQPConnection(deadLetterConf.Endpoint)
+	if err != nil {
+		return
+	}
+
+	retryConn, err := queue.OpenAMQPConnection(cmdConf.Endpoint)
+	if err != nil {
+		return
+	}
+
+	retryQueues := make([]queue.AMQPService, len(retryConfs))
+	for i := range retryConfs {
+		retryQueues[i] = queue.NewAMQPService(retryConfs[i], queue.NewAMQPRepository(retryConn),
+			conf.GetLogger())
+	}
+
+	out.cmds = queue.NewAMQPService(cmdConf, queue.NewAMQPRepository(cmdConn), conf.GetLogger())
+	out.errors = queue.NewAMQPService(errConf, queue.NewAMQPRepository(errConn), conf.GetLogger())
+	out.completion = queue.NewAMQPService(complConf, queue.NewAMQPRepository(complConn), conf.GetLogger())
+	out.status = queue.NewAMQPService(statusConf, queue.NewAMQPRepository(statusConn), conf.GetLogger())
+	out.deadLetters = queue.NewAMQPService(deadLetterConf, queue.NewAMQPRepository(deadLetterConn),
+		conf.GetLogger())
+	out.retry, err = handAux.NewRetrier(conf.Retry, retryQueues, conf.GetLogger())
+	return
+}
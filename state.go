@@ -0,0 +1,65 @@
+/**
+ * Copyright 2019 Whiteblock Inc. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/whiteblock/genesis/pkg/config"
+	"github.com/whiteblock/genesis/pkg/repository"
+	"github.com/whiteblock/genesis/pkg/service"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// openStateDB opens the state database, or returns nil if no state is to be kept
+func openStateDB(conf config.State) (*bolt.DB, error) {
+	if conf.Path == "" {
+		return nil, nil
+	}
+	err := os.MkdirAll(filepath.Dir(conf.Path), 0700)
+	if err != nil {
+		return nil, err
+	}
+	return bolt.Open(conf.Path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+}
+
+func getLedgerService(conf config.Config, db *bolt.DB) (service.LedgerService, error) {
+	if db == nil {
+		conf.GetLogger().Warn("no state path is set, redelivered instructions will be executed again")
+		return service.NewLedgerService(nil, conf.GetLogger()), nil
+	}
+	repo, err := repository.NewLedgerRepository(db, conf.GetLogger())
+	if err != nil {
+		return nil, err
+	}
+	removed, err := repo.Prune(time.Now().Add(-conf.State.LedgerRetention))
+	if err != nil {
+		return nil, err
+	}
+	conf.GetLogger().WithField("removed", removed).Debug("pruned the ledger")
+	return service.NewLedgerService(repo, conf.GetLogger()), nil
+}
+
+func getJobService(conf config.Config, db *bolt.DB) (service.JobService, error) {
+	if db == nil {
+		conf.GetLogger().Warn("no state path is set, REST jobs will be lost on restart")
+		return service.NewJobService(nil, conf.GetLogger()), nil
+	}
+	repo, err := repository.NewJobRepository(db, conf.GetLogger())
+	if err != nil {
+		return nil, err
+	}
+	removed, err := repo.Prune(time.Now().Add(-conf.State.JobRetention))
+	if err != nil {
+		return nil, err
+	}
+	conf.GetLogger().WithField("removed", removed).Debug("pruned the finished jobs")
+	return service.NewJobService(repo, conf.GetLogger()), nil
+}